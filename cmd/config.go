@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// loadConfigFile reads the options from a YAML or JSON file and sets the flags which are not set in the command line
+// The keys of the file are the names of the flags, like "output_format" or "output-format", so the precedence is:
+// the flags of the command line, then the options of the file, then the defaults of the flags.
+// The flags are looked up in the flag sets in order.
+func loadConfigFile(path string, flagSets ...*pflag.FlagSet) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read config file %s: %s", path, err.Error())
+	}
+
+	var options map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		options, err = parseYAMLConfig(string(content))
+	default:
+		options, err = parseJSONConfig(content)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %s", path, err.Error())
+	}
+	return applyConfig(options, flagSets...)
+}
+
+// applyConfig sets the flags to the values of the options, unless they are set in the command line
+// The values are strings or lists of strings, the lists are set item by item, or joined by commas if the flag is
+// a single string like --emails.
+func applyConfig(options map[string]interface{}, flagSets ...*pflag.FlagSet) error {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var flag *pflag.Flag
+		var flagSet *pflag.FlagSet
+		for _, fs := range flagSets {
+			if flag = fs.Lookup(name); flag != nil {
+				flagSet = fs
+				break
+			}
+		}
+		if flag == nil || name == "config" {
+			return fmt.Errorf("unknown option %q", name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		values, isList := options[name].([]string)
+		if !isList {
+			values = []string{options[name].(string)}
+		} else if !strings.HasSuffix(flag.Value.Type(), "Slice") && !strings.HasSuffix(flag.Value.Type(), "Array") {
+			values = []string{strings.Join(values, ",")}
+		}
+		if len(values) == 0 {
+			// An empty list clears the default of the flag
+			values = []string{""}
+		}
+		for _, value := range values {
+			if err := flagSet.Set(flag.Name, value); err != nil {
+				return fmt.Errorf("invalid value %q of option %q: %s", value, name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// parseJSONConfig returns with the options of a JSON object, whose values are scalars or arrays of scalars
+func parseJSONConfig(content []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	options := map[string]interface{}{}
+	for name, value := range object {
+		if array, ok := value.([]interface{}); ok {
+			values := make([]string, 0, len(array))
+			for _, item := range array {
+				itemValue, err := jsonScalar(item)
+				if err != nil {
+					return nil, fmt.Errorf("option %q: %s", name, err.Error())
+				}
+				values = append(values, itemValue)
+			}
+			options[name] = values
+			continue
+		}
+		scalar, err := jsonScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %q: %s", name, err.Error())
+		}
+		options[name] = scalar
+	}
+	return options, nil
+}
+
+// jsonScalar returns with the string form of a JSON string, number or boolean
+func jsonScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("expected a string, number, boolean or a list of them")
+}
+
+// parseYAMLConfig returns with the options of a flat YAML mapping
+// Only the subset of YAML needed by the options is supported: scalars like "workers: 4" or "since: '2021-01-01'",
+// lists in flow style like "emails: [a@example.com, b@example.com]" or in block style with "- " items, and comments.
+func parseYAMLConfig(content string) (map[string]interface{}, error) {
+	options := map[string]interface{}{}
+	listName := ""
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(removeYAMLComment(line), " \t\r")
+		if strings.TrimSpace(line) == "" || line == "---" {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listName == "" || trimmed == line {
+				return nil, fmt.Errorf("line %d: list item without an option", i+1)
+			}
+			options[listName] = append(options[listName].([]string), unquoteYAML(strings.TrimSpace(trimmed[1:])))
+			continue
+		}
+		if trimmed != line {
+			return nil, fmt.Errorf("line %d: nested options are not supported", i+1)
+		}
+
+		separator := strings.Index(line, ":")
+		if separator == -1 {
+			return nil, fmt.Errorf("line %d: expected \"option: value\"", i+1)
+		}
+		name := strings.TrimSpace(line[:separator])
+		value := strings.TrimSpace(line[separator+1:])
+		listName = ""
+		switch {
+		case value == "":
+			// The items of a block list follow in the next lines
+			listName = name
+			options[name] = []string{}
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			values := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					values = append(values, unquoteYAML(item))
+				}
+			}
+			options[name] = values
+		default:
+			options[name] = unquoteYAML(value)
+		}
+	}
+	return options, nil
+}
+
+// removeYAMLComment removes the comment of the line, the # starts a comment at the beginning of the line
+// or after a space, but not in quoted values
+func removeYAMLComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquoteYAML removes the quotes of a quoted scalar
+func unquoteYAML(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+)
+
+var _ = Describe("Config", func() {
+	var (
+		dir           string
+		flags         *pflag.FlagSet
+		outputFormat  *string
+		workers       *int
+		raw           *bool
+		emails        *string
+		shellCommands *[]string
+		branches      *[]string
+		timeout       *time.Duration
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "extractor_config_")
+		Expect(err).ToNot(HaveOccurred())
+
+		flags = pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.SetNormalizeFunc(normalizeFlagName)
+		outputFormat = flags.String("output_format", "json", "")
+		workers = flags.Int("workers", 0, "")
+		raw = flags.Bool("raw", false, "")
+		emails = flags.String("emails", "", "")
+		shellCommands = flags.StringSlice("shell_commands", []string{"docker"}, "")
+		branches = flags.StringArray("branch", nil, "")
+		timeout = flags.Duration("upload_timeout", 30*time.Second, "")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	It("should set the options of a YAML file, the command line flags take precedence", func() {
+		path := writeConfig("config.yaml", `# extractor options
+output_format: ndjson
+workers: 8
+raw: true
+emails: [me@example.com, "me@work.example.com"]
+shell-commands:
+  - kubectl
+  - helm # deployments
+branch:
+  - main
+  - release/1.0
+upload_timeout: '1m'
+`)
+		Expect(flags.Parse([]string{"--workers", "2"})).To(Succeed())
+
+		Expect(loadConfigFile(path, flags)).To(Succeed())
+		Expect(*outputFormat).To(Equal("ndjson"))
+		Expect(*workers).To(Equal(2))
+		Expect(*raw).To(BeTrue())
+		Expect(*emails).To(Equal("me@example.com,me@work.example.com"))
+		Expect(*shellCommands).To(Equal([]string{"kubectl", "helm"}))
+		Expect(*branches).To(Equal([]string{"main", "release/1.0"}))
+		Expect(*timeout).To(Equal(time.Minute))
+	})
+
+	It("should set the options of a JSON file", func() {
+		path := writeConfig("config.json", `{"workers": 4, "raw": true, "shell_commands": [], "branch": ["main"]}`)
+		Expect(flags.Parse([]string{"--branch", "develop"})).To(Succeed())
+
+		Expect(loadConfigFile(path, flags)).To(Succeed())
+		Expect(*workers).To(Equal(4))
+		Expect(*raw).To(BeTrue())
+		Expect(*shellCommands).To(BeEmpty())
+		Expect(*branches).To(Equal([]string{"develop"}))
+		Expect(*outputFormat).To(Equal("json"))
+	})
+
+	It("should return an error for unknown options and invalid values", func() {
+		Expect(loadConfigFile(writeConfig("unknown.yml", "colour: blue\n"), flags)).To(MatchError(ContainSubstring(`unknown option "colour"`)))
+		Expect(loadConfigFile(writeConfig("invalid.json", `{"workers": "many"}`), flags)).To(MatchError(ContainSubstring(`option "workers"`)))
+		Expect(loadConfigFile(writeConfig("nested.yaml", "upload:\n  url: https://example.com\n"), flags)).To(MatchError(ContainSubstring("nested options are not supported")))
+	})
+})
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Techloopio/extractor_tool/extractor"
+	"github.com/Techloopio/extractor_tool/logger"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(emailsCmd)
+}
+
+var emailsCmd = &cobra.Command{
+	Use:   "emails <repo_path>",
+	Short: "List the emails of the authors as JSON, with the number of their commits",
+	Long: `Use this command to pick the emails of an automated extraction, which can be passed to --emails later.
+Only the authors of the commits are read, the files are not analysed, so it is much faster than an extraction.
+The emails are sorted by the number of commits, descending. --branch, --rev_range, --since, --until,
+--include_merges and --max_commits select the commits like during the extraction.
+Example usage: extractor_tool emails /path/to/repo`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gitPath, err := extractor.FindGit(*RootConfig.GitPath)
+		if err != nil {
+			return withExitCode(ExitGitNotFound, err)
+		}
+		since, until, err := parseDateRangeFlags()
+		if err != nil {
+			return withExitCode(ExitBadArguments, err)
+		}
+		log, err := logger.NewLogger(*RootConfig.LogFormat, os.Stderr, *RootConfig.Quiet)
+		if err != nil {
+			return withExitCode(ExitBadArguments, err)
+		}
+
+		repoExtractor := extractor.RepoExtractor{
+			RepoPath:              args[0],
+			GitPath:               gitPath,
+			Since:                 since,
+			Until:                 until,
+			Refs:                  *RootConfig.Refs,
+			RevRange:              *RootConfig.RevRange,
+			IncludeMerges:         *RootConfig.IncludeMerges,
+			MaxCommits:            *RootConfig.MaxCommits,
+			Identities:            *RootConfig.Identities,
+			NormalizeGitHubEmails: *RootConfig.NormalizeGitHubEmails,
+			Quiet:                 *RootConfig.Quiet,
+			Logger:                log,
+		}
+		emails, err := repoExtractor.ListEmails()
+		if err != nil {
+			return withExitCode(extractionExitCode(err), err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(emails); err != nil {
+			return withExitCode(ExitError, err)
+		}
+		return nil
+	},
+}
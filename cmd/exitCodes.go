@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/Techloopio/extractor_tool/extractor"
+)
+
+// The exit codes of the process, so the scripts and the CI jobs can tell the failures apart
+const (
+	// ExitSuccess means every repository was extracted, and uploaded if an upload was requested
+	ExitSuccess = 0
+	// ExitError is any other failure, like a failing git command or an export which couldn't be written
+	ExitError = 1
+	// ExitBadArguments means invalid flags, arguments or config file
+	ExitBadArguments = 2
+	// ExitGitNotFound means the git executable couldn't be found
+	ExitGitNotFound = 3
+	// ExitUploadFailed means the exports were written, but some of them couldn't be uploaded
+	ExitUploadFailed = 4
+)
+
+// exitError is the error of a command with the exit code of the process
+type exitError struct {
+	code int
+	err  error
+	// logged is true if the error was already written to the log, so it is not printed again
+	logged bool
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode returns with the error, which exits the process with the code
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns with the exit code of the error returned by a command
+// The errors without an exit code are returned by cobra itself, like the unknown flags, so they are bad arguments.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return ExitBadArguments
+}
+
+// extractionExitCode returns with the exit code of the error of the extraction
+func extractionExitCode(err error) int {
+	var uploadErr *extractor.UploadError
+	switch {
+	case errors.As(err, &uploadErr):
+		return ExitUploadFailed
+	case errors.Is(err, extractor.ErrInvalidOption), errors.Is(err, extractor.ErrNotGitRepository):
+		return ExitBadArguments
+	case errors.Is(err, extractor.ErrGitNotFound), errors.Is(err, extractor.ErrGitTooOld):
+		return ExitGitNotFound
+	}
+	return ExitError
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/extractor"
+)
+
+var _ = Describe("ExitCodes", func() {
+	It("should exit with the code of the error", func() {
+		Expect(exitCode(nil)).To(Equal(ExitSuccess))
+		Expect(exitCode(withExitCode(ExitGitNotFound, errors.New("git not found")))).To(Equal(ExitGitNotFound))
+		Expect(exitCode(fmt.Errorf("wrapped: %w", withExitCode(ExitError, errors.New("failure"))))).To(Equal(ExitError))
+	})
+
+	It("should treat the errors of cobra as bad arguments", func() {
+		Expect(exitCode(errors.New("unknown flag: --foo"))).To(Equal(ExitBadArguments))
+	})
+
+	It("should tell the failed uploads from the failed extractions", func() {
+		uploadErr := &extractor.UploadError{Path: "./export/repo.json", Err: errors.New("timeout")}
+		Expect(extractionExitCode(fmt.Errorf("1 export(s) couldn't be uploaded: %w", uploadErr))).To(Equal(ExitUploadFailed))
+		Expect(extractionExitCode(errors.New("1 repositories couldn't be extracted: repo"))).To(Equal(ExitError))
+	})
+
+	It("should tell the invalid options", func() {
+		options := []extractor.RepoExtractor{
+			{OutputFormat: "xml"},
+			{Aggregation: "year"},
+			{HashAlgorithm: "crc32"},
+			{MaxCommits: -1},
+			{RevRange: "v1.0..v2.0", Refs: []string{"main"}},
+			{Identities: []string{"alias@example.com"}},
+		}
+		for _, r := range options {
+			r.RepoPath = "./repo"
+			r.Quiet = true
+			err := r.Extract()
+			Expect(errors.Is(err, extractor.ErrInvalidOption)).To(BeTrue(), "%v", err)
+			Expect(extractionExitCode(fmt.Errorf("1 repositories couldn't be extracted: repo: %w", err))).To(Equal(ExitBadArguments))
+		}
+	})
+
+	It("should tell the git errors of the extraction", func() {
+		Expect(extractionExitCode(fmt.Errorf("wrapped: %w", extractor.ErrNotGitRepository))).To(Equal(ExitBadArguments))
+		Expect(extractionExitCode(fmt.Errorf("wrapped: %w", extractor.ErrGitNotFound))).To(Equal(ExitGitNotFound))
+		Expect(extractionExitCode(fmt.Errorf("wrapped: %w", extractor.ErrGitTooOld))).To(Equal(ExitGitNotFound))
+	})
+
+	It("should tell the missing git", func() {
+		_, err := extractor.FindGit("/path/to/nowhere/git")
+		Expect(errors.Is(err, extractor.ErrGitNotFound)).To(BeTrue())
+		Expect(exitCode(withExitCode(ExitGitNotFound, err))).To(Equal(ExitGitNotFound))
+	})
+})
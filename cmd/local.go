@@ -2,35 +2,129 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/Techloopio/extractor_tool/extractor"
+	"github.com/Techloopio/extractor_tool/logger"
 	repoSource "github.com/Techloopio/extractor_tool/repoSources"
+	"github.com/Techloopio/extractor_tool/s3"
 	"github.com/spf13/cobra"
 )
 
 type extractConfig struct {
-	RepoPath string
-	RepoName string
+	RepoPaths []string
+	RepoName  string
+	ReposDir  string
 }
 
 var (
 	localCmd = &cobra.Command{
 		Use:   "local",
-		Short: "Extract local repository by path",
-		Run: func(cmd *cobra.Command, args []string) {
-			source := repoSource.NewDirectoryPath(ExtractConfig.RepoPath, ExtractConfig.RepoName)
-			config := repoSource.ExtractConfig{
-				OutputPath:    *RootConfig.OutPutPath,
-				GitPath:       *RootConfig.GitPath,
-				HashImportant: *RootConfig.HashImportant,
-				UserEmails:    *RootConfig.Emails,
-				Seeds:         *RootConfig.Seeds,
-				SkipLibraries: *RootConfig.SkipLibraries,
+		Short: "Extract local repositories by path",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := newLocalSource()
+			if err != nil {
+				return withExitCode(ExitBadArguments, err)
+			}
+			// The extraction would fail anyway, but git is checked before the repositories are cloned
+			gitPath, err := extractor.FindGit(*RootConfig.GitPath)
+			if err != nil {
+				return withExitCode(ExitGitNotFound, err)
+			}
+			*RootConfig.GitPath = gitPath
+
+			since, until, err := parseDateRangeFlags()
+			if err != nil {
+				return withExitCode(ExitBadArguments, err)
+			}
+			emailPattern, err := parseRegexFlag("email_regex", *RootConfig.EmailRegex)
+			if err != nil {
+				return withExitCode(ExitBadArguments, err)
+			}
+
+			log, err := logger.NewLogger(*RootConfig.LogFormat, os.Stderr, *RootConfig.Quiet)
+			if err != nil {
+				return withExitCode(ExitBadArguments, err)
 			}
-			err := repoSource.ExtractFromSource(source, config)
 
+			var uploaders []extractor.Uploader
+			if *RootConfig.S3Destination != "" {
+				uploader, err := s3.NewUploader(*RootConfig.S3Destination)
+				if err != nil {
+					return withExitCode(ExitBadArguments, err)
+				}
+				uploaders = append(uploaders, uploader)
+			}
+
+			config := repoSource.ExtractConfig{
+				OutputPath:            *RootConfig.OutPutPath,
+				GitPath:               *RootConfig.GitPath,
+				HashImportant:         *RootConfig.HashImportant,
+				UserEmails:            *RootConfig.Emails,
+				Seeds:                 *RootConfig.Seeds,
+				SkipLibraries:         *RootConfig.SkipLibraries,
+				OutputFormat:          *RootConfig.OutputFormat,
+				Since:                 since,
+				Until:                 until,
+				Aggregation:           *RootConfig.Granularity,
+				UseAuthorTimezone:     *RootConfig.UseAuthorTimezone,
+				Workers:               *RootConfig.Workers,
+				ExcludePaths:          *RootConfig.ExcludePaths,
+				RespectGitignore:      *RootConfig.RespectGitignore,
+				GeneratedPaths:        *RootConfig.GeneratedPaths,
+				CountGenerated:        *RootConfig.CountGenerated,
+				Raw:                   *RootConfig.Raw,
+				ToolVersion:           Version,
+				LegacyFormat:          *RootConfig.LegacyFormat,
+				Pretty:                *RootConfig.Pretty,
+				HashAlgorithm:         *RootConfig.HashAlgorithm,
+				HashSalt:              *RootConfig.HashSalt,
+				ObfuscateEmails:       *RootConfig.ObfuscateEmails,
+				HashLibraries:         *RootConfig.HashLibraries,
+				HashMapOut:            *RootConfig.HashMapOut,
+				IncludeMessages:       *RootConfig.IncludeMessages,
+				IncludeLibraryCounts:  *RootConfig.IncludeLibraryCounts,
+				IncludeActivity:       *RootConfig.IncludeActivity,
+				Refs:                  *RootConfig.Refs,
+				RevRange:              *RootConfig.RevRange,
+				IncludeMerges:         *RootConfig.IncludeMerges,
+				MaxCommits:            *RootConfig.MaxCommits,
+				TimeLimit:             *RootConfig.TimeLimit,
+				StateFile:             *RootConfig.StateFile,
+				ForceRefresh:          *RootConfig.ForceRefresh,
+				DryRun:                *RootConfig.DryRun,
+				Quiet:                 *RootConfig.Quiet,
+				Compress:              *RootConfig.Compress,
+				UploadURL:             *RootConfig.UploadURL,
+				UploadToken:           *RootConfig.UploadToken,
+				UploadTimeout:         *RootConfig.UploadTimeout,
+				UploadRetries:         *RootConfig.UploadRetries,
+				Uploaders:             uploaders,
+				EmailPattern:          emailPattern,
+				Identities:            *RootConfig.Identities,
+				NormalizeGitHubEmails: *RootConfig.NormalizeGitHubEmails,
+				MinLanguageConfidence: *RootConfig.MinLanguageConfidence,
+				MaxFileSize:           *RootConfig.MaxFileSize,
+				GoOS:                  *RootConfig.GoOS,
+				GoArch:                *RootConfig.GoArch,
+				GoBuildTags:           *RootConfig.GoBuildTags,
+				ExcludeGoStdlib:       *RootConfig.ExcludeGoStdlib,
+				ExcludeTSTypes:        *RootConfig.ExcludeTSTypes,
+				PythonGranularity:     *RootConfig.PythonGranularity,
+				ExcludePythonStdlib:   *RootConfig.ExcludePythonStdlib,
+				PythonVersion:         *RootConfig.PythonVersion,
+				ShellCommands:         *RootConfig.ShellCommands,
+				PerlPragmas:           *RootConfig.PerlPragmas,
+				SkipLanguages:         *RootConfig.SkipLanguages,
+				OnlyLanguages:         *RootConfig.OnlyLanguages,
+				Logger:                log,
+			}
+			err = repoSource.ExtractFromSource(source, config)
 			if err != nil {
-				fmt.Println("Couldn't locally extract repo. Error:", err.Error())
+				log.Error("Couldn't locally extract repo", logger.Fields{"error": err.Error()})
+				return &exitError{code: extractionExitCode(err), err: err, logged: true}
 			}
+			return nil
 		},
 	}
 
@@ -39,7 +133,28 @@ var (
 
 func init() {
 	rootCmd.AddCommand(localCmd)
-	localCmd.Flags().StringVar(&ExtractConfig.RepoPath, "repo_path", "", "Path of the repo")
-	localCmd.MarkFlagRequired("repo_path")
-	localCmd.Flags().StringVar(&ExtractConfig.RepoName, "repo_name", "", "You can overwrite the default repo name. This name will be shown on the profile page.")
+	localCmd.Flags().StringArrayVar(&ExtractConfig.RepoPaths, "repo_path", nil, "Path of the repo. Can be repeated to extract several repos, one export is written for each of them.")
+	localCmd.Flags().StringVar(&ExtractConfig.RepoName, "repo_name", "", "You can overwrite the default repo name. This name will be shown on the profile page. Only allowed with a single --repo_path.")
+	localCmd.Flags().StringVar(&ExtractConfig.ReposDir, "repos_dir", "", "Every repo found in this directory and its subdirectories is extracted, named by its path relative to the directory.")
+}
+
+// newLocalSource returns with the source of the repos given by --repo_path or --repos_dir
+func newLocalSource() (repoSource.RepoSource, error) {
+	if len(ExtractConfig.RepoPaths) == 0 && ExtractConfig.ReposDir == "" {
+		return nil, fmt.Errorf("either --repo_path or --repos_dir must be set")
+	}
+	if len(ExtractConfig.RepoPaths) > 0 && ExtractConfig.ReposDir != "" {
+		return nil, fmt.Errorf("--repo_path and --repos_dir cannot be used together")
+	}
+	if ExtractConfig.RepoName != "" && len(ExtractConfig.RepoPaths) != 1 {
+		return nil, fmt.Errorf("--repo_name can only be used with a single --repo_path")
+	}
+
+	if ExtractConfig.ReposDir != "" {
+		return repoSource.NewReposDirectory(ExtractConfig.ReposDir)
+	}
+	if len(ExtractConfig.RepoPaths) == 1 {
+		return repoSource.NewDirectoryPath(ExtractConfig.RepoPaths[0], ExtractConfig.RepoName), nil
+	}
+	return repoSource.NewDirectoryPaths(ExtractConfig.RepoPaths), nil
 }
@@ -1,22 +1,83 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Techloopio/extractor_tool/extractor"
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
 )
 
 type rootConfig struct {
-	SkipLibraries *bool
-	SkipUpdate    *bool
-	Seeds         *[]string
-	Emails        *[]string
-	GitPath       *string
-	OutPutPath    *string
-	HashImportant *bool
+	ConfigFile            *string
+	SkipLibraries         *bool
+	SkipUpdate            *bool
+	Seeds                 *[]string
+	Emails                *[]string
+	GitPath               *string
+	OutPutPath            *string
+	HashImportant         *bool
+	OutputFormat          *string
+	Since                 *string
+	Until                 *string
+	Granularity           *string
+	UseAuthorTimezone     *bool
+	Workers               *int
+	ExcludePaths          *[]string
+	RespectGitignore      *bool
+	GeneratedPaths        *[]string
+	CountGenerated        *bool
+	Raw                   *bool
+	LegacyFormat          *bool
+	Pretty                *bool
+	HashAlgorithm         *string
+	HashSalt              *string
+	ObfuscateEmails       *bool
+	HashLibraries         *bool
+	HashMapOut            *string
+	IncludeMessages       *bool
+	IncludeLibraryCounts  *bool
+	IncludeActivity       *bool
+	Refs                  *[]string
+	RevRange              *string
+	IncludeMerges         *bool
+	MaxCommits            *int
+	TimeLimit             *time.Duration
+	StateFile             *string
+	ForceRefresh          *bool
+	DryRun                *bool
+	Quiet                 *bool
+	Compress              *bool
+	UploadURL             *string
+	UploadToken           *string
+	UploadTimeout         *time.Duration
+	UploadRetries         *int
+	S3Destination         *string
+	LogFormat             *string
+	EmailRegex            *string
+	Identities            *[]string
+	NormalizeGitHubEmails *bool
+	MinLanguageConfidence *float64
+	MaxFileSize           *int64
+	GoOS                  *string
+	GoArch                *string
+	GoBuildTags           *[]string
+	ExcludeGoStdlib       *bool
+	ExcludeTSTypes        *bool
+	PythonGranularity     *string
+	ExcludePythonStdlib   *bool
+	PythonVersion         *int
+	ShellCommands         *[]string
+	PerlPragmas           *[]string
+	SkipLanguages         *[]string
+	OnlyLanguages         *[]string
 }
 
 var (
@@ -29,31 +90,153 @@ Example usage: extractor_tool path --repo_path /path/to/repo`,
 
 	RootConfig rootConfig
 
-	emailString *string
-	seedsString *string
-	Version     string
+	emailString   *string
+	seedsString   *string
+	excludeString *string
+	Version       string
 )
 
+// Execute runs the command and exits with the exit code of its error, see ExitError and the other codes
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if err == nil {
+		return
+	}
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || !exitErr.logged {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
 	}
+	os.Exit(exitCode(err))
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	// The errors are printed by Execute, the usage is only printed with --help
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	// Both --hash_salt and --hash-salt are accepted
+	rootCmd.SetGlobalNormalizationFunc(normalizeFlagName)
 
+	RootConfig.ConfigFile = rootCmd.PersistentFlags().String("config", "", "YAML or JSON file of the options, keyed by the names of the flags. The flags given in the command line override the options of the file.")
 	RootConfig.SkipLibraries = rootCmd.PersistentFlags().Bool("skip_libraries", false, "Turns off the library detection in order to reduce the execution time")
 	RootConfig.SkipUpdate = rootCmd.PersistentFlags().Bool("skip_update", false, "If set the auto-update is skipped")
 	emailString = rootCmd.PersistentFlags().String("emails", "", "Predefined emails. Example: \"alim.giray@codersrank.io,alimgiray@gmail.com\"")
+	RootConfig.EmailRegex = rootCmd.PersistentFlags().String("email_regex", "", "The emails matching this regular expression are selected besides the predefined emails, without asking. Example: \"@ourcompany\\.com$\"")
+	RootConfig.Identities = rootCmd.PersistentFlags().StringArray("identity", nil, "Maps the emails of the same person to one email, like \"me@home.com=me@work.com\" or \"*@home.com=me@work.com\". \"*@users.noreply.github.com\" maps the emails of the domain to the other email of the author with the same name. Can be repeated.")
+	RootConfig.NormalizeGitHubEmails = rootCmd.PersistentFlags().Bool("normalize_github_emails", false, "The GitHub noreply emails with the ID of the user, like \"1234+octocat@users.noreply.github.com\", are replaced with the email without the ID, like \"octocat@users.noreply.github.com\".")
 	seedsString = rootCmd.PersistentFlags().String("seeds", "", "The seed is used to find similar emails. Example: \"alimgiray, alimgiray@codersrank.io\"")
-	RootConfig.GitPath = rootCmd.PersistentFlags().String("git_path", "", "where the Git binary is")
-	RootConfig.OutPutPath = rootCmd.PersistentFlags().String("output_path", "./export", "Where to put output file. Existing exports will be overwritten.")
+	RootConfig.GitPath = rootCmd.PersistentFlags().String("git_path", "", "Path of the git executable. By default git is looked up in the PATH.")
+	RootConfig.OutPutPath = rootCmd.PersistentFlags().String("output_path", "./export", "Where to put output file. Existing exports will be overwritten. Use \"-\" to write the export to the standard output.")
 	RootConfig.HashImportant = rootCmd.PersistentFlags().Bool("hash_important", false, "Emails will be hashed.")
+	RootConfig.Since = rootCmd.PersistentFlags().String("since", "", "Only commits made on or after this date are extracted. Format: YYYY-MM-DD")
+	RootConfig.Until = rootCmd.PersistentFlags().String("until", "", "Only commits made on or before this date are extracted. Format: YYYY-MM-DD")
+	RootConfig.Granularity = rootCmd.PersistentFlags().String("granularity", "day", "Aggregation period of the exported commits. Options: \"day\", \"week\", \"month\" or \"none\" to export every commit separately.")
+	RootConfig.UseAuthorTimezone = rootCmd.PersistentFlags().Bool("use_author_timezone", false, "Commits are aggregated by the calendar day of the author's timezone instead of UTC.")
+	RootConfig.Workers = rootCmd.PersistentFlags().Int("workers", 0, "Number of concurrent workers. Defaults to the number of CPUs.")
+	RootConfig.MinLanguageConfidence = rootCmd.PersistentFlags().Float64("min_language_confidence", 0, "Libraries are not extracted from files whose language is detected with lower confidence, like headers without C++ or Objective-C specific content. Between 0 and 1.")
+	RootConfig.MaxFileSize = rootCmd.PersistentFlags().Int64("max_file_size", 1024*1024, "Files larger than this (in bytes) are not read, their libraries are not extracted, but they still count in the stats. 0 means no limit.")
+	RootConfig.GoOS = rootCmd.PersistentFlags().String("go_os", "", "Target operating system of the Go files, like \"linux\". The imports of the files whose build constraints exclude it are not extracted.")
+	RootConfig.GoArch = rootCmd.PersistentFlags().String("go_arch", "", "Target architecture of the Go files, like \"amd64\". The imports of the files whose build constraints exclude it are not extracted.")
+	RootConfig.GoBuildTags = rootCmd.PersistentFlags().StringSlice("go_tags", nil, "Custom build tags of the Go files, like \"integration\". The imports of the files requiring other tags are not extracted.")
+	RootConfig.ExcludeGoStdlib = rootCmd.PersistentFlags().Bool("exclude_go_stdlib", false, "The imports of the Go standard library, like \"fmt\" or \"net/http\", are not extracted, only the third-party modules.")
+	RootConfig.ExcludeTSTypes = rootCmd.PersistentFlags().Bool("exclude_ts_type_imports", false, "The type-only imports of the TypeScript files, like \"import type { Foo } from 'bar'\", are not extracted, only the runtime dependencies.")
+	RootConfig.PythonGranularity = rootCmd.PersistentFlags().String("python_granularity", languages.PythonGranularityPackage, "Granularity of the Python libraries. \"package\" reports the top-level packages, like \"django\", \"module\" reports the imported modules, like \"django.db\".")
+	RootConfig.ExcludePythonStdlib = rootCmd.PersistentFlags().Bool("exclude_python_stdlib", false, "The imports of the Python standard library, like \"os\" or \"json\", are not extracted, only the third-party packages.")
+	RootConfig.PythonVersion = rootCmd.PersistentFlags().Int("python_version", 0, "Major version of Python, 2 or 3, whose standard library is excluded by --exclude_python_stdlib. 0 excludes the modules of both versions.")
+	RootConfig.ShellCommands = rootCmd.PersistentFlags().StringSlice("shell_commands", languages.DefaultShellCommands, "Command line tools detected as the libraries of the shell scripts, besides the sourced files. Use \"\" to detect none.")
+	RootConfig.PerlPragmas = rootCmd.PersistentFlags().StringSlice("perl_pragmas", languages.DefaultPerlPragmas, "Perl pragmas, like strict or warnings, which are not reported as libraries. Use \"\" to report every module.")
+	RootConfig.SkipLanguages = rootCmd.PersistentFlags().StringSlice("skip_languages", nil, "The files of these languages are not analysed and don't count in the stats. Example: \"SQL,JSON\"")
+	RootConfig.OnlyLanguages = rootCmd.PersistentFlags().StringSlice("only_languages", nil, "Only the files of these languages are analysed and count in the stats. Example: \"Go,Python\"")
+	excludeString = rootCmd.PersistentFlags().String("exclude", "", "Glob patterns of files which are not analysed. They are added to the default patterns ("+strings.Join(extractor.DefaultExcludePaths, ",")+"). Example: \"generated/,*.pb.go\"")
+	RootConfig.RespectGitignore = rootCmd.PersistentFlags().Bool("respect_gitignore", false, "The committed files matching the .gitignore files of the repository, like committed build artifacts, are not analysed.")
+	RootConfig.GeneratedPaths = rootCmd.PersistentFlags().StringSlice("generated_paths", extractor.DefaultGeneratedPaths, "Glob patterns of the generated files, which are skipped like the files with a \"DO NOT EDIT\" header. Use \"\" to only check the headers.")
+	RootConfig.CountGenerated = rootCmd.PersistentFlags().Bool("count_generated", false, "The generated files count in the stats, only their libraries are not extracted.")
+	RootConfig.Raw = rootCmd.PersistentFlags().Bool("raw", false, "Export every commit as a separate record with its hash, author and date instead of aggregating them. The size of the export grows with the number of commits")
+	RootConfig.LegacyFormat = rootCmd.PersistentFlags().Bool("legacy_format", false, "Export a bare JSON array of the commits, without the schema and tool versions")
+	RootConfig.Pretty = rootCmd.PersistentFlags().Bool("pretty", false, "Indent the JSON export to make it easier to read and diff. The ndjson export is always compact.")
+	RootConfig.HashAlgorithm = rootCmd.PersistentFlags().String("hash_algo", "md5", "Hash algorithm used by --hash_important. Options: \"md5\", \"sha1\" or \"sha256\"")
+	RootConfig.HashSalt = rootCmd.PersistentFlags().String("hash_salt", "", "Salt of the hashes created by --hash_important. Use the same salt to get the same hashes across runs.")
+	RootConfig.ObfuscateEmails = rootCmd.PersistentFlags().Bool("obfuscate_emails", false, "Author emails will be hashed in the export. The emails are still shown in clear text during the email selection.")
+	RootConfig.HashLibraries = rootCmd.PersistentFlags().Bool("hash_libraries", false, "Library names will be hashed in the export, like the names of internal packages. The languages, the dates and the line counts stay readable.")
+	RootConfig.HashMapOut = rootCmd.PersistentFlags().String("hash_map_out", "", "Path of a file where the hashed values are written with their hashes, so they can be reversed. It contains sensitive data, keep it secret.")
+	RootConfig.IncludeMessages = rootCmd.PersistentFlags().Bool("include_messages", false, "Export the commit messages too.")
+	RootConfig.IncludeLibraryCounts = rootCmd.PersistentFlags().Bool("library_counts", false, "Export the number of the changed files using every library in \"libraryCounts\", besides the list of the libraries.")
+	RootConfig.IncludeActivity = rootCmd.PersistentFlags().Bool("include_activity", false, "Export the number of active days and the longest streak of consecutive days of every author in the envelope of the json export.")
+	RootConfig.Refs = rootCmd.PersistentFlags().StringArray("branch", nil, "Only the commits of this branch (or other ref) are extracted. Can be repeated. All the branches are extracted by default.")
+	RootConfig.RevRange = rootCmd.PersistentFlags().String("rev_range", "", "Only the commits of this revision range are extracted, like \"v1.0..v2.0\". It cannot be used with --branch or --state_file.")
+	RootConfig.IncludeMerges = rootCmd.PersistentFlags().Bool("include_merges", false, "Merge commits are extracted too, with the lines changed compared to their first parent.")
+	RootConfig.MaxCommits = rootCmd.PersistentFlags().Int("max_commits", 0, "Only the most recent commits are extracted, e.g. for a quick preview of a huge history. 0 means no limit.")
+	RootConfig.TimeLimit = rootCmd.PersistentFlags().Duration("time_limit", 0, "Stop the extraction after the time limit and export the commits analysed so far, the export is marked as partial. Example: \"10m\". 0 means no limit.")
+	RootConfig.StateFile = rootCmd.PersistentFlags().String("state_file", "", "Path of a state file. If set, only the commits added since the previous extraction are processed and they are merged into the existing export.")
+	RootConfig.ForceRefresh = rootCmd.PersistentFlags().Bool("force_refresh", false, "Ignore the state file and process every commit again.")
+	RootConfig.DryRun = rootCmd.PersistentFlags().Bool("dry_run", false, "Only print the selected emails, the number of commits and the languages of the changed files, without analysing the libraries. No files are written.")
+	RootConfig.Quiet = rootCmd.PersistentFlags().Bool("quiet", false, "The progress bars and the informational messages are not shown. Errors are still written to the standard error.")
+	RootConfig.LogFormat = rootCmd.PersistentFlags().String("log_format", "text", "Format of the log written to the standard error. \"text\" writes human-readable lines, \"json\" writes one JSON object per line for every event.")
+	RootConfig.Compress = rootCmd.PersistentFlags().Bool("gzip", false, "Compress the export with gzip. \".gz\" is appended to the name of the output file.")
+	RootConfig.UploadURL = rootCmd.PersistentFlags().String("upload_url", "", "If set the export file is POSTed to this URL after the extraction. The local file is kept, and the exit code is non-zero if the upload fails.")
+	RootConfig.UploadToken = rootCmd.PersistentFlags().String("upload_token", "", "Bearer token sent in the Authorization header of the upload.")
+	RootConfig.UploadTimeout = rootCmd.PersistentFlags().Duration("upload_timeout", extractor.DefaultUploadTimeout, "Timeout of a single upload attempt. Example: \"30s\"")
+	RootConfig.UploadRetries = rootCmd.PersistentFlags().Int("upload_retries", 3, "Number of retries if the upload fails because of a network or server error.")
+	RootConfig.S3Destination = rootCmd.PersistentFlags().String("s3_destination", "", "If set the export file is uploaded to S3 after the extraction, keyed by the repo name and the time of the upload. Example: \"s3://bucket/prefix\"")
+	RootConfig.OutputFormat = rootCmd.PersistentFlags().String("output_format", "json", "Format of the output file. \"json\" writes the commits aggregated per day, \"ndjson\" writes one line per commit without aggregation.")
+}
+
+// normalizeFlagName makes the flags with dashes and underscores equivalent
+func normalizeFlagName(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	return pflag.NormalizedName(strings.Replace(name, "-", "_", -1))
+}
+
+// parseDateFlag parses a date in YYYY-MM-DD format in the local timezone.
+// Empty value means the date is not set.
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s date %q, expected format: YYYY-MM-DD", name, value)
+	}
+	return date, nil
+}
+
+// parseDateRangeFlags parses --since and --until, the whole day of --until is included
+func parseDateRangeFlags() (since, until time.Time, err error) {
+	since, err = parseDateFlag("since", *RootConfig.Since)
+	if err != nil {
+		return since, until, err
+	}
+	until, err = parseDateFlag("until", *RootConfig.Until)
+	if err != nil {
+		return since, until, err
+	}
+	if !until.IsZero() {
+		until = until.Add(24*time.Hour - time.Second)
+	}
+	return since, until, nil
+}
+
+// parseRegexFlag compiles a regular expression given in a flag.
+// Empty value means the regular expression is not set.
+func parseRegexFlag(name, value string) (*regexp.Regexp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s regular expression %q: %s", name, value, err.Error())
+	}
+	return pattern, nil
 }
 
 func initConfig() {
+	if *RootConfig.ConfigFile != "" {
+		err := loadConfigFile(*RootConfig.ConfigFile, rootCmd.PersistentFlags(), localCmd.Flags())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(ExitBadArguments)
+		}
+	}
+
 	emails := make([]string, 0)
 	if len(*emailString) > 0 {
 		emails = strings.Split(*emailString, ",")
@@ -67,19 +250,9 @@ func initConfig() {
 
 	RootConfig.Seeds = &seeds
 
-	// Find git executable if it is not provided
-	if *RootConfig.GitPath == "" {
-		gitPath, err := exec.LookPath("git")
-		if err != nil {
-			defaultGitPath := "/usr/bin/git"
-			fmt.Printf("Couldn't find git path. Fall back to default (%s). Error: %s.\n", defaultGitPath, err.Error())
-			// Try default git path
-			*RootConfig.GitPath = defaultGitPath
-			return
-		}
-		gitPath = strings.TrimRight(gitPath, "\r\n")
-		gitPath = strings.TrimRight(gitPath, "\n")
-
-		*RootConfig.GitPath = gitPath
+	excludePaths := append([]string{}, extractor.DefaultExcludePaths...)
+	if len(*excludeString) > 0 {
+		excludePaths = append(excludePaths, strings.Split(*excludeString, ",")...)
 	}
+	RootConfig.ExcludePaths = &excludePaths
 }
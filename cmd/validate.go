@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/Techloopio/extractor_tool/export"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Check an export file against the schema of the export",
+	Long: `Use this command to check if an export file, which might be created by an older version, has the expected structure.
+The mismatches are written to the standard error and the exit code is non-zero if there is any.
+Example usage: extractor_tool validate ./export/repo_techloop.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return withExitCode(ExitError, err)
+		}
+
+		issues, err := export.Validate(content)
+		if err != nil {
+			return withExitCode(ExitError, err)
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(os.Stderr, issue.String())
+		}
+		if len(issues) > 0 {
+			return withExitCode(ExitError, fmt.Errorf("%s doesn't match the schema: %d mismatch(es)", args[0], len(issues)))
+		}
+		fmt.Printf("%s is valid\n", args[0])
+		return nil
+	},
+}
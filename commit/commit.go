@@ -5,18 +5,58 @@ type Commit struct {
 	AuthorName   string
 	AuthorEmail  string
 	Date         string
+	Message      string
+	CoAuthors    []CoAuthor
 	ChangedFiles []*ChangedFile
 	Libraries    map[string][]string
 }
 
+// CoAuthor is an additional author listed in a Co-authored-by trailer of the commit message
+type CoAuthor struct {
+	Name  string
+	Email string
+}
+
 type OptimizedCommitForExport struct {
-	AuthorEmails []string            `json:"authorEmails"`
-	Date         string              `json:"date"`
-	Languages    []string            `json:"languages"`
-	Insertions   int                 `json:"insertions"`
-	Deletions    int                 `json:"deletions"`
-	Libraries    map[string][]string `json:"libraries"`
-	Commits      int                 `json:"commits"`
+	AuthorEmails []string `json:"authorEmails"`
+	Date         string   `json:"date"`
+	Languages    []string `json:"languages"`
+	Insertions   int      `json:"insertions"`
+	Deletions    int      `json:"deletions"`
+	BinaryFiles  int      `json:"binaryFiles"`
+	FilesChanged int      `json:"filesChanged"` // Changed files, including the binary and the renamed ones
+	// LanguageLines are the inserted and deleted lines by language, Insertions and Deletions are the totals of every file
+	LanguageLines map[string]LanguageLines `json:"languageLines"`
+	Libraries     map[string][]string      `json:"libraries"`
+	// LibraryCounts are the number of the changed files using the libraries by language, if they are exported
+	LibraryCounts map[string]map[string]int `json:"libraryCounts,omitempty"`
+	Commits       int                       `json:"commits"`
+	Messages      []string                  `json:"messages,omitempty"`
+}
+
+// RawCommitForExport is a single, non-aggregated commit
+type RawCommitForExport struct {
+	Hash           string                    `json:"hash"`
+	AuthorName     string                    `json:"authorName"`
+	AuthorEmail    string                    `json:"authorEmail"`
+	CoAuthorEmails []string                  `json:"coAuthorEmails,omitempty"`
+	Date           string                    `json:"date"`
+	Languages      []string                  `json:"languages"`
+	Insertions     int                       `json:"insertions"`
+	Deletions      int                       `json:"deletions"`
+	BinaryFiles    int                       `json:"binaryFiles"`
+	FilesChanged   int                       `json:"filesChanged"`
+	LanguageLines  map[string]LanguageLines  `json:"languageLines"`
+	Libraries      map[string][]string       `json:"libraries"`
+	LibraryCounts  map[string]map[string]int `json:"libraryCounts,omitempty"`
+	Message        string                    `json:"message,omitempty"`
+}
+
+// LanguageLines are the changed lines of a language in a commit or in the aggregated commits
+// The files without detected language only count in the totals of the commit.
+type LanguageLines struct {
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
 }
 
 type ChangedFile struct {
@@ -24,4 +64,5 @@ type ChangedFile struct {
 	Insertions int    `json:"insertions"`
 	Deletions  int    `json:"deletions"`
 	Language   string `json:"language"`
+	Binary     bool   `json:"binary"`
 }
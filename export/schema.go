@@ -0,0 +1,88 @@
+package export
+
+// FieldType is the JSON type of a field of the export
+type FieldType string
+
+const (
+	// TypeString is a JSON string
+	TypeString FieldType = "string"
+	// TypeDate is a JSON string with a date like "2021-03-18 00:00:00 +0000 UTC"
+	TypeDate FieldType = "date"
+	// TypeInteger is a JSON number without fraction
+	TypeInteger FieldType = "integer"
+	// TypeStringArray is a JSON array of strings
+	TypeStringArray FieldType = "string array"
+	// TypeLibraries is a JSON object whose values are arrays of strings, like {"Go": ["fmt"]}
+	TypeLibraries FieldType = "libraries"
+	// TypeRecords is a JSON array of commit records
+	TypeRecords FieldType = "records"
+	// TypeObject is any JSON object
+	TypeObject FieldType = "object"
+)
+
+// Field is a field of a JSON object of the export
+type Field struct {
+	Name     string
+	Type     FieldType
+	Required bool
+}
+
+// Schema is the list of the fields of a JSON object of the export
+// Fields which are not in the schema are mismatches too.
+type Schema []Field
+
+// EnvelopeSchema is the schema of the JSON export, which wraps the commit records
+var EnvelopeSchema = Schema{
+	{Name: "schemaVersion", Type: TypeInteger, Required: true},
+	{Name: "toolVersion", Type: TypeString, Required: true},
+	{Name: "repo", Type: TypeString, Required: true},
+	{Name: "commits", Type: TypeRecords, Required: true},
+	{Name: "languages", Type: TypeObject},
+	{Name: "activity", Type: TypeObject},
+	{Name: "partial", Type: TypeObject},
+}
+
+// CommitSchema is the schema of the aggregated commit records, see commit.OptimizedCommitForExport
+var CommitSchema = Schema{
+	{Name: "authorEmails", Type: TypeStringArray, Required: true},
+	{Name: "date", Type: TypeDate, Required: true},
+	{Name: "languages", Type: TypeStringArray, Required: true},
+	{Name: "insertions", Type: TypeInteger, Required: true},
+	{Name: "deletions", Type: TypeInteger, Required: true},
+	// The exports written before the binary files were counted don't have it
+	{Name: "binaryFiles", Type: TypeInteger},
+	{Name: "filesChanged", Type: TypeInteger},
+	{Name: "languageLines", Type: TypeObject},
+	{Name: "libraries", Type: TypeLibraries, Required: true},
+	{Name: "libraryCounts", Type: TypeObject},
+	{Name: "commits", Type: TypeInteger, Required: true},
+	{Name: "messages", Type: TypeStringArray},
+}
+
+// RawCommitSchema is the schema of the records of the --raw export, see commit.RawCommitForExport
+var RawCommitSchema = Schema{
+	{Name: "hash", Type: TypeString, Required: true},
+	{Name: "authorName", Type: TypeString, Required: true},
+	{Name: "authorEmail", Type: TypeString, Required: true},
+	{Name: "coAuthorEmails", Type: TypeStringArray},
+	{Name: "date", Type: TypeDate, Required: true},
+	{Name: "languages", Type: TypeStringArray, Required: true},
+	{Name: "insertions", Type: TypeInteger, Required: true},
+	{Name: "deletions", Type: TypeInteger, Required: true},
+	{Name: "binaryFiles", Type: TypeInteger},
+	{Name: "filesChanged", Type: TypeInteger},
+	{Name: "languageLines", Type: TypeObject},
+	{Name: "libraries", Type: TypeLibraries, Required: true},
+	{Name: "libraryCounts", Type: TypeObject},
+	{Name: "message", Type: TypeString},
+}
+
+// field returns with the field of the schema by its name
+func (s Schema) field(name string) (Field, bool) {
+	for _, f := range s {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
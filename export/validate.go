@@ -0,0 +1,230 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/Techloopio/extractor_tool/extractor"
+)
+
+// Issue is a mismatch between the export and its schema
+type Issue struct {
+	// Path of the mismatching value like "commits[3].insertions", or "line 4" in the ndjson exports
+	Path    string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// Validate checks the export against the schema and returns with the mismatches
+// Every format is accepted: the JSON export with the envelope, the bare array of the legacy format and ndjson,
+// compressed with gzip or not. An error is returned if the content cannot be decoded at all.
+func Validate(content []byte) ([]Issue, error) {
+	// gzip files start with these magic bytes
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		content, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the export is not valid JSON: %s", err.Error())
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("the export is empty")
+	}
+
+	// Every line of the ndjson export is a record
+	if len(values) > 1 {
+		var issues []Issue
+		for i, value := range values {
+			issues = append(issues, validateRecord(fmt.Sprintf("line %d", i+1), value)...)
+		}
+		return issues, nil
+	}
+
+	switch value := values[0].(type) {
+	case []interface{}:
+		// The bare array of the legacy format
+		return validateRecords("", value), nil
+	case map[string]interface{}:
+		if _, ok := value["schemaVersion"]; ok {
+			return validateEnvelope(value), nil
+		}
+		// The ndjson export of a single commit
+		return validateRecord("line 1", value), nil
+	}
+	return []Issue{{Message: "expected a JSON object or array"}}, nil
+}
+
+func validateEnvelope(envelope map[string]interface{}) []Issue {
+	issues := validateObject("", envelope, EnvelopeSchema)
+	if version, ok := envelope["schemaVersion"].(json.Number); ok {
+		if v, err := version.Int64(); err == nil && v > extractor.SchemaVersion {
+			issues = append(issues, Issue{Path: "schemaVersion", Message: fmt.Sprintf("version %d is newer than the supported version %d", v, extractor.SchemaVersion)})
+		}
+	}
+	if commits, ok := envelope["commits"].([]interface{}); ok {
+		issues = append(issues, validateRecords("commits", commits)...)
+	}
+	return issues
+}
+
+func validateRecords(path string, records []interface{}) []Issue {
+	var issues []Issue
+	for i, record := range records {
+		issues = append(issues, validateRecord(fmt.Sprintf("%s[%d]", path, i), record)...)
+	}
+	return issues
+}
+
+// validateRecord checks a commit record, the records of the --raw export are recognized by their hash
+func validateRecord(path string, record interface{}) []Issue {
+	object, ok := record.(map[string]interface{})
+	if !ok {
+		return []Issue{{Path: path, Message: "expected a commit object"}}
+	}
+	if _, ok := object["hash"]; ok {
+		return validateObject(path, object, RawCommitSchema)
+	}
+	return validateObject(path, object, CommitSchema)
+}
+
+func validateObject(path string, object map[string]interface{}, schema Schema) []Issue {
+	var issues []Issue
+	for _, field := range schema {
+		value, ok := object[field.Name]
+		if !ok {
+			if field.Required {
+				issues = append(issues, Issue{Path: joinPath(path, field.Name), Message: "missing field"})
+			}
+			continue
+		}
+		if !hasType(value, field.Type) {
+			issues = append(issues, Issue{Path: joinPath(path, field.Name), Message: fmt.Sprintf("expected %s, got %s", field.Type, describe(value))})
+		}
+	}
+
+	var unknownFields []string
+	for name := range object {
+		if _, ok := schema.field(name); !ok {
+			unknownFields = append(unknownFields, name)
+		}
+	}
+	sort.Strings(unknownFields)
+	for _, name := range unknownFields {
+		issues = append(issues, Issue{Path: joinPath(path, name), Message: "unknown field"})
+	}
+	return issues
+}
+
+// hasType tells if the value has the type of the field
+// Arrays and objects can be null, as the empty slices and maps are exported as null.
+func hasType(value interface{}, fieldType FieldType) bool {
+	switch fieldType {
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeDate:
+		date, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.Parse("2006-01-02 15:04:05 -0700 MST", date)
+		return err == nil
+	case TypeInteger:
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case TypeStringArray:
+		return value == nil || isStringArray(value)
+	case TypeLibraries:
+		if value == nil {
+			return true
+		}
+		libraries, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for _, names := range libraries {
+			if names != nil && !isStringArray(names) {
+				return false
+			}
+		}
+		return true
+	case TypeRecords:
+		_, ok := value.([]interface{})
+		return ok || value == nil
+	case TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+func isStringArray(value interface{}) bool {
+	array, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range array {
+		if _, ok := item.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns with the JSON type of the value for the messages
+func describe(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
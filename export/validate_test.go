@@ -0,0 +1,103 @@
+package export_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/export"
+)
+
+const validCommit = `{"authorEmails":["developer@example.com"],"date":"2021-03-18 00:00:00 +0000 UTC","languages":["Go"],"insertions":10,"deletions":2,"binaryFiles":0,"libraries":{"Go":["fmt"]},"commits":1}`
+
+// jsonFields returns with the names of the JSON fields of the struct
+func jsonFields(value interface{}) []string {
+	var fields []string
+	t := reflect.TypeOf(value)
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, strings.Split(t.Field(i).Tag.Get("json"), ",")[0])
+	}
+	return fields
+}
+
+func schemaFields(schema export.Schema) []string {
+	var fields []string
+	for _, field := range schema {
+		fields = append(fields, field.Name)
+	}
+	return fields
+}
+
+var _ = Describe("Schema", func() {
+	It("should have the fields of the exported commits", func() {
+		Expect(schemaFields(export.CommitSchema)).To(ConsistOf(jsonFields(commit.OptimizedCommitForExport{})))
+		Expect(schemaFields(export.RawCommitSchema)).To(ConsistOf(jsonFields(commit.RawCommitForExport{})))
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("should accept the export with the envelope", func() {
+		issues, err := export.Validate([]byte(`{"schemaVersion":1,"toolVersion":"v1.0.0","repo":"repo","commits":[` + validCommit + `]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+	})
+
+	It("should accept the export written before the binary files were counted", func() {
+		olderCommit := strings.Replace(validCommit, `"binaryFiles":0,`, "", 1)
+		issues, err := export.Validate([]byte(`{"schemaVersion":1,"toolVersion":"v1.0.0","repo":"repo","commits":[` + olderCommit + `]}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+	})
+
+	It("should accept the legacy and the ndjson exports", func() {
+		issues, err := export.Validate([]byte("[" + validCommit + "," + validCommit + "]"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+
+		issues, err = export.Validate([]byte(validCommit + "\n" + validCommit + "\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+	})
+
+	It("should accept the compressed export", func() {
+		var buffer bytes.Buffer
+		w := gzip.NewWriter(&buffer)
+		w.Write([]byte("[" + validCommit + "]"))
+		w.Close()
+
+		issues, err := export.Validate(buffer.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(BeEmpty())
+	})
+
+	It("should report the mismatches", func() {
+		content := `{"schemaVersion":2,"toolVersion":"v1.0.0","commits":[` + validCommit + `,` +
+			`{"authorEmails":"developer@example.com","date":"2021-03-18","languages":null,"insertions":"10","deletions":2,"binaryFiles":0,"libraries":{"Go":[1]},"commits":1,"files":3}]}`
+		issues, err := export.Validate([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+
+		var messages []string
+		for _, issue := range issues {
+			messages = append(messages, issue.String())
+		}
+		Expect(messages).To(ConsistOf(
+			"repo: missing field",
+			"schemaVersion: version 2 is newer than the supported version 1",
+			"commits[1].authorEmails: expected string array, got string",
+			"commits[1].date: expected date, got string",
+			"commits[1].insertions: expected integer, got string",
+			"commits[1].libraries: expected libraries, got object",
+			"commits[1].files: unknown field",
+		))
+	})
+
+	It("should return an error if the export is not JSON", func() {
+		_, err := export.Validate([]byte("commits"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,81 @@
+package extractor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Techloopio/extractor_tool/commit"
+)
+
+// AuthorActivity tells on how many days an author committed, and the most consecutive days they committed on
+type AuthorActivity struct {
+	ActiveDays    int `json:"activeDays"`
+	LongestStreak int `json:"longestStreak"`
+}
+
+// getAuthorActivity returns with the activity of every author email of the exported records
+// The dates of the records are already bucketed by day, or they are the exact dates without aggregation.
+func getAuthorActivity(records []commit.OptimizedCommitForExport) map[string]AuthorActivity {
+	days := map[string]map[string]bool{}
+	for _, record := range records {
+		for _, email := range record.AuthorEmails {
+			addActiveDay(days, email, record.Date)
+		}
+	}
+	return getActivityOfDays(days)
+}
+
+// getRawAuthorActivity returns with the activity of every author and co-author email of the raw records
+func getRawAuthorActivity(records []commit.RawCommitForExport) map[string]AuthorActivity {
+	days := map[string]map[string]bool{}
+	for _, record := range records {
+		addActiveDay(days, record.AuthorEmail, record.Date)
+		for _, email := range record.CoAuthorEmails {
+			addActiveDay(days, email, record.Date)
+		}
+	}
+	return getActivityOfDays(days)
+}
+
+// addActiveDay adds the day of an exported date like "2021-03-18 00:00:00 +0000 UTC" to the days of the email
+func addActiveDay(days map[string]map[string]bool, email string, date string) {
+	if len(date) < len("2006-01-02") {
+		return
+	}
+	if days[email] == nil {
+		days[email] = map[string]bool{}
+	}
+	days[email][date[:len("2006-01-02")]] = true
+}
+
+func getActivityOfDays(days map[string]map[string]bool) map[string]AuthorActivity {
+	activity := make(map[string]AuthorActivity, len(days))
+	for email, emailDays := range days {
+		sortedDays := make([]time.Time, 0, len(emailDays))
+		for day := range emailDays {
+			parsed, err := time.Parse("2006-01-02", day)
+			if err != nil {
+				continue
+			}
+			sortedDays = append(sortedDays, parsed)
+		}
+		sort.Slice(sortedDays, func(i, j int) bool {
+			return sortedDays[i].Before(sortedDays[j])
+		})
+
+		longestStreak := 0
+		streak := 0
+		for i, day := range sortedDays {
+			if i > 0 && day.Sub(sortedDays[i-1]) == 24*time.Hour {
+				streak++
+			} else {
+				streak = 1
+			}
+			if streak > longestStreak {
+				longestStreak = streak
+			}
+		}
+		activity[email] = AuthorActivity{ActiveDays: len(sortedDays), LongestStreak: longestStreak}
+	}
+	return activity
+}
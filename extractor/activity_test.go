@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/obfuscation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetAuthorActivity", func() {
+	It("should count the active days and the longest streak of every author", func() {
+		activity := getAuthorActivity([]commit.OptimizedCommitForExport{
+			{AuthorEmails: []string{"first@example.com"}, Date: "2021-03-01 00:00:00 +0000 UTC"},
+			{AuthorEmails: []string{"first@example.com", "second@example.com"}, Date: "2021-03-02 00:00:00 +0000 UTC"},
+			{AuthorEmails: []string{"first@example.com"}, Date: "2021-03-03 00:00:00 +0000 UTC"},
+			{AuthorEmails: []string{"first@example.com", "second@example.com"}, Date: "2021-03-10 00:00:00 +0000 UTC"},
+			{AuthorEmails: []string{"first@example.com"}, Date: "2021-03-11 00:00:00 +0000 UTC"},
+		})
+
+		Expect(activity).To(Equal(map[string]AuthorActivity{
+			"first@example.com":  {ActiveDays: 5, LongestStreak: 3},
+			"second@example.com": {ActiveDays: 2, LongestStreak: 1},
+		}))
+	})
+
+	It("should count the commits of the same day once without aggregation", func() {
+		activity := getRawAuthorActivity([]commit.RawCommitForExport{
+			{AuthorEmail: "first@example.com", Date: "2021-02-28 09:00:00 +0000 UTC"},
+			{AuthorEmail: "first@example.com", Date: "2021-02-28 18:00:00 +0000 UTC"},
+			{AuthorEmail: "first@example.com", CoAuthorEmails: []string{"second@example.com"}, Date: "2021-03-01 10:00:00 +0000 UTC"},
+		})
+
+		Expect(activity).To(Equal(map[string]AuthorActivity{
+			"first@example.com":  {ActiveDays: 2, LongestStreak: 2},
+			"second@example.com": {ActiveDays: 1, LongestStreak: 1},
+		}))
+	})
+
+	It("should write the activity to the envelope", func() {
+		for _, pretty := range []bool{false, true} {
+			obfuscator, err := obfuscation.NewObfuscator("", "")
+			Expect(err).ToNot(HaveOccurred())
+			r := &RepoExtractor{
+				IncludeActivity:            true,
+				Pretty:                     pretty,
+				obfuscator:                 obfuscator,
+				commitPipeline:             make(chan commit.Commit),
+				libraryExtractionCompleted: make(chan bool),
+			}
+			go func() {
+				r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000"}
+				r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-19 10:00:00 +0000"}
+				r.libraryExtractionCompleted <- true
+			}()
+
+			var buffer bytes.Buffer
+			w := bufio.NewWriter(&buffer)
+			Expect(r.writeExport(w, nil, nil)).To(Succeed())
+			w.Flush()
+
+			var envelope struct {
+				Commits  []commit.OptimizedCommitForExport `json:"commits"`
+				Activity map[string]AuthorActivity         `json:"activity"`
+			}
+			Expect(json.Unmarshal(buffer.Bytes(), &envelope)).To(Succeed())
+			Expect(envelope.Commits).To(HaveLen(2))
+			Expect(envelope.Activity).To(Equal(map[string]AuthorActivity{
+				"developer@example.com": {ActiveDays: 2, LongestStreak: 2},
+			}))
+		}
+	})
+})
@@ -0,0 +1,46 @@
+package extractor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetAggregationStartFromStringDate", func() {
+	date := "2021-03-18 15:04:05 +0000"
+
+	It("should aggregate per day by default", func() {
+		Expect(getAggregationStartFromStringDate(date, "", false).String()).To(Equal("2021-03-18 00:00:00 +0000 UTC"))
+		Expect(getAggregationStartFromStringDate(date, AggregationDay, false).String()).To(Equal("2021-03-18 00:00:00 +0000 UTC"))
+	})
+
+	It("should aggregate per week starting on Monday", func() {
+		Expect(getAggregationStartFromStringDate(date, AggregationWeek, false).String()).To(Equal("2021-03-15 00:00:00 +0000 UTC"))
+		Expect(getAggregationStartFromStringDate("2021-03-21 23:59:59 +0000", AggregationWeek, false).String()).To(Equal("2021-03-15 00:00:00 +0000 UTC"))
+		Expect(getAggregationStartFromStringDate("2021-03-15 00:00:00 +0000", AggregationWeek, false).String()).To(Equal("2021-03-15 00:00:00 +0000 UTC"))
+	})
+
+	It("should aggregate per month", func() {
+		Expect(getAggregationStartFromStringDate(date, AggregationMonth, false).String()).To(Equal("2021-03-01 00:00:00 +0000 UTC"))
+	})
+
+	It("should keep the exact date without aggregation", func() {
+		Expect(getAggregationStartFromStringDate(date, AggregationNone, false).String()).To(Equal("2021-03-18 15:04:05 +0000 UTC"))
+	})
+
+	Context("commits near midnight in non-UTC timezones", func() {
+		It("should use the calendar day in UTC by default", func() {
+			Expect(getAggregationStartFromStringDate("2021-01-02 00:30:00 +0200", AggregationDay, false).String()).To(Equal("2021-01-01 00:00:00 +0000 UTC"))
+			Expect(getAggregationStartFromStringDate("2021-01-01 22:00:00 -0500", AggregationDay, false).String()).To(Equal("2021-01-02 00:00:00 +0000 UTC"))
+		})
+
+		It("should use the calendar day of the author if it is set", func() {
+			Expect(getAggregationStartFromStringDate("2021-01-02 00:30:00 +0200", AggregationDay, true).String()).To(Equal("2021-01-02 00:00:00 +0000 UTC"))
+			Expect(getAggregationStartFromStringDate("2021-01-01 22:00:00 -0500", AggregationDay, true).String()).To(Equal("2021-01-01 00:00:00 +0000 UTC"))
+		})
+
+		It("should use the month of the author if it is set", func() {
+			Expect(getAggregationStartFromStringDate("2021-02-01 00:30:00 +0200", AggregationMonth, false).String()).To(Equal("2021-01-01 00:00:00 +0000 UTC"))
+			Expect(getAggregationStartFromStringDate("2021-02-01 00:30:00 +0200", AggregationMonth, true).String()).To(Equal("2021-02-01 00:00:00 +0000 UTC"))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package extractor
+
+import "sync"
+
+// blobCache stores the libraries extracted from already analysed blobs.
+// The same blob (file content) can appear in many commits, e.g. vendored files,
+// but it has to be read and analysed only once.
+// It is safe for concurrent use by multiple library workers.
+type blobCache struct {
+	mutex     sync.RWMutex
+	libraries map[string][]string
+	generated map[string]bool
+}
+
+func newBlobCache() *blobCache {
+	return &blobCache{
+		libraries: make(map[string][]string),
+		generated: make(map[string]bool),
+	}
+}
+
+// get returns with the libraries of the blob analysed as the given language
+func (c *blobCache) get(lang, blobHash string) ([]string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	libraries, ok := c.libraries[lang+":"+blobHash]
+	return libraries, ok
+}
+
+// set stores the libraries of the blob analysed as the given language
+// The stored slice must not be modified afterwards.
+func (c *blobCache) set(lang, blobHash string, libraries []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.libraries[lang+":"+blobHash] = libraries
+}
+
+// isGenerated tells if the blob was found to be a generated file
+func (c *blobCache) isGenerated(blobHash string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.generated[blobHash]
+}
+
+// setGenerated stores that the blob is a generated file, so its libraries are not extracted
+func (c *blobCache) setGenerated(blobHash string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generated[blobHash] = true
+}
@@ -0,0 +1,108 @@
+package extractor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// catFile reads file contents through a single long-lived "git cat-file --batch" process
+// instead of starting a new "git show" process for every file.
+// It is not safe for concurrent use, every library worker has its own instance.
+type catFile struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	// err is set if reading the output failed. The output cannot be parsed after that.
+	err error
+}
+
+// newCatFile starts the "git cat-file --batch" process in the repo
+// The process is killed when the context is done, like at the time limit.
+func newCatFile(ctx context.Context, gitPath, repoPath string) (*catFile, error) {
+	cmd := exec.CommandContext(ctx, gitPath,
+		"cat-file",
+		"--batch",
+	)
+	cmd.Dir = repoPath
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &catFile{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// getFileContent returns with the content of the file in the given commit
+// If the file doesn't exist in the commit (e.g. it was deleted) the content is empty.
+func (c *catFile) getFileContent(commitHash, filePath string) ([]byte, error) {
+	// The requests are separated by new lines, such paths cannot be requested
+	if strings.ContainsAny(filePath, "\r\n") {
+		return nil, fmt.Errorf("cannot request path with new line: %q", filePath)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	content, err := c.read(commitHash, filePath)
+	if err != nil {
+		c.err = err
+	}
+	return content, err
+}
+
+func (c *catFile) read(commitHash, filePath string) ([]byte, error) {
+	_, err := fmt.Fprintf(c.stdin, "%s:%s\n", commitHash, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response starts with a header line: "<hash> <type> <size>" or "<object> missing"
+	header, err := c.stdout.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) > 0 && fields[len(fields)-1] == "missing" {
+		return []byte{}, nil
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected git cat-file header: %s", header)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected git cat-file header: %s", header)
+	}
+
+	// The content is followed by a new line
+	content := make([]byte, size+1)
+	_, err = io.ReadFull(c.stdout, content)
+	if err != nil {
+		return nil, err
+	}
+	if fields[1] != "blob" {
+		return []byte{}, nil
+	}
+	return content[:size], nil
+}
+
+// close stops the "git cat-file --batch" process
+func (c *catFile) close() error {
+	c.stdin.Close()
+	return c.cmd.Wait()
+}
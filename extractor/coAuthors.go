@@ -0,0 +1,30 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/commit"
+)
+
+// regex to find trailers like "Co-authored-by: Jane Doe <jane@example.com>"
+var coAuthorRegex = regexp.MustCompile(`(?mi)^[ \t]*co-authored-by:[ \t]*([^<\n]*?)[ \t]*<([^>\n]+)>`)
+
+// parseCoAuthors returns with the co-authors listed in the Co-authored-by trailers of the commit message
+// The primary author and the duplicates are left out.
+func parseCoAuthors(message, authorEmail string) []commit.CoAuthor {
+	var coAuthors []commit.CoAuthor
+	seenEmails := map[string]bool{strings.ToLower(authorEmail): true}
+	for _, match := range coAuthorRegex.FindAllStringSubmatch(message, -1) {
+		email := strings.TrimSpace(match[2])
+		if email == "" || seenEmails[strings.ToLower(email)] {
+			continue
+		}
+		seenEmails[strings.ToLower(email)] = true
+		coAuthors = append(coAuthors, commit.CoAuthor{
+			Name:  match[1],
+			Email: email,
+		})
+	}
+	return coAuthors
+}
@@ -0,0 +1,50 @@
+package extractor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/commit"
+)
+
+var _ = Describe("ParseCoAuthors", func() {
+	It("should parse a single co-author", func() {
+		message := "Add parser\n\nCo-authored-by: Jane Doe <jane@example.com>"
+		Expect(parseCoAuthors(message, "john@example.com")).To(Equal([]commit.CoAuthor{
+			{Name: "Jane Doe", Email: "jane@example.com"},
+		}))
+	})
+
+	It("should parse multiple co-authors without duplicates", func() {
+		message := "Add parser\n\nPaired on the tokenizer.\n\n" +
+			"Co-authored-by: Jane Doe <jane@example.com>\n" +
+			"co-authored-by: Bob <bob@example.com>\n" +
+			"Co-Authored-By: Jane Doe <Jane@Example.com>\n" +
+			"Co-authored-by: John Doe <john@example.com>"
+		Expect(parseCoAuthors(message, "john@example.com")).To(Equal([]commit.CoAuthor{
+			{Name: "Jane Doe", Email: "jane@example.com"},
+			{Name: "Bob", Email: "bob@example.com"},
+		}))
+	})
+
+	It("should return nothing without trailers", func() {
+		Expect(parseCoAuthors("Add parser", "john@example.com")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("EmailCollector", func() {
+	It("should list the co-authors too", func() {
+		commits := []*commit.Commit{
+			{AuthorName: "John Doe", AuthorEmail: "john@example.com", CoAuthors: []commit.CoAuthor{{Name: "Jane Doe", Email: "jane@example.com"}}},
+			{AuthorName: "Jane Doe", AuthorEmail: "jane@example.com"},
+		}
+		emails := newEmailCollector()
+		for _, c := range commits {
+			emails.add(c)
+		}
+		Expect(emails.emails).To(Equal([]string{
+			"John Doe -> john@example.com",
+			"Jane Doe -> jane@example.com",
+		}))
+	})
+})
@@ -0,0 +1,249 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+var _ = Describe("GetCommits", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		repo.commit("main.go", "package main\n")
+		repo.git("checkout", "--quiet", "-b", "feature")
+		for i := 0; i < 5; i++ {
+			repo.commit("feature.go", "package main\n// "+strconv.Itoa(i)+"\n")
+		}
+		repo.git("checkout", "--quiet", "-")
+		repo.commit("README.md", "# Readme\n")
+		repo.git("merge", "--quiet", "--no-ff", "--no-edit", "feature")
+		repo.commit("src/{weird} name.py", "import os\n")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should get every commit exactly once", func() {
+		r := repo.extractor()
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+
+		expectedCount, err := strconv.Atoi(repo.git("rev-list", "--all", "--no-merges", "--count"))
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(expectedCount))
+
+		hashes := map[string]bool{}
+		for _, c := range commits {
+			hashes[c.Hash] = true
+		}
+		Expect(hashes).To(HaveLen(expectedCount))
+	})
+
+	It("should parse the changed files", func() {
+		r := repo.extractor()
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+
+		var pythonCommit *commit.Commit
+		for _, c := range commits {
+			for _, changedFile := range c.ChangedFiles {
+				if changedFile.Path == "src/{weird} name.py" {
+					pythonCommit = c
+				}
+			}
+		}
+		Expect(pythonCommit).NotTo(BeNil())
+		Expect(pythonCommit.AuthorEmail).To(Equal("developer@example.com"))
+		Expect(pythonCommit.Date).To(Equal("2021-03-18 10:00:00 +0100"))
+		Expect(pythonCommit.ChangedFiles).To(HaveLen(1))
+		Expect(pythonCommit.ChangedFiles[0].Insertions).To(Equal(1))
+	})
+
+	It("should parse the commit messages", func() {
+		repo.git("commit", "--quiet", "--allow-empty", "-m", "feat: add parser", "-m", "First line\n\nSecond paragraph |||SEP||| with separator")
+		repo.commit("parser.go", "package main\n")
+		r := repo.extractor()
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+
+		messages := map[string]bool{}
+		for _, c := range commits {
+			messages[c.Message] = true
+		}
+		Expect(messages).To(HaveKey("feat: add parser\n\nFirst line\n\nSecond paragraph |||SEP||| with separator"))
+		Expect(messages).To(HaveKey("Change parser.go"))
+		Expect(messages).To(HaveKey("Change src/{weird} name.py"))
+	})
+
+	It("should only get the commits of the selected refs", func() {
+		r := repo.extractor()
+		r.Refs = []string{"feature"}
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+
+		expectedCount, err := strconv.Atoi(repo.git("rev-list", "feature", "--no-merges", "--count"))
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(expectedCount))
+		for _, c := range commits {
+			Expect(c.Message).ToNot(Equal("Change README.md"))
+		}
+	})
+
+	It("should only get the most recent commits up to the maximum", func() {
+		r := repo.extractor()
+		r.Refs = []string{"HEAD"}
+		r.MaxCommits = 2
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(2))
+		Expect(commits[0].Message).To(Equal("Change src/{weird} name.py"))
+		Expect(r.getNumberOfCommits()).To(Equal(2))
+	})
+
+	It("should get the merge commits with their changes compared to the first parent", func() {
+		r := repo.extractor()
+		r.IncludeMerges = true
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+
+		expectedCount, err := strconv.Atoi(repo.git("rev-list", "--all", "--count"))
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(expectedCount))
+
+		var mergeCommit *commit.Commit
+		for _, c := range commits {
+			if strings.HasPrefix(c.Message, "Merge branch") {
+				mergeCommit = c
+			}
+		}
+		Expect(mergeCommit).NotTo(BeNil())
+		Expect(mergeCommit.ChangedFiles).To(HaveLen(1))
+		Expect(mergeCommit.ChangedFiles[0].Path).To(Equal("feature.go"))
+		Expect(mergeCommit.ChangedFiles[0].Insertions).To(Equal(2))
+	})
+})
+
+var _ = Describe("ParseCommitDate", func() {
+	It("should parse the date formats of git", func() {
+		expected := time.Date(2021, 3, 18, 10, 0, 0, 0, time.FixedZone("", 3600))
+		for _, value := range []string{
+			"2021-03-18T10:00:00+01:00",
+			"2021-03-18 10:00:00 +0100",
+			"Thu Mar 18 10:00:00 2021 +0100",
+			"Thu, 18 Mar 2021 10:00:00 +0100",
+		} {
+			date, err := parseCommitDate(value)
+			Expect(err).ToNot(HaveOccurred(), value)
+			Expect(date.Equal(expected)).To(BeTrue(), value)
+		}
+	})
+
+	It("should return an error for malformed dates", func() {
+		_, err := parseCommitDate("yesterday")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ParseCommits", func() {
+	It("should skip the commits with malformed dates", func() {
+		output := "|||BEGIN|||aaa|||SEP|||Developer|||SEP|||developer@example.com|||SEP|||2021-03-18T10:00:00+01:00|||SEP|||First\n" +
+			"|||BODY||||||END|||\n" +
+			"1\t0\tmain.go\n" +
+			"|||BEGIN|||bbb|||SEP|||Developer|||SEP|||developer@example.com|||SEP|||not a date|||SEP|||Second\n" +
+			"|||BODY||||||END|||\n" +
+			"2\t0\tmain.go\n"
+		r := &RepoExtractor{}
+
+		var commits []*commit.Commit
+		err := r.parseCommits(strings.NewReader(output), func(c *commit.Commit) {
+			commits = append(commits, c)
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Hash).To(Equal("aaa"))
+		Expect(commits[0].Date).To(Equal("2021-03-18 10:00:00 +0100"))
+	})
+})
+
+var _ = Describe("RevRange", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		repo.commit("main.go", "package main\n")
+		repo.git("tag", "v1.0")
+		repo.commit("server.go", "package main\n\nimport \"net/http\"\n")
+		repo.commit("client.go", "package main\n\nimport \"net/url\"\n")
+		repo.git("tag", "v2.0")
+		repo.commit("README.md", "# Readme\n")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should only get the commits of the range", func() {
+		r := repo.extractor()
+		r.RevRange = "v1.0..v2.0"
+
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(2))
+		Expect(commits[0].Message).To(Equal("Change client.go"))
+		Expect(commits[1].Message).To(Equal("Change server.go"))
+		Expect(r.getNumberOfCommits()).To(Equal(2))
+	})
+
+	It("should not be combined with branches or a state file", func() {
+		r := repo.extractor()
+		r.RevRange = "v1.0..v2.0"
+		r.Refs = []string{"master"}
+		Expect(r.validateOptions()).ToNot(Succeed())
+
+		r.Refs = nil
+		r.StateFile = filepath.Join(repo.path, "state.json")
+		Expect(r.validateOptions()).ToNot(Succeed())
+	})
+
+	It("should extract a bare clone", func() {
+		dir, err := ioutil.TempDir("", "extractor_bare_")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		barePath := filepath.Join(dir, "fixture.git")
+		repo.git("clone", "--quiet", "--bare", repo.path, barePath)
+		Expect(exec.Command("git", "--git-dir", barePath, "config", "--unset", "remote.origin.url").Run()).To(Succeed())
+
+		r := repo.extractor()
+		r.RepoPath = barePath
+		r.RevRange = "v1.0..v2.0"
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(dir, "export", "fixture")
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(r.Extract()).To(Succeed())
+		Expect(r.repo.RepoName).To(Equal("fixture"))
+
+		export, err := ioutil.ReadFile(r.exportFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(export)).To(ContainSubstring(`"net/http"`))
+		Expect(string(export)).To(ContainSubstring(`"net/url"`))
+	})
+})
@@ -0,0 +1,86 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/languagedetection"
+)
+
+// dryRunSummary tells what would be analysed by the extraction
+type dryRunSummary struct {
+	repoName            string
+	emails              []string
+	numberOfCommits     int
+	numberOfUserCommits int
+	// Number of the changed files of the commits of the selected emails per language
+	languages map[string]int
+}
+
+// dryRun lists the commits of the selected emails and the languages of their changed files,
+// without reading the content of the files and without writing the export or the state file
+// The languages are detected by the name and the extension of the files only, so the scripts without extension
+// are not counted and the ambiguous extensions count as their most common language.
+func (r *RepoExtractor) dryRun(ctx context.Context, w io.Writer) error {
+	summary := dryRunSummary{
+		repoName:        r.repo.RepoName,
+		emails:          r.repo.Emails,
+		numberOfCommits: r.getNumberOfCommits(),
+		languages:       map[string]int{},
+	}
+
+	languageAnalyzer := languagedetection.NewLanguageAnalyzer()
+	err := r.streamCommits(ctx, true, func(c *commit.Commit) {
+		if !isCommitOfEmails(c, r.selectedEmails) {
+			return
+		}
+		summary.numberOfUserCommits++
+		for _, changedFile := range c.ChangedFiles {
+			if r.isExcluded(changedFile.Path) || (!r.CountGeneratedFiles && r.isGeneratedPath(changedFile.Path)) {
+				continue
+			}
+			lang := languageAnalyzer.DetectLanguageFromFileName(filepath.Base(changedFile.Path))
+			if extension := languagedetection.FileExtension(changedFile.Path); lang == "" && extension != "" {
+				lang = languageAnalyzer.DetectLanguageFromExtension(extension)
+			}
+			if lang == "" || !isSelectedLanguage(lang, r.SkipLanguages, r.OnlyLanguages) {
+				continue
+			}
+			summary.languages[lang]++
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	writeDryRunSummary(w, summary)
+	return nil
+}
+
+// writeDryRunSummary writes the summary in human-readable lines, the languages ordered by the number of files
+func writeDryRunSummary(w io.Writer, summary dryRunSummary) {
+	fmt.Fprintf(w, "Repository: %s\n", summary.repoName)
+	fmt.Fprintf(w, "Selected emails: %s\n", strings.Join(summary.emails, ", "))
+	fmt.Fprintf(w, "Commits: %d, of the selected emails: %d\n", summary.numberOfCommits, summary.numberOfUserCommits)
+
+	languages := make([]string, 0, len(summary.languages))
+	for lang := range summary.languages {
+		languages = append(languages, lang)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if summary.languages[languages[i]] != summary.languages[languages[j]] {
+			return summary.languages[languages[i]] > summary.languages[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	fmt.Fprintln(w, "Languages (changed files):")
+	for _, lang := range languages {
+		fmt.Fprintf(w, "  %s: %d\n", lang, summary.languages[lang])
+	}
+}
@@ -0,0 +1,73 @@
+package extractor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+var _ = Describe("DryRun", func() {
+	var fixture *fixtureRepo
+
+	BeforeEach(func() {
+		fixture = newFixtureRepo()
+		fixture.commit("main.go", "package main\n")
+		fixture.commit("util.go", "package main\n")
+		fixture.commit("app/index.js", "console.log(1)\n")
+		fixture.git("-c", "user.email=colleague@example.com", "commit", "--quiet", "--allow-empty", "-m", "Colleague")
+	})
+
+	AfterEach(func() {
+		fixture.remove()
+	})
+
+	It("should list the commits of the selected emails and their languages", func() {
+		r := fixture.extractor()
+		r.repo = &repo{RepoName: "fixture", Emails: []string{"developer@example.com"}}
+		r.selectedEmails = map[string]bool{"developer@example.com": true}
+
+		var output bytes.Buffer
+		Expect(r.dryRun(context.Background(), &output)).To(Succeed())
+		Expect(output.String()).To(Equal("Repository: fixture\n" +
+			"Selected emails: developer@example.com\n" +
+			"Commits: 4, of the selected emails: 3\n" +
+			"Languages (changed files):\n" +
+			"  Go: 2\n" +
+			"  JavaScript: 1\n"))
+	})
+
+	It("should not write any file", func() {
+		outputDir, err := ioutil.TempDir("", "extractor_dry_run_")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(outputDir)
+
+		r := fixture.extractor()
+		r.DryRun = true
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = outputDir + "/export/fixture"
+		r.StateFile = outputDir + "/state.json"
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+
+		stdout := os.Stdout
+		reader, writer, err := os.Pipe()
+		Expect(err).ToNot(HaveOccurred())
+		os.Stdout = writer
+		err = r.Extract()
+		os.Stdout = stdout
+		writer.Close()
+		Expect(err).ToNot(HaveOccurred())
+
+		output, err := ioutil.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(output)).To(ContainSubstring("Commits: 4, of the selected emails: 3\n"))
+		files, err := ioutil.ReadDir(outputDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+})
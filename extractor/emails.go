@@ -0,0 +1,61 @@
+package extractor
+
+import (
+	"os"
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+// AuthorEmail is an email of the authors and co-authors of a repository with the number of its commits
+type AuthorEmail struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Commits int    `json:"commits"`
+}
+
+// ListEmails returns with the emails of the authors and co-authors, the most active ones first
+// Only the quick pass over the history is done, which collects the emails for the selection: the emails are not
+// selected, the changed files are not analysed and nothing is written. The emails can be passed to UserEmails later.
+func (r *RepoExtractor) ListEmails() ([]AuthorEmail, error) {
+	if r.Logger == nil {
+		r.Logger, _ = logger.NewLogger(logger.FormatText, os.Stderr, r.Quiet)
+	}
+
+	err := r.validateOptions()
+	if err != nil {
+		return nil, err
+	}
+	err = r.initRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	err = r.loadIdentityNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	emails, err := r.collectEmails(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return emails.authorEmails(), nil
+}
+
+// authorEmails returns with the collected emails sorted by the number of commits, descending
+func (e *emailCollector) authorEmails() []AuthorEmail {
+	authorEmails := make([]AuthorEmail, 0, len(e.names))
+	for email, name := range e.names {
+		authorEmails = append(authorEmails, AuthorEmail{Name: name, Email: email, Commits: e.commits[email]})
+	}
+	sort.Slice(authorEmails, func(i, j int) bool {
+		if authorEmails[i].Commits != authorEmails[j].Commits {
+			return authorEmails[i].Commits > authorEmails[j].Commits
+		}
+		return authorEmails[i].Email < authorEmails[j].Email
+	})
+	return authorEmails
+}
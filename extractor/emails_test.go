@@ -0,0 +1,142 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+var _ = Describe("AnalyseCommits", func() {
+	var fixture *fixtureRepo
+
+	BeforeEach(func() {
+		fixture = newFixtureRepo()
+		fixture.commit("main.go", "package main\n")
+		fixture.git("-c", "user.email=colleague@ourcompany.com", "commit", "--quiet", "--allow-empty", "-m", "Colleague")
+		fixture.git("-c", "user.email=contractor@example.org", "commit", "--quiet", "--allow-empty", "-m", "Contractor")
+	})
+
+	AfterEach(func() {
+		fixture.remove()
+	})
+
+	It("should select the emails matching the pattern besides the given emails", func() {
+		r := fixture.extractor()
+		r.repo = &repo{}
+		r.UserEmails = []string{"developer@example.com"}
+		r.EmailPattern = regexp.MustCompile(`@ourcompany\.com$`)
+
+		Expect(r.analyseCommits(context.Background())).To(Succeed())
+		Expect(r.selectedEmails).To(Equal(map[string]bool{
+			"developer@example.com":    true,
+			"colleague@ourcompany.com": true,
+		}))
+		Expect(r.repo.Emails).To(Equal([]string{"developer@example.com", "colleague@ourcompany.com"}))
+	})
+
+	It("should fail if git log fails", func() {
+		r := fixture.extractor()
+		r.repo = &repo{}
+		r.Refs = []string{"does-not-exist"}
+		r.EmailPattern = regexp.MustCompile(`@ourcompany\.com$`)
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+
+		Expect(r.analyseCommits(context.Background())).ToNot(Succeed())
+	})
+
+	It("should fail the extraction if git log fails", func() {
+		outputDir, err := ioutil.TempDir("", "extractor_git_failure_")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(outputDir)
+
+		r := fixture.extractor()
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(outputDir, "fixture")
+		r.Quiet = true
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(r.Extract()).To(Succeed())
+		previousExport, err := ioutil.ReadFile(r.exportFilePath())
+		Expect(err).ToNot(HaveOccurred())
+
+		// git log of the library pass fails, the other git commands succeed
+		failingGit := filepath.Join(outputDir, "failing-git")
+		script := "#!/bin/sh\ncase \"$*\" in *--numstat*) echo 'fatal: bad object' >&2; exit 128;; esac\nexec " + r.GitPath + " \"$@\"\n"
+		Expect(ioutil.WriteFile(failingGit, []byte(script), 0755)).To(Succeed())
+
+		r = fixture.extractor()
+		r.GitPath = failingGit
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(outputDir, "fixture")
+		r.StateFile = filepath.Join(outputDir, "state.json")
+		r.HashImportant = true
+		r.HashMapOut = filepath.Join(outputDir, "hashes.json")
+		r.Quiet = true
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(r.Extract()).ToNot(Succeed())
+
+		export, err := ioutil.ReadFile(r.exportFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(export).To(Equal(previousExport))
+		Expect(r.HashMapOut).ToNot(BeAnExistingFile())
+		Expect(r.StateFile).ToNot(BeAnExistingFile())
+		files, err := filepath.Glob(filepath.Join(outputDir, ".*.tmp"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GetEmailsMatching", func() {
+	It("should match the pattern against the email only", func() {
+		emails := []string{"ourcompany.com -> developer@example.com", "Colleague -> colleague@ourcompany.com"}
+		Expect(getEmailsMatching(emails, regexp.MustCompile(`ourcompany\.com`))).To(Equal([]string{"Colleague -> colleague@ourcompany.com"}))
+	})
+})
+
+var _ = Describe("ListEmails", func() {
+	var fixture *fixtureRepo
+
+	BeforeEach(func() {
+		fixture = newFixtureRepo()
+		fixture.commit("main.go", "package main\n")
+		fixture.git("-c", "user.name=Contractor", "-c", "user.email=contractor@example.org", "commit", "--quiet", "--allow-empty", "-m", "Contractor")
+		fixture.git("commit", "--quiet", "--allow-empty", "-m", "Pairing\n\nCo-authored-by: Colleague <colleague@ourcompany.com>\nCo-authored-by: Developer <developer@example.com>")
+	})
+
+	AfterEach(func() {
+		fixture.remove()
+	})
+
+	It("should list the emails with the number of their commits, the most active ones first", func() {
+		r := fixture.extractor()
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+
+		emails, err := r.ListEmails()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(emails).To(Equal([]AuthorEmail{
+			{Name: "Developer", Email: "developer@example.com", Commits: 2},
+			{Name: "Colleague", Email: "colleague@ourcompany.com", Commits: 1},
+			{Name: "Contractor", Email: "contractor@example.org", Commits: 1},
+		}))
+	})
+
+	It("should count the commits of the emails for the interactive selection", func() {
+		r := fixture.extractor()
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+
+		emails, commits, err := r.getAllEmails(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(emails).To(HaveLen(3))
+		Expect(commits).To(Equal(map[string]int{
+			"developer@example.com":    2,
+			"colleague@ourcompany.com": 1,
+			"contractor@example.org":   1,
+		}))
+	})
+})
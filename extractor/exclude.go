@@ -0,0 +1,70 @@
+package extractor
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultExcludePaths are the vendored and generated paths which are not analysed by default
+var DefaultExcludePaths = []string{
+	"node_modules/",
+	"bower_components/",
+	"vendor/",
+	"third_party/",
+	"*.min.js",
+	"*.min.css",
+}
+
+// isExcludedPath checks if the file path matches any of the glob patterns
+// Patterns without a slash, like "*.min.js" or "vendor/", match any file or directory name in the path.
+// Patterns with a slash, like "web/static/*", are matched from the root of the repository.
+func isExcludedPath(filePath string, patterns []string) bool {
+	segments := strings.Split(filePath, "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+
+		if strings.Contains(pattern, "/") {
+			for i := range segments {
+				if matched, _ := path.Match(pattern, strings.Join(segments[:i+1], "/")); matched {
+					return true
+				}
+			}
+			continue
+		}
+
+		for _, segment := range segments {
+			if matched, _ := path.Match(pattern, segment); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isExcluded checks if the file is excluded by ExcludePaths, or by the .gitignore files with RespectGitignore
+func (r *RepoExtractor) isExcluded(filePath string) bool {
+	return isExcludedPath(filePath, r.ExcludePaths) || r.gitignore.ignores(filePath)
+}
+
+// isSelectedLanguage checks if the files of the language are analysed
+// The languages are compared case-insensitively. If the allowed languages are given, files of unknown language are
+// not analysed either.
+func isSelectedLanguage(lang string, skipLanguages, onlyLanguages []string) bool {
+	for _, skipLanguage := range skipLanguages {
+		if strings.EqualFold(lang, skipLanguage) {
+			return false
+		}
+	}
+	if len(onlyLanguages) == 0 {
+		return true
+	}
+	for _, onlyLanguage := range onlyLanguages {
+		if strings.EqualFold(lang, onlyLanguage) {
+			return true
+		}
+	}
+	return false
+}
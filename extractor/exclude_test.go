@@ -0,0 +1,42 @@
+package extractor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsExcludedPath", func() {
+	It("should exclude the default vendored and generated paths", func() {
+		Expect(isExcludedPath("node_modules/react/index.js", DefaultExcludePaths)).To(BeTrue())
+		Expect(isExcludedPath("web/node_modules/react/index.js", DefaultExcludePaths)).To(BeTrue())
+		Expect(isExcludedPath("vendor/github.com/pkg/errors/errors.go", DefaultExcludePaths)).To(BeTrue())
+		Expect(isExcludedPath("static/js/app.min.js", DefaultExcludePaths)).To(BeTrue())
+		Expect(isExcludedPath("src/vendors.go", DefaultExcludePaths)).To(BeFalse())
+		Expect(isExcludedPath("static/js/app.js", DefaultExcludePaths)).To(BeFalse())
+	})
+
+	It("should match patterns with directories from the root", func() {
+		patterns := []string{"web/static/*"}
+		Expect(isExcludedPath("web/static/app.js", patterns)).To(BeTrue())
+		Expect(isExcludedPath("web/static/js/app.js", patterns)).To(BeTrue())
+		Expect(isExcludedPath("other/web/static/app.js", patterns)).To(BeFalse())
+	})
+})
+
+var _ = Describe("IsSelectedLanguage", func() {
+	It("should select every language by default", func() {
+		Expect(isSelectedLanguage("Go", nil, nil)).To(BeTrue())
+		Expect(isSelectedLanguage("", nil, nil)).To(BeTrue())
+	})
+
+	It("should not select the skipped languages", func() {
+		Expect(isSelectedLanguage("SQL", []string{"sql", "JSON"}, nil)).To(BeFalse())
+		Expect(isSelectedLanguage("Go", []string{"sql", "JSON"}, nil)).To(BeTrue())
+	})
+
+	It("should only select the allowed languages", func() {
+		Expect(isSelectedLanguage("Go", nil, []string{"Go"})).To(BeTrue())
+		Expect(isSelectedLanguage("Python", nil, []string{"Go"})).To(BeFalse())
+		Expect(isSelectedLanguage("", nil, []string{"Go"})).To(BeFalse())
+	})
+})
@@ -0,0 +1,514 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/logger"
+	"github.com/Techloopio/extractor_tool/obfuscation"
+	"github.com/Techloopio/extractor_tool/ui"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetRawCommitForExport", func() {
+	It("should keep the hash, the author and the exact date of the commit", func() {
+		r := &RepoExtractor{}
+		rawCommit := r.getRawCommitForExport(commit.Commit{
+			Hash:        "abc",
+			AuthorName:  "Developer",
+			AuthorEmail: "developer@example.com",
+			Date:        "2021-03-18 23:30:00 +0100",
+			ChangedFiles: []*commit.ChangedFile{
+				{Path: "main.go", Insertions: 3, Deletions: 1, Language: "Go"},
+				{Path: "util.go", Insertions: 2, Deletions: 0, Language: "Go"},
+				{Path: "README.md", Insertions: 1, Deletions: 1},
+			},
+			Libraries: map[string][]string{"Go": {"errors", "errors"}},
+		})
+
+		Expect(rawCommit).To(Equal(commit.RawCommitForExport{
+			Hash:         "abc",
+			AuthorName:   "Developer",
+			AuthorEmail:  "developer@example.com",
+			Date:         "2021-03-18 22:30:00 +0000 UTC",
+			Languages:    []string{"Go"},
+			Insertions:   6,
+			Deletions:    2,
+			FilesChanged: 3,
+			LanguageLines: map[string]commit.LanguageLines{
+				"Go": {Insertions: 5, Deletions: 1},
+			},
+			Libraries: map[string][]string{"Go": {"errors"}},
+		}))
+	})
+})
+
+var _ = Describe("WriteJSONHeader", func() {
+	export := func(r *RepoExtractor) []byte {
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		r.writeJSONHeader(w)
+		fmt.Fprintln(w, `{"commits":1}`)
+		r.writeJSONFooter(w, exportSummary{})
+		w.Flush()
+		return buffer.Bytes()
+	}
+
+	It("should wrap the commits in an envelope", func() {
+		r := &RepoExtractor{ToolVersion: "v1.2.3", repo: &repo{RepoName: "extractor_tool"}}
+
+		var envelope struct {
+			SchemaVersion int                      `json:"schemaVersion"`
+			ToolVersion   string                   `json:"toolVersion"`
+			Repo          string                   `json:"repo"`
+			Commits       []map[string]interface{} `json:"commits"`
+		}
+		Expect(json.Unmarshal(export(r), &envelope)).To(Succeed())
+		Expect(envelope.SchemaVersion).To(Equal(SchemaVersion))
+		Expect(envelope.ToolVersion).To(Equal("v1.2.3"))
+		Expect(envelope.Repo).To(Equal("extractor_tool"))
+		Expect(envelope.Commits).To(HaveLen(1))
+	})
+
+	It("should write a bare array in the legacy format", func() {
+		r := &RepoExtractor{ToolVersion: "v1.2.3", LegacyFormat: true}
+
+		var commits []map[string]interface{}
+		Expect(json.Unmarshal(export(r), &commits)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ExportPretty", func() {
+	export := func(r *RepoExtractor) string {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		r.Pretty = true
+		r.obfuscator = obfuscator
+		r.repo = &repo{RepoName: "extractor_tool"}
+		r.commitPipeline = make(chan commit.Commit)
+		r.libraryExtractionCompleted = make(chan bool)
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000"}
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-19 10:00:00 +0000"}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		Expect(r.writeExport(w, nil, nil)).To(Succeed())
+		w.Flush()
+		return buffer.String()
+	}
+
+	It("should indent the envelope and the commits", func() {
+		output := export(&RepoExtractor{})
+		Expect(output).To(HavePrefix("{\n  \"schemaVersion\": 1,\n"))
+		Expect(output).To(ContainSubstring("\n  \"commits\": [\n    {\n      \"authorEmails\": [\n"))
+		Expect(output).To(ContainSubstring("\n    },\n    {\n"))
+		Expect(output).To(HaveSuffix("\n    }\n  ]\n}\n"))
+
+		var envelope struct {
+			Commits []commit.OptimizedCommitForExport `json:"commits"`
+		}
+		Expect(json.Unmarshal([]byte(output), &envelope)).To(Succeed())
+		Expect(envelope.Commits).To(HaveLen(2))
+	})
+
+	It("should write valid JSON in the legacy and raw formats", func() {
+		var commits []commit.OptimizedCommitForExport
+		Expect(json.Unmarshal([]byte(export(&RepoExtractor{LegacyFormat: true})), &commits)).To(Succeed())
+		Expect(commits).To(HaveLen(2))
+
+		var envelope struct {
+			Commits []commit.RawCommitForExport `json:"commits"`
+		}
+		Expect(json.Unmarshal([]byte(export(&RepoExtractor{Raw: true})), &envelope)).To(Succeed())
+		Expect(envelope.Commits).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("ExportJSON", func() {
+	It("should obfuscate the emails of every commit merged into the same day", func() {
+		obfuscator, err := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "salt")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			ObfuscateEmails:            true,
+			LegacyFormat:               true,
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "first@example.com", Date: "2021-03-18 10:00:00 +0000"}
+			r.commitPipeline <- commit.Commit{AuthorEmail: "second@example.com", Date: "2021-03-18 12:00:00 +0000"}
+			r.commitPipeline <- commit.Commit{AuthorEmail: "first@example.com", Date: "2021-03-18 14:00:00 +0000"}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		r.exportJSON(w, nil)
+		w.Flush()
+
+		var commits []commit.OptimizedCommitForExport
+		Expect(json.Unmarshal(buffer.Bytes(), &commits)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Commits).To(Equal(3))
+		Expect(commits[0].AuthorEmails).To(Equal([]string{
+			obfuscator.Hash("first@example.com"),
+			obfuscator.Hash("second@example.com"),
+		}))
+	})
+
+	It("should only hash the libraries with HashLibraries", func() {
+		obfuscator, err := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "salt")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			HashLibraries:              true,
+			LegacyFormat:               true,
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000",
+				ChangedFiles: []*commit.ChangedFile{{Path: "main.go", Insertions: 3, Language: "Go"}},
+				Libraries:    map[string][]string{"Go": {"fmt", "github.com/acme/billing"}}}
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 12:00:00 +0000",
+				ChangedFiles: []*commit.ChangedFile{{Path: "server.go", Insertions: 2, Language: "Go"}},
+				Libraries:    map[string][]string{"Go": {"github.com/acme/billing"}}}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		r.exportJSON(w, nil)
+		w.Flush()
+
+		var commits []commit.OptimizedCommitForExport
+		Expect(json.Unmarshal(buffer.Bytes(), &commits)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"Go": {
+			obfuscator.Hash("fmt"),
+			obfuscator.Hash("github.com/acme/billing"),
+		}}))
+		Expect(commits[0].AuthorEmails).To(Equal([]string{"developer@example.com"}))
+		Expect(commits[0].Languages).To(Equal([]string{"Go"}))
+		Expect(commits[0].Insertions).To(Equal(5))
+	})
+
+	It("should sum the changed files and lines of every commit merged into the same day", func() {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			LegacyFormat:               true,
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000", ChangedFiles: []*commit.ChangedFile{
+				{Path: "main.go", Insertions: 3, Language: "Go"},
+				{Path: "logo.png", Binary: true},
+			}}
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 12:00:00 +0000", ChangedFiles: []*commit.ChangedFile{
+				{Path: "cmd/main.go", Insertions: 2, Deletions: 1, Language: "Go"},
+				{Path: "app.js", Insertions: 4, Language: "JavaScript"},
+			}}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		r.exportJSON(w, nil)
+		w.Flush()
+
+		var commits []commit.OptimizedCommitForExport
+		Expect(json.Unmarshal(buffer.Bytes(), &commits)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].FilesChanged).To(Equal(4))
+		Expect(commits[0].BinaryFiles).To(Equal(1))
+		Expect(commits[0].Insertions).To(Equal(9))
+		Expect(commits[0].LanguageLines).To(Equal(map[string]commit.LanguageLines{
+			"Go":         {Insertions: 5, Deletions: 1},
+			"JavaScript": {Insertions: 4, Deletions: 0},
+		}))
+	})
+
+	It("should sum the library counts of every commit merged into the same day", func() {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			IncludeLibraryCounts:       true,
+			LegacyFormat:               true,
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000",
+				Libraries: map[string][]string{"Go": {"fmt", "github.com/acme/billing", "fmt"}}}
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 12:00:00 +0000",
+				Libraries: map[string][]string{"Go": {"fmt"}, "Python": {"requests"}}}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		r.exportJSON(w, nil)
+		w.Flush()
+
+		var commits []commit.OptimizedCommitForExport
+		Expect(json.Unmarshal(buffer.Bytes(), &commits)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries["Go"]).To(ConsistOf("fmt", "github.com/acme/billing"))
+		Expect(commits[0].LibraryCounts).To(Equal(map[string]map[string]int{
+			"Go":     {"fmt": 3, "github.com/acme/billing": 1},
+			"Python": {"requests": 1},
+		}))
+	})
+
+	It("should mark the export as partial after the time limit", func() {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		log, err := logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			obfuscator:                 obfuscator,
+			repo:                       &repo{RepoName: "extractor_tool"},
+			Logger:                     log,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+			partial:                    &partialExport{ProcessedCommits: 1, TotalCommits: 3},
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000"}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		r.exportJSON(w, nil)
+		w.Flush()
+
+		var envelope struct {
+			Commits []commit.OptimizedCommitForExport `json:"commits"`
+			Partial *partialExport                    `json:"partial"`
+		}
+		Expect(json.Unmarshal(buffer.Bytes(), &envelope)).To(Succeed())
+		Expect(envelope.Commits).To(HaveLen(1))
+		Expect(envelope.Partial).To(Equal(&partialExport{ProcessedCommits: 1, TotalCommits: 3}))
+	})
+})
+
+var _ = Describe("ExportToStdout", func() {
+	It("should write only the export to the standard output", func() {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			OutputPath:                 StdoutOutputPath,
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000"}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		stdout := os.Stdout
+		reader, writer, err := os.Pipe()
+		Expect(err).ToNot(HaveOccurred())
+		os.Stdout = writer
+		err = r.export()
+		os.Stdout = stdout
+		writer.Close()
+		Expect(err).ToNot(HaveOccurred())
+
+		output, err := ioutil.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		var envelope struct {
+			Commits []commit.OptimizedCommitForExport `json:"commits"`
+		}
+		Expect(json.Unmarshal(output, &envelope)).To(Succeed())
+		Expect(envelope.Commits).To(HaveLen(1))
+		Expect(envelope.Commits[0].AuthorEmails).To(Equal([]string{"developer@example.com"}))
+	})
+})
+
+var _ = Describe("WriteFileAtomically", func() {
+	var dir, path string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "extractor_atomic_")
+		Expect(err).ToNot(HaveOccurred())
+		path = dir + "/repo_techloop.json"
+		Expect(ioutil.WriteFile(path, []byte("previous\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(content string, err error) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			fmt.Fprint(w, content)
+			return err
+		}
+	}
+
+	It("should replace the previous file", func() {
+		Expect(writeFileAtomically(path, 0644, false, write("new\n", nil))).To(Succeed())
+
+		content, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("new\n"))
+		info, err := os.Stat(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+	})
+
+	It("should keep the previous file intact if the write fails", func() {
+		Expect(writeFileAtomically(path, 0644, false, write("trunc", errors.New("disk full")))).ToNot(Succeed())
+
+		content, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("previous\n"))
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("should append to the previous file", func() {
+		Expect(writeFileAtomically(path, 0644, true, write("new\n", nil))).To(Succeed())
+		Expect(writeFileAtomically(dir+"/other.ndjson", 0644, true, write("first\n", nil))).To(Succeed())
+
+		content, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("previous\nnew\n"))
+		content, err = ioutil.ReadFile(dir + "/other.ndjson")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("first\n"))
+	})
+})
+
+var _ = Describe("ExportCompressed", func() {
+	var outputDir string
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("", "extractor_export_")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	export := func(outputFormat string) []byte {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			OutputPath:                 outputDir + "/repo",
+			OutputFormat:               outputFormat,
+			Compress:                   true,
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{AuthorEmail: "developer@example.com", Date: "2021-03-18 10:00:00 +0000"}
+			r.libraryExtractionCompleted <- true
+		}()
+		Expect(r.export()).To(Succeed())
+
+		path := outputDir + "/repo_techloop.json.gz"
+		if outputFormat == OutputFormatNDJSON {
+			path = outputDir + "/repo_techloop.ndjson.gz"
+		}
+		file, err := os.Open(path)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+		reader, err := gzip.NewReader(file)
+		Expect(err).ToNot(HaveOccurred())
+		content, err := ioutil.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		return content
+	}
+
+	It("should write the whole JSON export compressed", func() {
+		var envelope struct {
+			Commits []commit.OptimizedCommitForExport `json:"commits"`
+		}
+		Expect(json.Unmarshal(export(OutputFormatJSON), &envelope)).To(Succeed())
+		Expect(envelope.Commits).To(HaveLen(1))
+		Expect(envelope.Commits[0].AuthorEmails).To(Equal([]string{"developer@example.com"}))
+	})
+
+	It("should write the whole NDJSON export compressed", func() {
+		lines := bytes.Split(bytes.TrimSpace(export(OutputFormatNDJSON)), []byte("\n"))
+		Expect(lines).To(HaveLen(1))
+		var record map[string]interface{}
+		Expect(json.Unmarshal(lines[0], &record)).To(Succeed())
+	})
+
+	It("should read the previous compressed export", func() {
+		export(OutputFormatJSON)
+		var commits []commit.OptimizedCommitForExport
+		Expect(readPreviousExport(outputDir+"/repo_techloop.json.gz", &commits, nil)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("FailedExport", func() {
+	It("should stop the library analysis if the export cannot be written", func() {
+		repo := newFixtureRepo()
+		defer repo.remove()
+		for i := 0; i < 5; i++ {
+			repo.commit(fmt.Sprintf("main%d.go", i), "package main\n\nimport \"fmt\"\n")
+		}
+		dir, err := ioutil.TempDir("", "extractor_failed_export_")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		// The directory of the export cannot be created under a file
+		Expect(ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644)).To(Succeed())
+
+		goroutines := runtime.NumGoroutine()
+		r := repo.extractor()
+		r.Quiet = true
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(dir, "file", "export", "fixture")
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(r.Extract()).ToNot(Succeed())
+
+		// The workers, their git processes and the goroutines waiting for them are all gone
+		Eventually(runtime.NumGoroutine, "5s").Should(BeNumerically("<=", goroutines))
+	})
+})
+
+var _ = Describe("NewProgressTracker", func() {
+	It("should not show the progress bar in quiet mode", func() {
+		r := &RepoExtractor{Quiet: true}
+		Expect(r.newProgressTracker()).To(Equal(ui.NilProgressTracker()))
+	})
+
+	It("should not show the progress bar with JSON logs", func() {
+		jsonLogger, err := logger.NewLogger(logger.FormatJSON, ioutil.Discard, false)
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{Logger: jsonLogger}
+		Expect(r.newProgressTracker()).To(Equal(ui.NilProgressTracker()))
+	})
+})
@@ -2,31 +2,66 @@ package extractor
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/context"
-	"golang.org/x/text/language"
-	"golang.org/x/text/search"
 
 	"github.com/Techloopio/extractor_tool/commit"
 	"github.com/Techloopio/extractor_tool/languagedetection"
 	"github.com/Techloopio/extractor_tool/librarydetection"
 	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+	"github.com/Techloopio/extractor_tool/logger"
 	"github.com/Techloopio/extractor_tool/obfuscation"
+	"github.com/Techloopio/extractor_tool/state"
 	"github.com/Techloopio/extractor_tool/ui"
 )
 
+// StdoutOutputPath as output path writes the export to the standard output
+// The messages are written to the standard error in this case, so the standard output only contains the export.
+const StdoutOutputPath = "-"
+
+// SchemaVersion is the version of the exported JSON envelope. It has to be increased on breaking changes of the export.
+const SchemaVersion = 1
+
+const (
+	// OutputFormatJSON writes a single JSON array with the commits aggregated per day
+	OutputFormatJSON = "json"
+	// OutputFormatNDJSON writes one JSON object per line for every commit.
+	// The commits are not aggregated per day, so the output is streamed without holding it in memory.
+	OutputFormatNDJSON = "ndjson"
+)
+
+const (
+	// AggregationDay aggregates the commits per day
+	AggregationDay = "day"
+	// AggregationWeek aggregates the commits per week, weeks start on Monday
+	AggregationWeek = "week"
+	// AggregationMonth aggregates the commits per month
+	AggregationMonth = "month"
+	// AggregationNone doesn't aggregate the commits, every commit is exported as a separate record
+	AggregationNone = "none"
+)
+
+// gitFatalExitCode is the exit code of git in case of fatal errors, e.g. a path does not exist in a commit
+const gitFatalExitCode = 128
+
 // RepoExtractor is responsible for all parts of repo extraction process
 // Including cloning the repo, processing the commits and uploading the results
 type RepoExtractor struct {
@@ -38,10 +73,75 @@ type RepoExtractor struct {
 	UserEmails                 []string
 	TimeLimit                  time.Duration // If set the extraction will be stopped after the given time limit and the partial result will be uploaded
 	Seed                       []string
+	OutputFormat               string         // Either OutputFormatJSON (default) or OutputFormatNDJSON
+	Since                      time.Time      // If set only the commits after this date are analysed
+	Until                      time.Time      // If set only the commits before this date are analysed
+	Aggregation                string         // One of AggregationDay (default), AggregationWeek, AggregationMonth or AggregationNone
+	UseAuthorTimezone          bool           // If it is true the commits are aggregated by the calendar day of the author instead of UTC
+	Workers                    int            // Number of workers analysing the commits. Defaults to the number of CPUs.
+	ExcludePaths               []string       // Glob patterns of the files which are not analysed, like "vendor/" or "*.min.js"
+	RespectGitignore           bool           // The committed files matching the .gitignore files of HEAD are not analysed either
+	GeneratedPaths             []string       // Glob patterns of the generated files, like "*.pb.go". Defaults to DefaultGeneratedPaths if nil.
+	CountGeneratedFiles        bool           // The generated files count in the stats, only their libraries are not extracted
+	Raw                        bool           // Export every commit as a separate record with its hash and author, without aggregation
+	ToolVersion                string         // Version of the tool, written to the export
+	LegacyFormat               bool           // Export a bare JSON array without the envelope containing the schema and tool versions
+	Pretty                     bool           // Indent the JSON export. The ndjson export is always compact, one record per line.
+	Quiet                      bool           // If it is true the progress bars and the informational messages are not shown
+	Compress                   bool           // Compress the export with gzip. ".gz" is appended to the name of the export file.
+	UploadURL                  string         // If set the export file is POSTed to this URL. The local file is kept.
+	UploadToken                string         // Bearer token sent with the upload
+	UploadTimeout              time.Duration  // Timeout of a single upload attempt. Defaults to 30 seconds.
+	UploadRetries              int            // Number of retries of a failed upload
+	Uploaders                  []Uploader     // The export file is uploaded to these storages too, like an S3 bucket
+	GoOS                       string         // If set the imports of the Go files built for other operating systems are not extracted
+	GoArch                     string         // If set the imports of the Go files built for other architectures are not extracted
+	GoBuildTags                []string       // Custom build tags of the target, the imports of the Go files requiring other tags are not extracted
+	ExcludeGoStandardLibrary   bool           // If it is true the imports of the Go standard library, like "fmt", are not extracted
+	ExcludeTypeScriptTypes     bool           // If it is true the type-only imports of the TypeScript files, like import type { Foo } from 'bar', are not extracted
+	PythonGranularity          string         // Either languages.PythonGranularityPackage (default), like "django", or languages.PythonGranularityModule, like "django.db"
+	ExcludePythonStdlib        bool           // If it is true the imports of the Python standard library, like "os", are not extracted
+	PythonVersion              int            // Major version of Python whose standard library is excluded, 2 or 3. 0 excludes the modules of both versions.
+	ShellCommands              []string       // Command line tools detected as libraries of the shell scripts. Defaults to languages.DefaultShellCommands if nil.
+	PerlPragmas                []string       // Perl modules which are not reported as libraries. Defaults to languages.DefaultPerlPragmas if nil.
+	MaxFileSize                int64          // The content of larger files (in bytes) is not read, they only count in the stats. 0 means no limit.
+	SkipLanguages              []string       // The files of these languages are not analysed and don't count in the stats
+	OnlyLanguages              []string       // If set only the files of these languages are analysed and count in the stats
+	MinLanguageConfidence      float64        // Libraries are not extracted from the files whose language is detected with lower confidence, between 0 and 1
+	EmailPattern               *regexp.Regexp // If set the emails matching the pattern are selected besides UserEmails, without asking the user
+	NormalizeGitHubEmails      bool           // Replace the GitHub noreply emails like "1234+octocat@users.noreply.github.com" with "octocat@users.noreply.github.com"
+	Identities                 []string       // Rules mapping the emails of the same person to one email, like "me@home.com=me@work.com" or "*@users.noreply.github.com" to map by name
+	Logger                     *logger.Logger // Writes the events of the extraction. Defaults to a text logger writing to the standard error.
+	HashAlgorithm              string         // Algorithm used by HashImportant: md5 (default), sha1 or sha256
+	HashSalt                   string         // Salt prepended to the hashed values. The same salt gives the same hashes across runs.
+	ObfuscateEmails            bool           // Hash the author emails in the export. HashImportant hashes them too.
+	HashLibraries              bool           // Hash the library names in the export, the languages, the dates and the line counts stay readable
+	HashMapOut                 string         // If set the hashed values are written with their hashes to this file, which has to be kept secret
+	IncludeMessages            bool           // Export the commit messages
+	IncludeLibraryCounts       bool           // Export the number of the changed files using the libraries, besides the libraries
+	IncludeActivity            bool           // Export the number of active days and the longest streak of every author in the envelope
+	Refs                       []string       // Branches or other refs to analyse. All refs are analysed if empty.
+	RevRange                   string         // If set only the commits of the range are analysed, like "v1.0..v2.0". It cannot be combined with Refs or StateFile.
+	IncludeMerges              bool           // Analyse the merge commits too, with their changes compared to the first parent
+	MaxCommits                 int            // If set only the most recent commits are analysed, e.g. for a quick preview of a huge history. 0 means no limit.
+	StateFile                  string         // Path of the state file. If set, only the commits added since the previous extraction are analysed and merged into the existing export.
+	ForceRefresh               bool           // Ignore the state file and analyse every commit
+	DryRun                     bool           // Only write the selected emails, the number of commits and their languages to the standard output, without analysing the libraries and writing the export
 	repo                       *repo
-	userCommits                []*commit.Commit // Commits which are belong to user (from selected emails)
+	selectedEmails             map[string]bool // Only the commits of these emails are analysed
+	commitsErr                 error           // Error of git log during the analysis of the libraries
 	commitPipeline             chan commit.Commit
+	blobCache                  *blobCache // Libraries of the already analysed blobs
+	obfuscator                 *obfuscation.Obfuscator
+	identities                 *identities // Canonical emails of the authors, parsed from Identities
+	previousState              *state.State
+	previousSummary            exportSummary      // Summary of the previous export in case of incremental extraction
+	gitignore                  *gitignore         // Rules of the .gitignore files, if RespectGitignore is set
+	currentRefs                []string           // Commit hashes of the refs analysed when a state file is used
+	progress                   ui.ProgressTracker // Progress of the passes over the commits
 	libraryExtractionCompleted chan bool
+	droppedCommits             int32          // Commits of the user not analysed before the time limit, updated atomically
+	partial                    *partialExport // Set if the time limit was exceeded before every commit was processed
 }
 
 // Extract a single repo in the path
@@ -49,40 +149,190 @@ func (r *RepoExtractor) Extract() error {
 	var ctx context.Context
 	var cancel context.CancelFunc
 
+	// The extraction is also cancelled if the export fails, so the library workers don't wait for it forever
 	if r.TimeLimit.Seconds() != 0.0 {
 		ctx, cancel = context.WithTimeout(context.Background(), r.TimeLimit)
-		defer cancel()
 	} else {
-		ctx = context.Background()
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+
+	if r.Logger == nil {
+		r.Logger, _ = logger.NewLogger(logger.FormatText, os.Stderr, r.Quiet)
+	}
+
+	err := r.validateOptions()
+	if err != nil {
+		return err
+	}
+
+	err = r.initRepo()
+	if err != nil {
+		r.Logger.Error("Cannot init extractor_tool", logger.Fields{"error": err.Error()})
+		return err
+	}
+
+	err = r.loadState()
+	if err != nil {
+		return err
+	}
+
+	if r.RespectGitignore {
+		r.gitignore = r.loadGitignore()
 	}
 
-	err := r.initRepo()
+	err = r.loadIdentityNames(ctx)
 	if err != nil {
-		fmt.Println("Cannot init extractor_tool. Error: ", err.Error())
+		r.Logger.Error("Couldn't map the identities by name", logger.Fields{"error": err.Error()})
 		return err
 	}
 
 	// For library detection
 	r.initAnalyzers()
 
+	r.progress = r.newProgressTracker()
 	err = r.analyseCommits(ctx)
 	if err != nil {
 		return err
 	}
+	if r.DryRun {
+		return r.dryRun(ctx, os.Stdout)
+	}
 	go r.analyseLibraries(ctx)
 
 	err = r.export()
 	if err != nil {
-		fmt.Println("Couldn't export commits to export. Error:", err.Error())
+		r.stopLibraryAnalysis(cancel)
+	}
+	if r.commitsErr != nil {
+		// The previous export, the hash map and the state file are kept, the error is already logged
+		return r.commitsErr
+	}
+	if err != nil {
+		r.Logger.Error("Couldn't export commits to export", logger.Fields{"error": err.Error()})
+		return err
+	}
+	err = r.writeHashMap()
+	if err != nil {
+		r.Logger.Error("Couldn't write the hash map", logger.Fields{"error": err.Error(), "path": r.HashMapOut})
+		return err
+	}
+	// The next extraction would skip the commits which weren't analysed before the time limit
+	if r.partial != nil {
+		if r.StateFile != "" {
+			r.Logger.Warning("The state file is not updated, because the time limit was exceeded", nil)
+		}
+	} else if r.MaxCommits > 0 && r.StateFile != "" {
+		// The older commits of the sample would never be analysed by the next extraction either
+		r.Logger.Warning("The state file is not updated, because only the most recent commits were analysed", logger.Fields{"maxCommits": r.MaxCommits})
+	} else {
+		err = r.saveState()
+		if err != nil {
+			r.Logger.Error("Couldn't save the state file", logger.Fields{"error": err.Error()})
+			return err
+		}
+	}
+
+	// The partial result is uploaded too if the time limit was exceeded
+	err = r.upload()
+	if err != nil {
+		r.Logger.Error("Couldn't upload the export, it is kept locally", logger.Fields{"error": err.Error(), "path": r.exportFilePath()})
+		return err
+	}
+
+	return nil
+}
+
+// validateOptions checks the options which can be set by the user
+// The errors wrap ErrInvalidOption, so they can be told from the failures of the extraction.
+func (r *RepoExtractor) validateOptions() error {
+	if err := r.checkOptions(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidOption, err.Error())
+	}
+	return nil
+}
+
+func (r *RepoExtractor) checkOptions() error {
+	switch r.OutputFormat {
+	case "", OutputFormatJSON, OutputFormatNDJSON:
+	default:
+		return fmt.Errorf("unknown output format: %s", r.OutputFormat)
+	}
+
+	if r.IncludeActivity && (r.LegacyFormat || r.OutputFormat == OutputFormatNDJSON) {
+		return errors.New("the activity of the authors is written to the envelope of the json export, it cannot be used with the legacy or the ndjson format")
+	}
+	if r.IncludeActivity && !r.Raw && (r.Aggregation == AggregationWeek || r.Aggregation == AggregationMonth) {
+		return errors.New("the activity of the authors needs the days of the commits, it cannot be used with weekly or monthly granularity")
+	}
+
+	if (r.UploadURL != "" || len(r.Uploaders) > 0) && r.OutputPath == StdoutOutputPath {
+		return errors.New("the export written to the standard output cannot be uploaded")
+	}
+
+	if r.MaxCommits < 0 {
+		return fmt.Errorf("the maximum number of commits cannot be negative, got: %d", r.MaxCommits)
+	}
+
+	if r.RevRange != "" && len(r.Refs) > 0 {
+		return errors.New("the revision range already selects the commits, it cannot be used with branches")
+	}
+	// The state file saves the refs of the analysed commits, a range has no refs to continue from
+	if r.RevRange != "" && r.StateFile != "" {
+		return errors.New("the revision range cannot be used with a state file")
+	}
+
+	if r.MinLanguageConfidence < 0 || r.MinLanguageConfidence > 1 {
+		return fmt.Errorf("the minimum language confidence must be between 0 and 1, got: %v", r.MinLanguageConfidence)
+	}
+
+	switch r.Aggregation {
+	case "", AggregationDay, AggregationWeek, AggregationMonth, AggregationNone:
+	default:
+		return fmt.Errorf("unknown aggregation: %s", r.Aggregation)
+	}
+
+	switch r.PythonGranularity {
+	case "", languages.PythonGranularityPackage, languages.PythonGranularityModule:
+	default:
+		return fmt.Errorf("unknown granularity of the Python libraries: %s", r.PythonGranularity)
+	}
+	if r.PythonVersion != 0 && r.PythonVersion != 2 && r.PythonVersion != 3 {
+		return fmt.Errorf("the major version of Python must be 2 or 3, got: %d", r.PythonVersion)
+	}
+
+	obfuscator, err := obfuscation.NewObfuscator(r.HashAlgorithm, r.HashSalt)
+	if err != nil {
+		return err
+	}
+	r.obfuscator = obfuscator
+
+	r.identities, err = parseIdentities(r.Identities, r.NormalizeGitHubEmails)
+	if err != nil {
 		return err
 	}
 
+	if r.HashMapOut != "" {
+		if !r.isHashing() {
+			return errors.New("the hash map needs hashing, like --hash_important, --obfuscate_emails or --hash_libraries")
+		}
+		r.obfuscator.RecordHashes()
+	}
+
 	return nil
 }
 
-// Creates Repo struct
+// Creates Repo struct, after checking that git can be run and the path is a repository
 func (r *RepoExtractor) initRepo() error {
-	fmt.Println("Initializing repository")
+	r.Logger.Info("Initializing repository", logger.Fields{"path": r.RepoPath})
+
+	// Fail fast with a clear error, instead of a failing git command in the middle of the analysis
+	if err := r.checkGit(); err != nil {
+		return err
+	}
+	if err := r.checkRepository(); err != nil {
+		return err
+	}
 
 	r.commitPipeline = make(chan commit.Commit)
 	r.libraryExtractionCompleted = make(chan bool)
@@ -95,7 +345,7 @@ func (r *RepoExtractor) initRepo() error {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		fmt.Println("Cannot get remote.origin.url. Use directory path to get repo name.")
+		r.Logger.Warning("Cannot get remote.origin.url. Use directory path to get repo name", nil)
 	}
 
 	repoName := ""
@@ -120,7 +370,8 @@ func (r *RepoExtractor) GetRepoName(remoteOrigin string) string {
 	// If remoteOrigin is empty fall back to the repos path. It can happen in interactive mode
 	if remoteOrigin == "" {
 		parts := strings.Split(r.RepoPath, "/")
-		return parts[len(parts)-1]
+		// The directories of the bare clones are usually named like "repo.git"
+		return strings.TrimSuffix(parts[len(parts)-1], ".git")
 	}
 	repoName := ""
 	remoteOrigin = strings.TrimSuffix(remoteOrigin, ".git")
@@ -139,138 +390,232 @@ func (r *RepoExtractor) GetRepoName(remoteOrigin string) string {
 	return repoName
 }
 
+// BuildLibraries is the key of the libraries of the build files, like the Makefiles and the CMakeLists.txt files
+const BuildLibraries = "Build"
+
+// buildLanguages are the languages of the build files, their libraries are reported together under BuildLibraries
+var buildLanguages = map[string]bool{"CMake": true, "Makefile": true}
+
 func (r *RepoExtractor) initAnalyzers() {
-	librarydetection.AddAnalyzer("Go", languages.NewGoAnalyzer())
+	librarydetection.AddAnalyzer("Go", languages.NewGoAnalyzerWithOptions(languages.GoOptions{
+		GOOS:                   r.GoOS,
+		GOARCH:                 r.GoArch,
+		BuildTags:              r.GoBuildTags,
+		ExcludeStandardLibrary: r.ExcludeGoStandardLibrary,
+	}))
 	librarydetection.AddAnalyzer("C", languages.NewCAnalyzer())
 	librarydetection.AddAnalyzer("C++", languages.NewCppAnalyzer())
 	librarydetection.AddAnalyzer("C#", languages.NewCSharpAnalyzer())
 	librarydetection.AddAnalyzer("Java", languages.NewJavaAnalyzer())
 	librarydetection.AddAnalyzer("JavaScript", languages.NewJavaScriptAnalyzer())
 	librarydetection.AddAnalyzer("Kotlin", languages.NewKotlinAnalyzer())
-	librarydetection.AddAnalyzer("TypeScript", languages.NewTypeScriptAnalyzer())
-	librarydetection.AddAnalyzer("Perl", languages.NewPerlAnalyzer())
+	librarydetection.AddAnalyzer("TypeScript", languages.NewTypeScriptAnalyzerWithOptions(languages.TypeScriptOptions{
+		ExcludeTypeImports: r.ExcludeTypeScriptTypes,
+	}))
+	perlPragmas := r.PerlPragmas
+	if perlPragmas == nil {
+		perlPragmas = languages.DefaultPerlPragmas
+	}
+	librarydetection.AddAnalyzer("Perl", languages.NewPerlAnalyzerWithOptions(languages.PerlOptions{Pragmas: perlPragmas}))
 	librarydetection.AddAnalyzer("PHP", languages.NewPHPAnalyzer())
-	librarydetection.AddAnalyzer("Python", languages.NewPythonScriptAnalyzer())
+	librarydetection.AddAnalyzer("Python", languages.NewPythonScriptAnalyzerWithOptions(languages.PythonOptions{
+		Granularity:            r.PythonGranularity,
+		ExcludeStandardLibrary: r.ExcludePythonStdlib,
+		Version:                r.PythonVersion,
+	}))
 	librarydetection.AddAnalyzer("Ruby", languages.NewRubyScriptAnalyzer())
 	librarydetection.AddAnalyzer("Swift", languages.NewSwiftAnalyzer())
+	librarydetection.AddAnalyzer("Dockerfile", languages.NewDockerfileAnalyzer())
+	librarydetection.AddAnalyzer("R", languages.NewRAnalyzer())
+	librarydetection.AddAnalyzer("Lua", languages.NewLuaAnalyzer())
+	librarydetection.AddAnalyzer("Groovy", languages.NewGroovyAnalyzer())
+	librarydetection.AddAnalyzer("Jenkins", languages.NewGroovyAnalyzer())
+	librarydetection.AddAnalyzer("Solidity", languages.NewSolidityAnalyzer())
+	librarydetection.AddAnalyzer("MATLAB", languages.NewMatlabAnalyzer())
+	librarydetection.AddAnalyzer("Assembly", languages.NewAssemblyAnalyzer())
+	librarydetection.AddAnalyzer("GDScript", languages.NewGDScriptAnalyzer())
+	// The dialects of the .sql files are detected by their content
+	for _, sqlDialect := range []string{"SQL", "PLpgSQL", "PLSQL", "TSQL", "SQLPL"} {
+		librarydetection.AddAnalyzer(sqlDialect, languages.NewSQLAnalyzer())
+	}
+	shellCommands := r.ShellCommands
+	if shellCommands == nil {
+		shellCommands = languages.DefaultShellCommands
+	}
+	librarydetection.AddAnalyzer("Shell", languages.NewShellAnalyzerWithOptions(languages.ShellOptions{Commands: shellCommands}))
+	librarydetection.AddAnalyzer("Makefile", languages.NewMakefileAnalyzer())
+	librarydetection.AddAnalyzer("CMake", languages.NewCMakeAnalyzer())
+
+	librarydetection.AddManifestAnalyzer("go.mod", languages.NewGoModAnalyzer())
+	librarydetection.AddManifestAnalyzer("package.json", languages.NewPackageJSONAnalyzer())
+	librarydetection.AddManifestAnalyzer("requirements.txt", languages.NewRequirementsTxtAnalyzer())
+	librarydetection.AddManifestAnalyzer("Pipfile", languages.NewPipfileAnalyzer())
+	librarydetection.AddManifestAnalyzer("pyproject.toml", languages.NewPyprojectAnalyzer())
+	librarydetection.AddManifestAnalyzer("Package.resolved", languages.NewPackageResolvedAnalyzer())
+	librarydetection.AddManifestAnalyzer("Package.swift", languages.NewPackageSwiftAnalyzer())
+	librarydetection.AddManifestAnalyzer("composer.json", languages.NewComposerJSONAnalyzer())
+	librarydetection.AddManifestAnalyzer("composer.lock", languages.NewComposerLockAnalyzer())
+	librarydetection.AddManifestAnalyzer("Gemfile.lock", languages.NewGemfileLockAnalyzer())
+	librarydetection.AddManifestAnalyzer("Gemfile", languages.NewGemfileAnalyzer())
+	librarydetection.AddManifestAnalyzer("pom.xml", languages.NewPomXMLAnalyzer())
+	librarydetection.AddManifestAnalyzer("build.gradle", languages.NewGradleAnalyzer())
+	librarydetection.AddManifestAnalyzer("build.gradle.kts", languages.NewGradleAnalyzer())
+	librarydetection.AddManifestAnalyzer("renv.lock", languages.NewRenvLockAnalyzer())
+	librarydetection.AddManifestAnalyzer("DESCRIPTION", languages.NewRDescriptionAnalyzer())
+	librarydetection.AddPathManifestAnalyzer(".github/workflows/*.yml", languages.NewGitHubActionsAnalyzer())
+	librarydetection.AddPathManifestAnalyzer(".github/workflows/*.yaml", languages.NewGitHubActionsAnalyzer())
 }
 
 // Creates commits
+// analyseCommits selects the emails of the user
+// If the emails are not given, the emails of every author are collected in a quick pass over the history,
+// without the changed files, so the user can select them. If the email pattern is given, the matching emails
+// are selected besides the given emails, without asking the user.
 func (r *RepoExtractor) analyseCommits(ctx context.Context) error {
-	fmt.Println("Analysing commits")
+	r.Logger.Info("Analysing commits", nil)
 
-	var commits []*commit.Commit
-	commits, err := r.getCommits(ctx)
-	userCommits := make([]*commit.Commit, 0, len(commits))
-	if len(commits) == 0 {
+	r.selectedEmails = make(map[string]bool)
+	r.selectEmails(r.UserEmails)
+	if len(r.UserEmails) > 0 && r.EmailPattern == nil {
 		return nil
 	}
+
+	// A failing git log must fail the extraction, instead of exporting an empty history
+	allEmails, commits, err := r.getAllEmails(ctx)
 	if err != nil {
 		return err
 	}
+	if len(allEmails) == 0 {
+		return nil
+	}
 
-	allEmails := getAllEmails(commits)
-	selectedEmails := make(map[string]bool)
-
-	if len(r.UserEmails) == 0 {
-		selectedEmailsWithNames := ui.SelectEmail(allEmails)
-		emails, emailsMap := getEmailsWithoutNames(selectedEmailsWithNames)
-		r.repo.Emails = append(r.repo.Emails, emails...)
-		for mail := range emailsMap {
-			selectedEmails[mail] = true
-		}
+	var selectedEmailsWithNames []string
+	if r.EmailPattern != nil {
+		selectedEmailsWithNames = getEmailsMatching(allEmails, r.EmailPattern)
+		r.Logger.Info("Emails selected by pattern", logger.Fields{"pattern": r.EmailPattern.String(), "emails": len(selectedEmailsWithNames)})
 	} else {
-		r.repo.Emails = append(r.repo.Emails, r.UserEmails...)
-		for _, email := range r.UserEmails {
-			selectedEmails[email] = true
-		}
+		selectedEmailsWithNames = ui.SelectEmail(allEmails, commits)
 	}
+	emails, _ := getEmailsWithoutNames(selectedEmailsWithNames)
+	r.selectEmails(emails)
+	return nil
+}
 
-	// Only consider commits for user
-	for _, v := range commits {
-		if _, ok := selectedEmails[v.AuthorEmail]; ok {
-			userCommits = append(userCommits, v)
+// selectEmails adds the emails to the selected ones, skipping the already selected emails
+// The emails given by the user are replaced with the emails of their identities, like the emails of the commits.
+func (r *RepoExtractor) selectEmails(emails []string) {
+	for _, email := range emails {
+		email = r.identities.canonicalEmail("", email)
+		if !r.selectedEmails[email] {
+			r.selectedEmails[email] = true
+			r.repo.Emails = append(r.repo.Emails, email)
 		}
 	}
+}
+
+// getNumberOfWorkers returns with the number of workers used by the worker pools
+func (r *RepoExtractor) getNumberOfWorkers() int {
+	if r.Workers > 0 {
+		return r.Workers
+	}
+	return runtime.NumCPU()
+}
 
-	r.userCommits = userCommits
-	return nil
+// getAllEmails returns with the emails of the authors and co-authors in "Name -> email" format,
+// and the number of commits by email, which are counted in the same pass
+func (r *RepoExtractor) getAllEmails(ctx context.Context) ([]string, map[string]int, error) {
+	emails, err := r.collectEmails(ctx)
+	return emails.emails, emails.commitCounts(), err
 }
 
-func (r *RepoExtractor) getCommits(ctx context.Context) ([]*commit.Commit, error) {
-	jobs := make(chan *req)
-	results := make(chan []*commit.Commit)
-	noMoreChan := make(chan bool)
-	for w := 0; w < runtime.NumCPU(); w++ {
-		go func() {
-			err := r.commitWorker(w, jobs, results, noMoreChan)
-			if err != nil {
-				fmt.Println("Error during getting commits. Error: " + err.Error())
-			}
-		}()
+// collectEmails collects the emails of the authors and co-authors with the number of their commits
+func (r *RepoExtractor) collectEmails(ctx context.Context) (*emailCollector, error) {
+	pb := r.progressStage("Fetching commits")
+
+	emails := newEmailCollector()
+	numberOfCommits := 0
+	err := r.streamCommits(ctx, false, func(c *commit.Commit) {
+		emails.add(c)
+		numberOfCommits++
+		pb.Inc()
+	})
+	pb.Finish()
+	if err != nil {
+		r.Logger.Error("Error during getting commits", logger.Fields{"error": err.Error()})
+		return emails, err
 	}
 
-	// launch initial jobs
-	lastOffset := 0
-	step := 1000
-	for x := 0; x < runtime.NumCPU(); x++ {
-		jobs <- &req{
-			Limit:  step,
-			Offset: x * step,
+	r.Logger.Info("Commits fetched", logger.Fields{"commits": numberOfCommits, "emails": len(emails.emails)})
+	return emails, nil
+}
+
+// isCommitOfEmails tells if the commit was authored or co-authored by any of the emails
+func isCommitOfEmails(c *commit.Commit, emails map[string]bool) bool {
+	if emails[c.AuthorEmail] {
+		return true
+	}
+	for _, coAuthor := range c.CoAuthors {
+		if emails[coAuthor.Email] {
+			return true
 		}
-		lastOffset = step * x
 	}
+	return false
+}
 
-	var commits []*commit.Commit
-	workersReturnedNoMore := 0
+// emailCollector collects the unique emails of the authors and co-authors in "Name -> email" format
+// and the number of the commits of every email
+type emailCollector struct {
+	seenEmails map[string]bool
+	emails     []string
+	names      map[string]string
+	commits    map[string]int
+}
 
-	var pb ui.ProgressBar
-	numberOfCommits := r.getNumberOfCommits()
-	if numberOfCommits > 0 {
-		pb = ui.NewProgressBar(numberOfCommits)
-	} else {
-		pb = ui.NilProgressBar()
+func newEmailCollector() *emailCollector {
+	return &emailCollector{
+		seenEmails: make(map[string]bool),
+		names:      make(map[string]string),
+		commits:    make(map[string]int),
 	}
+}
 
-	func() {
-		for {
-			select {
-			case res := <-results:
-				lastOffset += step
-				jobs <- &req{
-					Limit:  step,
-					Offset: lastOffset,
-				}
-				commits = append(commits, res...)
-				pb.SetCurrent(len(commits))
-			case <-noMoreChan:
-				workersReturnedNoMore++
-				if workersReturnedNoMore == runtime.NumCPU() {
-					close(jobs)
-					return
-				}
-			case <-ctx.Done():
-				fmt.Println("Time limit exceeded. Couldn't get all the commits.")
-				close(jobs)
-				return
-			}
+func (e *emailCollector) add(c *commit.Commit) {
+	e.addEmail(c.AuthorName, c.AuthorEmail)
+	e.commits[c.AuthorEmail]++
+	// The co-author trailers can repeat the author or each other, the commit is counted once for every email
+	counted := map[string]bool{c.AuthorEmail: true}
+	for _, coAuthor := range c.CoAuthors {
+		e.addEmail(coAuthor.Name, coAuthor.Email)
+		if !counted[coAuthor.Email] {
+			counted[coAuthor.Email] = true
+			e.commits[coAuthor.Email]++
 		}
-	}()
-	pb.Finish()
+	}
+}
+
+// commitCounts returns with the number of commits by email, a commit counts for its author and every co-author
+func (e *emailCollector) commitCounts() map[string]int {
+	return e.commits
+}
 
-	return commits, nil
+func (e *emailCollector) addEmail(name, email string) {
+	if !e.seenEmails[email] {
+		e.seenEmails[email] = true
+		e.names[email] = name
+		e.emails = append(e.emails, fmt.Sprintf("%s -> %s", name, email))
+	}
 }
 
-func getAllEmails(commits []*commit.Commit) []string {
-	allEmails := make([]string, 0, len(commits))
-	emails := make(map[string]bool) // To prevent duplicates
-	for _, v := range commits {
-		if _, ok := emails[v.AuthorEmail]; !ok {
-			emails[v.AuthorEmail] = true
-			allEmails = append(allEmails, fmt.Sprintf("%s -> %s", v.AuthorName, v.AuthorEmail))
+// getEmailsMatching returns with the emails in "Name -> email" format whose email matches the pattern
+func getEmailsMatching(emails []string, pattern *regexp.Regexp) []string {
+	var matchingEmails []string
+	for _, email := range emails {
+		fields := strings.Split(email, " -> ")
+		if pattern.MatchString(fields[len(fields)-1]) {
+			matchingEmails = append(matchingEmails, email)
 		}
 	}
-	return allEmails
+	return matchingEmails
 }
 
 func getEmailsWithoutNames(emails []string) ([]string, map[string]bool) {
@@ -288,169 +633,369 @@ func getEmailsWithoutNames(emails []string) ([]string, map[string]bool) {
 }
 
 func (r *RepoExtractor) getNumberOfCommits() int {
-	cmd := exec.Command(r.GitPath,
+	args := []string{
 		"--no-pager",
 		"log",
-		"--all",
-		"--no-merges",
 		"--pretty=oneline",
-	)
+	}
+	args = append(args, r.getRevisionArgs()...)
+	cmd := exec.Command(r.GitPath, args...)
 	cmd.Dir = r.RepoPath
 	stdout, err := cmd.CombinedOutput()
 	if err != nil {
-		fmt.Println("Cannot get number of commits. Cannot show progress bar. Error: " + err.Error())
+		r.Logger.Warning("Cannot get number of commits. Cannot show progress bar", logger.Fields{"error": err.Error()})
 		return 0
 	}
 	return strings.Count(string(stdout), "\n")
 }
 
-// commitWorker get commits from git
-func (r *RepoExtractor) commitWorker(w int, jobs <-chan *req, results chan<- []*commit.Commit, noMoreChan chan<- bool) error {
-	for v := range jobs {
-		var commits []*commit.Commit
-
-		cmd := exec.Command(r.GitPath,
-			"log",
-			"--numstat",
-			"--all",
-			fmt.Sprintf("--skip=%d", v.Offset),
-			fmt.Sprintf("--max-count=%d", v.Limit),
-			"--pretty=format:|||BEGIN|||%H|||SEP|||%an|||SEP|||%ae|||SEP|||%ad",
-			"--no-merges",
-		)
-		cmd.Dir = r.RepoPath
-		stdout, err := cmd.StdoutPipe()
-		if nil != err {
-			fmt.Println("Cannot create pipe.")
-			return err
+// getNumberOfUserCommits returns with the number of the commits of the selected emails in the selected history
+// The commits are listed without their changed files, so it is much faster than the analysis.
+func (r *RepoExtractor) getNumberOfUserCommits() (int, error) {
+	numberOfUserCommits := 0
+	err := r.streamCommits(context.Background(), false, func(c *commit.Commit) {
+		if isCommitOfEmails(c, r.selectedEmails) {
+			numberOfUserCommits++
 		}
-		if err := cmd.Start(); err != nil {
-			fmt.Println("Error during execution of Git command.")
-			return err
+	})
+	return numberOfUserCommits, err
+}
+
+// getRevisionArgs returns with the git log arguments which select the commits to analyse
+func (r *RepoExtractor) getRevisionArgs() []string {
+	var args []string
+	if !r.IncludeMerges {
+		args = append(args, "--no-merges")
+	}
+	if r.MaxCommits > 0 {
+		// git log lists the most recent commits first, and the total of the progress bars is capped too
+		args = append(args, fmt.Sprintf("--max-count=%d", r.MaxCommits))
+	}
+	switch {
+	case len(r.currentRefs) > 0:
+		// The same commits are saved to the state file, which are analysed now
+		args = append(args, r.currentRefs...)
+	case r.RevRange != "":
+		args = append(args, r.RevRange)
+	case len(r.Refs) > 0:
+		args = append(args, r.Refs...)
+	default:
+		args = append(args, "--all")
+	}
+	if r.previousState != nil {
+		for _, ref := range r.previousState.ProcessedRefs {
+			args = append(args, "^"+ref)
 		}
+	}
+	if !r.Since.IsZero() {
+		args = append(args, "--since="+r.Since.Format(time.RFC3339))
+	}
+	if !r.Until.IsZero() {
+		args = append(args, "--until="+r.Until.Format(time.RFC3339))
+	}
+	return args
+}
 
-		// parse the output into stats
-		scanner := bufio.NewScanner(stdout)
-		currentLine := 0
-		var currectCommit *commit.Commit
-		for scanner.Scan() {
-			m := scanner.Text()
-			currentLine++
-			if m == "" {
-				continue
-			}
-			if strings.HasPrefix(m, "|||BEGIN|||") {
-				// we reached a new commit
-				// save the existing
-				if currectCommit != nil {
-					commits = append(commits, currectCommit)
-				}
+// streamCommits runs git log and calls handle with every commit as soon as it is parsed
+// The changed files are only listed with numstat, which makes git log much slower.
+// If the context is done, git log is stopped and the commits parsed so far are kept.
+func (r *RepoExtractor) streamCommits(ctx context.Context, numstat bool, handle func(*commit.Commit)) error {
+	args := []string{
+		"log",
+		// The subject is always a single line. The body can span multiple lines, so it is closed by |||END|||.
+		// The author date is in strict ISO 8601 format, which doesn't depend on the config of git.
+		"--pretty=format:|||BEGIN|||%H|||SEP|||%an|||SEP|||%ae|||SEP|||%aI|||SEP|||%s%n|||BODY|||%b|||END|||",
+	}
+	if numstat {
+		args = append(args, "--numstat")
+	}
+	if r.IncludeMerges {
+		// git log doesn't show the changes of merge commits without -m. It shows the merge once for every parent,
+		// but only the first one, compared to the first parent, is kept by parseCommits.
+		args = append(args, "-m")
+	}
+	args = append(args, r.getRevisionArgs()...)
+	cmd := exec.CommandContext(ctx, r.GitPath, args...)
+	cmd.Dir = r.RepoPath
+	stdout, err := cmd.StdoutPipe()
+	if nil != err {
+		r.Logger.Error("Cannot create pipe", logger.Fields{"error": err.Error()})
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		// git log is not started if the time limit is exceeded before
+		if ctx.Err() != nil {
+			r.Logger.Warning("Time limit exceeded. Couldn't get all the commits", nil)
+			return nil
+		}
+		r.Logger.Error("Error during execution of Git command", logger.Fields{"error": err.Error()})
+		return err
+	}
 
-				// and add new one commit
-				m = strings.Replace(m, "|||BEGIN|||", "", 1)
-				bits := strings.Split(m, "|||SEP|||")
-				changedFiles := []*commit.ChangedFile{}
-				dateStr := ""
-				t, err := time.Parse("Mon Jan 2 15:04:05 2006 -0700", bits[3])
-				if err == nil {
-					dateStr = t.Format("2006-01-02 15:04:05 -0700")
-				} else {
-					fmt.Println("Cannot convert date. Expected date format: Mon Jan 2 15:04:05 2006 -0700. Got: " + bits[3])
-				}
-				currectCommit = &commit.Commit{
-					Hash:         bits[0],
-					AuthorName:   bits[1],
-					AuthorEmail:  bits[2],
-					Date:         dateStr,
-					ChangedFiles: changedFiles,
-				}
-				continue
-			}
+	parseErr := r.parseCommits(stdout, handle)
+	if parseErr != nil {
+		// Stop git log, the rest of the output cannot be parsed
+		cmd.Process.Kill()
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		r.Logger.Warning("Time limit exceeded. Couldn't get all the commits", nil)
+		return nil
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+	return err
+}
 
-			bits := strings.Fields(m)
+// commitDateLayouts are the date formats of git, the strict ISO 8601 format of %aI is tried first
+var commitDateLayouts = []string{
+	time.RFC3339,                     // %aI
+	"2006-01-02 15:04:05 -0700",      // %ai
+	"Mon Jan 2 15:04:05 2006 -0700",  // %ad with the default date format
+	"Mon, 2 Jan 2006 15:04:05 -0700", // %aD, RFC 2822
+}
 
-			insertionsString := bits[0]
-			if insertionsString == "-" {
-				insertionsString = "0"
-			}
-			insertions, err := strconv.Atoi(insertionsString)
-			if err != nil {
-				fmt.Println("Cannot convert the following into integer: " + insertionsString)
-				return err
-			}
+// parseCommitDate parses the date of a commit in any of the date formats of git
+func parseCommitDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range commitDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format: %q", value)
+}
 
-			deletionsString := bits[1]
-			if deletionsString == "-" {
-				deletionsString = "0"
-			}
-			deletions, err := strconv.Atoi(deletionsString)
-			if err != nil {
-				fmt.Println("Cannot convert the following into integer: " + deletionsString)
-				return err
-			}
+// parseCommits parses the output of git log and calls handle with every commit
+// Every commit is handled once, even if git log returns it multiple times.
+// The commits whose date cannot be parsed are skipped, so they are not aggregated into a wrong day.
+func (r *RepoExtractor) parseCommits(output io.Reader, handle func(*commit.Commit)) error {
+	seenCommits := make(map[string]bool)
+	handleOnce := func(c *commit.Commit) {
+		if seenCommits[c.Hash] || c.Date == "" {
+			return
+		}
+		seenCommits[c.Hash] = true
+		r.identities.canonicalize(c)
+		handle(c)
+	}
 
-			fileName := bits[2]
-			// it is a rename, skip
-			if strings.Contains("=>", fileName) {
-				continue
+	scanner := bufio.NewScanner(output)
+	// Lines of commit messages can be longer than the default limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var currectCommit *commit.Commit
+	var body []string
+	inBody := false
+	for scanner.Scan() {
+		m := scanner.Text()
+		if inBody || strings.HasPrefix(m, "|||BODY|||") {
+			m = strings.TrimPrefix(m, "|||BODY|||")
+			inBody = !strings.HasSuffix(m, "|||END|||")
+			body = append(body, strings.TrimSuffix(m, "|||END|||"))
+			if !inBody && currectCommit != nil {
+				currectCommit.Message = getCommitMessage(currectCommit.Message, body)
+				currectCommit.CoAuthors = parseCoAuthors(currectCommit.Message, currectCommit.AuthorEmail)
+				body = nil
 			}
-
-			changedFile := &commit.ChangedFile{
-				Path:       bits[2],
-				Insertions: insertions,
-				Deletions:  deletions,
+			continue
+		}
+		if m == "" {
+			continue
+		}
+		if strings.HasPrefix(m, "|||BEGIN|||") {
+			// we reached a new commit
+			// save the existing
+			if currectCommit != nil {
+				handleOnce(currectCommit)
 			}
 
-			if currectCommit == nil {
-				// TODO maybe skip? does this break anything?
-				return errors.New("did not expect current commit to be null")
+			// and add new one commit
+			m = strings.Replace(m, "|||BEGIN|||", "", 1)
+			bits := strings.SplitN(m, "|||SEP|||", 5)
+			if len(bits) != 5 {
+				return fmt.Errorf("unexpected commit header: %s", m)
 			}
-
-			if currectCommit.ChangedFiles == nil {
-				// TODO maybe skip? does this break anything?
-				return errors.New("did not expect current commit changed files to be null")
+			changedFiles := []*commit.ChangedFile{}
+			dateStr := ""
+			t, err := parseCommitDate(bits[3])
+			if err == nil {
+				dateStr = t.Format("2006-01-02 15:04:05 -0700")
+			} else {
+				r.Logger.Warning("Cannot parse the date of the commit, it is skipped", logger.Fields{"hash": bits[0], "date": bits[3]})
 			}
-
-			currectCommit.ChangedFiles = append(currectCommit.ChangedFiles, changedFile)
+			currectCommit = &commit.Commit{
+				Hash:         bits[0],
+				AuthorName:   bits[1],
+				AuthorEmail:  bits[2],
+				Date:         dateStr,
+				Message:      bits[4],
+				ChangedFiles: changedFiles,
+			}
+			continue
 		}
 
-		// last commit will not get appended otherwise
-		// because scanner is not returning anything
-		if currectCommit != nil {
-			commits = append(commits, currectCommit)
+		changedFile, err := parseNumstatLine(m)
+		if err != nil {
+			r.Logger.Error("Cannot parse the changed files", logger.Fields{"error": err.Error()})
+			return err
 		}
 
-		if len(commits) == 0 {
-			noMoreChan <- true
-			return nil
+		if currectCommit == nil {
+			// TODO maybe skip? does this break anything?
+			return errors.New("did not expect current commit to be null")
 		}
-		results <- commits
+
+		currectCommit.ChangedFiles = append(currectCommit.ChangedFiles, changedFile)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// last commit will not get appended otherwise
+	// because scanner is not returning anything
+	if currectCommit != nil {
+		handleOnce(currectCommit)
 	}
 	return nil
 }
 
-func (r *RepoExtractor) analyseLibraries(ctx context.Context) {
-	fmt.Println("Analysing libraries")
-	defer func() {
-		r.libraryExtractionCompleted <- true
-	}()
+// getCommitMessage joins the subject and the body of the commit
+func getCommitMessage(subject string, body []string) string {
+	bodyText := strings.TrimSpace(strings.Join(body, "\n"))
+	if bodyText == "" {
+		return subject
+	}
+	return subject + "\n\n" + bodyText
+}
+
+// parseNumstatLine parses a single line of git log --numstat output
+// Renamed files are recorded with their post-rename path.
+func parseNumstatLine(line string) (*commit.ChangedFile, error) {
+	bits := strings.SplitN(line, "\t", 3)
+	if len(bits) != 3 {
+		return nil, fmt.Errorf("unexpected numstat line: %s", line)
+	}
+	// Git doesn't count the lines of binary files, they are reported as "-\t-\tfile"
+	binary := bits[0] == "-" && bits[1] == "-"
 
-	jobs := make(chan *commit.Commit, len(r.userCommits))
-	results := make(chan bool, len(r.userCommits))
-	// Analyse libraries for every commit
-	for w := 1; w <= runtime.NumCPU(); w++ {
-		go r.libraryWorker(ctx, jobs, results)
+	insertionsString := bits[0]
+	if insertionsString == "-" {
+		insertionsString = "0"
 	}
-	for _, v := range r.userCommits {
-		jobs <- v
+	insertions, err := strconv.Atoi(insertionsString)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert the following into integer: %s", insertionsString)
 	}
-	close(jobs)
-	pb := ui.NewProgressBar(len(r.userCommits))
-	for a := 1; a <= len(r.userCommits); a++ {
-		<-results
-		pb.Inc()
+
+	deletionsString := bits[1]
+	if deletionsString == "-" {
+		deletionsString = "0"
+	}
+	deletions, err := strconv.Atoi(deletionsString)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert the following into integer: %s", deletionsString)
+	}
+
+	return &commit.ChangedFile{
+		Path:       getRenamedPath(bits[2]),
+		Insertions: insertions,
+		Deletions:  deletions,
+		Binary:     binary,
+	}, nil
+}
+
+// getRenamedPath returns with the new path of a renamed file
+// Git uses the following formats for renames:
+// "old.go => new.go" and "src/{old => new}/file.go"
+func getRenamedPath(path string) string {
+	if !strings.Contains(path, " => ") {
+		return path
+	}
+
+	start := strings.Index(path, "{")
+	end := strings.LastIndex(path, "}")
+	if start == -1 || end < start {
+		parts := strings.SplitN(path, " => ", 2)
+		return parts[1]
+	}
+
+	parts := strings.SplitN(path[start+1:end], " => ", 2)
+	if len(parts) != 2 {
+		return path
+	}
+	newPath := path[:start] + parts[1] + path[end+1:]
+	// "src/{ => sub}/file.go" and "src/{sub => }/file.go" leave double slashes behind
+	return strings.Replace(newPath, "//", "/", -1)
+}
+
+func (r *RepoExtractor) analyseLibraries(ctx context.Context) {
+	r.Logger.Info("Analysing libraries", nil)
+	// The channel is closed, so the completion can be waited for more than once
+	defer close(r.libraryExtractionCompleted)
+
+	pb := r.progressStage("Analysing libraries")
+
+	r.blobCache = newBlobCache()
+	// The channel is bounded, so only a few commits are held in memory, even in huge repositories
+	workers := r.getNumberOfWorkers()
+	jobs := make(chan *commit.Commit, workers)
+	var wg sync.WaitGroup
+	for w := 1; w <= workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.libraryWorker(ctx, jobs)
+		}()
 	}
+
+	// Only the commits of the user are analysed
+	numberOfCommits := 0
+	numberOfUserCommits := 0
+	err := r.streamCommits(ctx, true, func(c *commit.Commit) {
+		numberOfCommits++
+		if isCommitOfEmails(c, r.selectedEmails) {
+			numberOfUserCommits++
+			jobs <- c
+		}
+		pb.Inc()
+	})
+	close(jobs)
+	wg.Wait()
 	pb.Finish()
+	if ctx.Err() != nil {
+		// git log is killed by the time limit, so its error is expected. The commits it didn't list are not processed.
+		processedCommits := numberOfUserCommits - int(atomic.LoadInt32(&r.droppedCommits))
+		totalCommits, err := r.getNumberOfUserCommits()
+		if err != nil {
+			r.Logger.Warning("Cannot get the number of the commits of the selected emails", logger.Fields{"error": err.Error()})
+			totalCommits = numberOfUserCommits
+		}
+		if processedCommits < totalCommits {
+			r.partial = &partialExport{ProcessedCommits: processedCommits, TotalCommits: totalCommits}
+			r.Logger.Warning("Time limit exceeded. The commits analysed so far are exported", logger.Fields{"processedCommits": processedCommits, "totalCommits": totalCommits})
+		}
+		return
+	}
+	if err != nil {
+		r.Logger.Error("Error during getting commits", logger.Fields{"error": err.Error()})
+		r.commitsErr = err
+		return
+	}
+	r.Logger.Info("Libraries analysed", logger.Fields{"commits": numberOfCommits, "userCommits": numberOfUserCommits})
+}
+
+// stopLibraryAnalysis stops the library workers and waits for them if the export failed before it read every commit
+// Otherwise the workers and their git processes would be blocked forever on the pipeline.
+func (r *RepoExtractor) stopLibraryAnalysis(cancel context.CancelFunc) {
+	cancel()
+	for {
+		select {
+		case <-r.commitPipeline:
+		case <-r.libraryExtractionCompleted:
+			return
+		}
+	}
 }
 
 func (r *RepoExtractor) getFileContent(commitHash, filePath string) ([]byte, error) {
@@ -460,119 +1005,354 @@ func (r *RepoExtractor) getFileContent(commitHash, filePath string) ([]byte, err
 		fmt.Sprintf("%s:%s", commitHash, filePath),
 	)
 	cmd.Dir = r.RepoPath
-	var err error
-	fileContents, err := cmd.CombinedOutput()
+	fileContents, err := cmd.Output()
 	if err != nil {
-		searchString1 := fmt.Sprintf("Path '%s' does not exist in '%s'", filePath, commitHash)
-		searchString2 := fmt.Sprintf("Path '%s' exists on disk, but not in '%s'", filePath, commitHash)
-		// Ignore case is needed because on windows error message starts with lowercase letter, in other systems it starts with uppercase letter
-		stringSearcher := search.New(language.English, search.IgnoreCase)
-		// means the file was deleted, skip
-		start, end := stringSearcher.IndexString(string(fileContents), searchString1)
-		if start != -1 && end != -1 {
-			return []byte{}, nil
-		}
-		start, end = stringSearcher.IndexString(string(fileContents), searchString2)
-		if start != -1 && end != -1 {
-			return []byte{}, nil
+		// Git exits with 128 if the path does not exist in the commit, means the file was deleted, skip
+		// It is also the exit code of the other fatal errors, like a corrupt object, so the path is looked up.
+		// The error message is not checked, because it depends on the locale of git.
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == gitFatalExitCode {
+			if exists, lookupErr := r.pathExists(commitHash, filePath); lookupErr == nil && !exists {
+				return []byte{}, nil
+			}
 		}
 		return nil, err
 	}
 	return fileContents, nil
 }
 
-func (r *RepoExtractor) libraryWorker(ctx context.Context, commits <-chan *commit.Commit, results chan<- bool) error {
-	languageAnalyzer := languagedetection.NewLanguageAnalyzer()
-	hasTimeout := false
-	for commitToAnalyse := range commits {
-		c := commit.Commit{
-			ChangedFiles: commitToAnalyse.ChangedFiles,
-			Libraries:    make(map[string][]string),
-		}
-		c.Hash = commitToAnalyse.Hash
-		c.AuthorEmail = commitToAnalyse.AuthorEmail
-		c.AuthorName = commitToAnalyse.AuthorName
+// pathExists tells if the path exists in the commit
+func (r *RepoExtractor) pathExists(commitHash, filePath string) (bool, error) {
+	cmd := exec.Command(r.GitPath,
+		"--literal-pathspecs",
+		"ls-tree",
+		"--name-only",
+		"-z",
+		commitHash,
+		"--",
+		filePath,
+	)
+	cmd.Dir = r.RepoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return len(out) > 0, nil
+}
+
+// getBlobHashes returns with the blob hashes and the sizes in bytes of the given files in the commit
+// Deleted files are missing from the result.
+func (r *RepoExtractor) getBlobHashes(ctx context.Context, commitHash string, filePaths []string) (map[string]string, map[string]int64, error) {
+	blobHashes := make(map[string]string, len(filePaths))
+	blobSizes := make(map[string]int64, len(filePaths))
+	// Avoid hitting the argument length limit with huge commits
+	step := 500
+	for start := 0; start < len(filePaths); start += step {
+		end := start + step
+		if end > len(filePaths) {
+			end = len(filePaths)
+		}
+		args := []string{
+			"--literal-pathspecs",
+			"ls-tree",
+			"-z",
+			"--long",
+			commitHash,
+			"--",
+		}
+		args = append(args, filePaths[start:end]...)
+		cmd := exec.CommandContext(ctx, r.GitPath, args...)
+		cmd.Dir = r.RepoPath
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Every entry looks like this: "<mode> <type> <hash> <size>\t<path>\x00"
+		for _, entry := range strings.Split(string(out), "\x00") {
+			parts := strings.SplitN(entry, "\t", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			fields := strings.Fields(parts[0])
+			if len(fields) != 4 || fields[1] != "blob" {
+				continue
+			}
+			blobHashes[parts[1]] = fields[2]
+			if size, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+				blobSizes[parts[1]] = size
+			}
+		}
+	}
+	return blobHashes, blobSizes, nil
+}
+
+// readFileContent reads the file content with the worker's cat-file process
+// If it is not available, it falls back to git show.
+func (r *RepoExtractor) readFileContent(contentReader *catFile, commitHash, filePath string) ([]byte, error) {
+	if contentReader != nil {
+		fileContents, err := contentReader.getFileContent(commitHash, filePath)
+		if err == nil {
+			return fileContents, nil
+		}
+	}
+	return r.getFileContent(commitHash, filePath)
+}
+
+func (r *RepoExtractor) libraryWorker(ctx context.Context, commits <-chan *commit.Commit) error {
+	languageAnalyzer := languagedetection.NewLanguageAnalyzer()
+	// The git processes of the worker are killed at the time limit, so the export isn't held up by a slow commit
+	contentReader, err := newCatFile(ctx, r.GitPath, r.RepoPath)
+	if err != nil {
+		r.Logger.Warning("Cannot start git cat-file. Fall back to git show", logger.Fields{"error": err.Error()})
+	} else {
+		defer contentReader.close()
+	}
+	for commitToAnalyse := range commits {
+		// After the time limit the rest of the commits are dropped, the export waits for the ones analysed so far
+		if ctx.Err() != nil {
+			atomic.AddInt32(&r.droppedCommits, 1)
+			continue
+		}
+		// Excluded files don't count in the insertions and deletions either
+		changedFiles := make([]*commit.ChangedFile, 0, len(commitToAnalyse.ChangedFiles))
+		for _, fileChange := range commitToAnalyse.ChangedFiles {
+			if !r.isExcluded(fileChange.Path) {
+				changedFiles = append(changedFiles, fileChange)
+			}
+		}
+		c := commit.Commit{
+			ChangedFiles: changedFiles,
+			Libraries:    make(map[string][]string),
+		}
+		c.Hash = commitToAnalyse.Hash
+		c.AuthorEmail = commitToAnalyse.AuthorEmail
+		c.AuthorName = commitToAnalyse.AuthorName
 		c.Date = commitToAnalyse.Date
+		c.Message = commitToAnalyse.Message
+		c.CoAuthors = commitToAnalyse.CoAuthors
 		libraries := map[string][]string{}
-		for n, fileChange := range commitToAnalyse.ChangedFiles {
-			select {
-			case <-ctx.Done():
-				if !hasTimeout {
-					hasTimeout = true
-					fmt.Println("Time limit exceeded. Couldn't analyze all the commits.")
-				}
-				c.Libraries = libraries
-				r.commitPipeline <- c
-				results <- true
-				continue
-			default:
+		skippedFiles := map[*commit.ChangedFile]bool{}
+
+		var blobHashes map[string]string
+		var blobSizes map[string]int64
+		if !r.SkipLibraries || r.MaxFileSize > 0 {
+			filePaths := make([]string, 0, len(c.ChangedFiles))
+			for _, fileChange := range c.ChangedFiles {
+				filePaths = append(filePaths, fileChange.Path)
+			}
+			var err error
+			blobHashes, blobSizes, err = r.getBlobHashes(ctx, commitToAnalyse.Hash, filePaths)
+			if err != nil {
+				// Fall back to reading every file
+				blobHashes = nil
+				blobSizes = nil
+			}
+		}
+
+		for n, fileChange := range c.ChangedFiles {
+			if ctx.Err() != nil {
+				break
 			}
 
-			lang := ""
 			var fileContents []byte
 			fileContents = nil
 
-			extension := filepath.Ext(fileChange.Path)
-			if extension == "" {
-				continue
+			// The content of huge files, like bundles or dumps, is not read. Their language is detected without
+			// the content, their libraries are not extracted, but they still count in the stats.
+			tooLarge := r.MaxFileSize > 0 && blobSizes[fileChange.Path] > r.MaxFileSize
+			if tooLarge {
+				fileContents = []byte{}
+			}
+
+			// Manifest files like go.mod contain the dependencies with their versions
+			// Malformed manifests are skipped, they don't fail the commit
+			isManifest := false
+			if !r.SkipLibraries && !tooLarge {
+				manifestAnalyzer, err := librarydetection.GetManifestAnalyzer(filepath.Base(fileChange.Path))
+				if err != nil {
+					// Some manifests like the GitHub Actions workflows are only recognized by their path
+					manifestAnalyzer, err = librarydetection.GetPathManifestAnalyzer(fileChange.Path)
+				}
+				if err == nil {
+					isManifest = true
+					fileContents, err = r.readFileContent(contentReader, commitToAnalyse.Hash, fileChange.Path)
+					if err != nil {
+						continue
+					}
+					// Deleted files have no content, so the manifest analyzers don't have to handle them
+					if len(fileContents) > 0 {
+						dependencies, err := manifestAnalyzer.ExtractDependencies(string(fileContents))
+						if err != nil {
+							r.Logger.Warning("Error extracting dependencies", logger.Fields{"path": fileChange.Path, "error": err.Error()})
+						}
+						for dependencyLang, fileDependencies := range dependencies {
+							libraries[dependencyLang] = append(libraries[dependencyLang], removeDuplicateStrings(fileDependencies)...)
+						}
+					}
+				}
 			}
-			// remove the trailing dot
-			extension = extension[1:]
 
-			if languageAnalyzer.ShouldUseFile(extension) {
+			// Some files like Dockerfile or Makefile are detected by their name
+			lang := languageAnalyzer.DetectLanguageFromFileName(filepath.Base(fileChange.Path))
+			confidence := languagedetection.ConfidenceCertain
+			extension := languagedetection.FileExtension(fileChange.Path)
+			if lang == "" && extension == "" {
+				// Scripts without extension can be detected by their shebang line
 				var err error
 				if fileContents == nil {
-					fileContents, err = r.getFileContent(commitToAnalyse.Hash, fileChange.Path)
+					fileContents, err = r.readFileContent(contentReader, commitToAnalyse.Hash, fileChange.Path)
 					if err != nil {
 						continue
 					}
 				}
-				lang = languageAnalyzer.DetectLanguageFromFile(fileChange.Path, fileContents)
-			} else {
-				lang = languageAnalyzer.DetectLanguageFromExtension(extension)
+				lang = languageAnalyzer.DetectLanguageFromShebang(fileContents)
+				confidence = languagedetection.ConfidenceHigh
+			} else if lang == "" {
+				if languageAnalyzer.ShouldUseFile(extension) {
+					var err error
+					if fileContents == nil {
+						fileContents, err = r.readFileContent(contentReader, commitToAnalyse.Hash, fileChange.Path)
+						if err != nil {
+							continue
+						}
+					}
+					lang, confidence = languageAnalyzer.DetectLanguageFromFileWithConfidence(fileChange.Path, fileContents)
+				} else {
+					lang = languageAnalyzer.DetectLanguageFromExtension(extension)
+				}
 			}
 
+			// The files of the skipped languages don't count in the stats either
+			if !isSelectedLanguage(lang, r.SkipLanguages, r.OnlyLanguages) {
+				skippedFiles[fileChange] = true
+				continue
+			}
 			// We don't know extension, nothing to do
 			if lang == "" {
 				continue
 			}
 			c.ChangedFiles[n].Language = lang
-			if !r.SkipLibraries {
+			// The generated files, like the protobuf stubs, are not written by the authors
+			if r.isGeneratedPath(fileChange.Path) {
+				if !r.CountGeneratedFiles {
+					skippedFiles[fileChange] = true
+				}
+				continue
+			}
+			// The dependencies of manifest files are already extracted
+			// The libraries of files with uncertain language and of declaration files like *.d.ts are not extracted,
+			// they are only counted in the stats
+			if !r.SkipLibraries && !isManifest && !tooLarge && confidence >= r.MinLanguageConfidence && languageAnalyzer.ShouldExtractLibraries(extension) {
 				analyzer, err := librarydetection.GetAnalyzer(lang)
 				if err != nil {
 					continue
 				}
-				if fileContents == nil {
-					fileContents, err = r.getFileContent(commitToAnalyse.Hash, fileChange.Path)
+				blobHash, blobHashKnown := blobHashes[fileChange.Path]
+				if blobHashes != nil && !blobHashKnown {
+					// The file was deleted in this commit, nothing to analyse
+					continue
+				}
+				fileLibraries, cached := r.blobCache.get(lang, blobHash)
+				generated := blobHashKnown && r.blobCache.isGenerated(blobHash)
+				if !generated && (!blobHashKnown || !cached) {
+					if fileContents == nil {
+						fileContents, err = r.readFileContent(contentReader, commitToAnalyse.Hash, fileChange.Path)
+						if err != nil {
+							continue
+						}
+					}
+					generated = isGeneratedContent(fileContents)
+					if generated && blobHashKnown {
+						r.blobCache.setGenerated(blobHash)
+					}
+				}
+				// The header of the generated files is only checked when their content is read for the libraries
+				if generated {
+					if !r.CountGeneratedFiles {
+						skippedFiles[fileChange] = true
+					}
+					continue
+				}
+				if !blobHashKnown || !cached {
+					fileLibraries, err = analyzer.ExtractLibraries(string(fileContents))
 					if err != nil {
-						continue
+						r.Logger.Warning("Error extracting libraries", logger.Fields{"language": lang, "error": err.Error()})
+					}
+					for index, fileLibrary := range fileLibraries {
+						fileLibraries[index] = strings.Replace(fileLibrary, "../", "", -1)
+					}
+					if blobHashKnown {
+						r.blobCache.set(lang, blobHash, fileLibraries)
 					}
 				}
-				fileLibraries, err := analyzer.ExtractLibraries(string(fileContents))
-				if err != nil {
-					fmt.Printf("error extracting libraries for %s: %s \n", lang, err.Error())
+				librariesKey := lang
+				if buildLanguages[lang] {
+					librariesKey = BuildLibraries
 				}
-				for index, fileLibrary := range fileLibraries {
-					fileLibraries[index] = strings.Replace(fileLibrary, "../", "", -1)
+				if libraries[librariesKey] == nil {
+					libraries[librariesKey] = make([]string, 0)
 				}
-				if libraries[lang] == nil {
-					libraries[lang] = make([]string, 0)
+				// Every file is added once to the count of its libraries
+				libraries[librariesKey] = append(libraries[librariesKey], removeDuplicateStrings(fileLibraries)...)
+			}
+		}
+		if len(skippedFiles) > 0 {
+			changedFiles := make([]*commit.ChangedFile, 0, len(c.ChangedFiles)-len(skippedFiles))
+			for _, fileChange := range c.ChangedFiles {
+				if !skippedFiles[fileChange] {
+					changedFiles = append(changedFiles, fileChange)
 				}
-				libraries[lang] = append(libraries[lang], fileLibraries...)
 			}
+			c.ChangedFiles = changedFiles
+		}
+		// Manifests can add the dependencies of the skipped languages, like "JavaScript-dev"
+		// The build files are already selected by their own languages, like "Makefile"
+		for dependencyLang := range libraries {
+			if dependencyLang != BuildLibraries && !isSelectedLanguage(strings.TrimSuffix(dependencyLang, "-dev"), r.SkipLanguages, r.OnlyLanguages) {
+				delete(libraries, dependencyLang)
+			}
+		}
+		// The commits interrupted by the time limit are dropped too, every exported commit has all of its libraries
+		if ctx.Err() != nil {
+			atomic.AddInt32(&r.droppedCommits, 1)
+			continue
 		}
 		c.Libraries = libraries
 		r.commitPipeline <- c
-		results <- true
 	}
 	return nil
 }
 
-func getStartOfDayFromStringDate(dateString string) time.Time {
+// getStartOfDayFromStringDate returns with the start of the calendar day of the date.
+// The calendar day is determined in UTC, or in the timezone of the date if useAuthorTimezone is set.
+// The result is always labeled as UTC, so the same calendar days are equal regardless of the timezone.
+func getStartOfDayFromStringDate(dateString string, useAuthorTimezone bool) time.Time {
 	commitDate, _ := time.Parse("2006-01-02 15:04:05 -0700", dateString)
+	if !useAuthorTimezone {
+		commitDate = commitDate.UTC()
+	}
 	return time.Date(commitDate.Year(), commitDate.Month(), commitDate.Day(), 0, 0, 0, 0, time.UTC)
 }
 
+// getAggregationStartFromStringDate returns with the start of the aggregation period the date belongs to
+func getAggregationStartFromStringDate(dateString string, aggregation string, useAuthorTimezone bool) time.Time {
+	switch aggregation {
+	case AggregationNone:
+		commitDate, _ := time.Parse("2006-01-02 15:04:05 -0700", dateString)
+		return commitDate.UTC()
+	case AggregationWeek:
+		startOfDay := getStartOfDayFromStringDate(dateString, useAuthorTimezone)
+		// Monday is the first day of the week
+		daysSinceMonday := (int(startOfDay.Weekday()) + 6) % 7
+		return startOfDay.AddDate(0, 0, -daysSinceMonday)
+	case AggregationMonth:
+		startOfDay := getStartOfDayFromStringDate(dateString, useAuthorTimezone)
+		return startOfDay.AddDate(0, 0, 1-startOfDay.Day())
+	default:
+		return getStartOfDayFromStringDate(dateString, useAuthorTimezone)
+	}
+}
+
 func contains(slice []string, value string) bool {
 	for _, sliceItem := range slice {
 		if sliceItem == value {
@@ -619,51 +1399,210 @@ func getCommitJSonSuffix(commitSliceLength int, commitIndex int) string {
 	return ""
 }
 
+// newProgressTracker returns with a tracker of the passes over the commits
+// The commits are fetched to collect the emails of the authors, unless the emails are given, then their libraries
+// are analysed, except in dry run mode. There is no progress bar in quiet mode and with JSON logs,
+// as it would break the lines of the log.
+func (r *RepoExtractor) newProgressTracker() ui.ProgressTracker {
+	if r.Quiet || r.Logger.IsJSON() {
+		return ui.NilProgressTracker()
+	}
+	stages := 0
+	if len(r.UserEmails) == 0 || r.EmailPattern != nil {
+		stages++
+	}
+	if !r.DryRun {
+		stages++
+	}
+	numberOfCommits := r.getNumberOfCommits()
+	if stages == 0 || numberOfCommits <= 0 {
+		return ui.NilProgressTracker()
+	}
+	return ui.NewProgressTracker(stages, numberOfCommits)
+}
+
+// progressStage starts the next stage of the progress tracker
+func (r *RepoExtractor) progressStage(label string) ui.ProgressBar {
+	if r.progress == nil {
+		return ui.NilProgressBar()
+	}
+	return r.progress.Stage(label)
+}
+
 // Writes result to the file
 func (r *RepoExtractor) export() error {
-	fmt.Println("Creating export at: " + r.OutputPath)
+	if r.OutputPath == StdoutOutputPath {
+		return r.exportToStdout()
+	}
 
-	repoDataPath := r.OutputPath + "_techloop.json"
-	// Remove old files
-	os.Remove(repoDataPath)
+	r.Logger.Info("Creating export", logger.Fields{"path": r.OutputPath})
+
+	repoDataPath := r.exportFilePath()
+
+	// The new commits of an incremental extraction are merged into the previous export
+	var previousCommits []commit.OptimizedCommitForExport
+	var previousRawCommits []commit.RawCommitForExport
+	if r.isIncremental() && r.OutputFormat != OutputFormatNDJSON {
+		var err error
+		if r.Raw {
+			err = readPreviousExport(repoDataPath, &previousRawCommits, &r.previousSummary)
+		} else {
+			err = readPreviousExport(repoDataPath, &previousCommits, &r.previousSummary)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read the previous export %s: %s", repoDataPath, err.Error())
+		}
+	}
 
 	// Create directory
 	directories := strings.Split(r.OutputPath, string(os.PathSeparator))
 	err := os.MkdirAll(strings.Join(directories[:len(directories)-1], string(os.PathSeparator)), 0755)
 	if err != nil {
-		log.Println("Cannot create directory. Error:", err.Error())
+		r.Logger.Error("Cannot create directory", logger.Fields{"error": err.Error()})
+		return err
+	}
+
+	// Every line of the ndjson export is a separate record, the new ones are appended
+	// A compressed export gets a new gzip member, concatenated members are read as a single stream.
+	appendToPrevious := r.isIncremental() && r.OutputFormat == OutputFormatNDJSON
+	err = writeFileAtomically(repoDataPath, 0644, appendToPrevious, func(w io.Writer) error {
+		return r.writeOutput(w, previousCommits, previousRawCommits)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.Logger.Info("Exported!", logger.Fields{"path": repoDataPath})
+	return nil
+}
+
+// writeFileAtomically writes the file with a temporary file next to it, which replaces the file only if it is complete
+// A failed or interrupted write leaves the previous file intact. If appendToPrevious is set, the content of the
+// previous file is copied first and the new content is appended to it.
+func writeFileAtomically(path string, perm os.FileMode, appendToPrevious bool, write func(w io.Writer) error) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		if appendToPrevious {
+			previous, err := os.Open(path)
+			if err == nil {
+				_, err = io.Copy(file, previous)
+				previous.Close()
+			}
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := write(file); err != nil {
+			return err
+		}
+		// The content must be on the disk before the rename, otherwise a crash could leave an empty file behind
+		return file.Sync()
+	}()
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// Temporary files are only readable by the owner
+		err = os.Chmod(file.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// exportFilePath returns with the path of the export file
+func (r *RepoExtractor) exportFilePath() string {
+	repoDataPath := r.OutputPath + "_techloop.json"
+	if r.OutputFormat == OutputFormatNDJSON {
+		repoDataPath = r.OutputPath + "_techloop.ndjson"
+	}
+	if r.Compress {
+		repoDataPath += ".gz"
+	}
+	return repoDataPath
+}
+
+// exportToStdout writes the export to the standard output
+// The commits are not merged into a previous export, as there is no file to read it from.
+func (r *RepoExtractor) exportToStdout() error {
+	err := r.writeOutput(os.Stdout, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	r.Logger.Info("Exported!", nil)
+	return nil
+}
+
+// writeOutput writes the export to the output, compressed with gzip if Compress is set
+func (r *RepoExtractor) writeOutput(output io.Writer, previousCommits []commit.OptimizedCommitForExport, previousRawCommits []commit.RawCommitForExport) error {
+	var compressor *gzip.Writer
+	if r.Compress {
+		compressor = gzip.NewWriter(output)
+		output = compressor
 	}
 
-	file, err := os.Create(repoDataPath)
+	w := bufio.NewWriter(output)
+	err := r.writeExport(w, previousCommits, previousRawCommits)
+	if err != nil {
+		return err
+	}
+	// The buffer must be flushed before the gzip writer is closed, otherwise its end is lost
+	err = w.Flush()
 	if err != nil {
 		return err
 	}
+	if compressor != nil {
+		return compressor.Close()
+	}
+	return nil
+}
 
-	w := bufio.NewWriter(file)
-	fmt.Fprintln(w, "[")
-	var preparedCommitsDataForExport []commit.OptimizedCommitForExport
+// writeExport writes the commits from the pipeline in the selected format
+// It always waits for the library workers, after the time limit they send the commits analysed so far, so the partial
+// export contains every analysed commit and it is still complete and valid.
+// If git log failed during the analysis, its error is returned, so the incomplete export doesn't replace the previous one.
+func (r *RepoExtractor) writeExport(w *bufio.Writer, previousCommits []commit.OptimizedCommitForExport, previousRawCommits []commit.RawCommitForExport) error {
+	if r.Raw {
+		r.exportRaw(w, previousRawCommits)
+	} else if r.OutputFormat == OutputFormatNDJSON {
+		r.exportNDJSON(w)
+	} else {
+		r.exportJSON(w, previousCommits)
+	}
+	return r.commitsErr
+}
+
+// exportJSON aggregates the commits per day (or the configured aggregation period) and writes them as a single JSON array
+// The previous commits are the records of the previous export in case of incremental extraction.
+func (r *RepoExtractor) exportJSON(w *bufio.Writer, previousCommits []commit.OptimizedCommitForExport) {
+	r.writeJSONHeader(w)
+	preparedCommitsDataForExport := previousCommits
+	languages := map[string]LanguageSummary{}
 
 loop:
 	for {
 		select {
 		case commitFromPipeline := <-r.commitPipeline:
-			commitDateStartHour := getStartOfDayFromStringDate(commitFromPipeline.Date)
-
-			var commitLanguages []string
-			var commitInsertions, commitDeletions int
+			addToLanguageSummary(languages, commitFromPipeline)
+			optimizedCommit := r.getOptimizedCommitForExport(commitFromPipeline)
+			// Obfuscated before merging, otherwise the emails of the merged commits would be exported in clear text
+			r.obfuscateCommit(&optimizedCommit)
 
-			for _, commitChangedFile := range commitFromPipeline.ChangedFiles {
-				if !contains(commitLanguages, commitChangedFile.Language) && commitChangedFile.Language != "" {
-					commitLanguages = append(commitLanguages, commitChangedFile.Language)
-				}
-				commitInsertions += commitChangedFile.Insertions
-				commitDeletions += commitChangedFile.Deletions
-			}
-
-			if _, index := commitContainsExistingDate(preparedCommitsDataForExport, commitDateStartHour.String()); index > -1 {
+			if _, index := commitContainsExistingDate(preparedCommitsDataForExport, optimizedCommit.Date); index > -1 && r.Aggregation != AggregationNone {
 				newLibraries := preparedCommitsDataForExport[index].Libraries
 
-				for newLibraryKey, newLibrary := range commitFromPipeline.Libraries {
+				for newLibraryKey, newLibrary := range optimizedCommit.Libraries {
 					if _, currentLibraryExists := newLibraries[newLibraryKey]; currentLibraryExists {
 						for _, libraryItem := range newLibrary {
 							if !contains(newLibraries[newLibraryKey], libraryItem) {
@@ -671,35 +1610,30 @@ loop:
 							}
 						}
 					} else {
-						preparedCommitsDataForExport[index].Libraries[newLibraryKey] = removeDuplicateStrings(newLibrary)
+						preparedCommitsDataForExport[index].Libraries[newLibraryKey] = newLibrary
+					}
+				}
+				for _, language := range optimizedCommit.Languages {
+					if !contains(preparedCommitsDataForExport[index].Languages, language) {
+						preparedCommitsDataForExport[index].Languages = append(preparedCommitsDataForExport[index].Languages, language)
 					}
 				}
+				preparedCommitsDataForExport[index].Messages = append(preparedCommitsDataForExport[index].Messages, optimizedCommit.Messages...)
 				preparedCommitsDataForExport[index].Commits += 1
-				preparedCommitsDataForExport[index].Deletions += commitDeletions
-				preparedCommitsDataForExport[index].Insertions += commitInsertions
+				preparedCommitsDataForExport[index].Deletions += optimizedCommit.Deletions
+				preparedCommitsDataForExport[index].Insertions += optimizedCommit.Insertions
+				preparedCommitsDataForExport[index].BinaryFiles += optimizedCommit.BinaryFiles
+				preparedCommitsDataForExport[index].FilesChanged += optimizedCommit.FilesChanged
+				preparedCommitsDataForExport[index].LanguageLines = addLanguageLines(preparedCommitsDataForExport[index].LanguageLines, optimizedCommit.LanguageLines)
 				preparedCommitsDataForExport[index].Libraries = newLibraries
-				preparedCommitsDataForExport[index].AuthorEmails = addUniqueEmailToCommitAuthorEmailsSlice(preparedCommitsDataForExport[index].AuthorEmails, commitFromPipeline.AuthorEmail)
-
-			} else {
-				librariesWithoutDuplicity := make(map[string][]string)
-				for libraryKey, library := range commitFromPipeline.Libraries {
-					librariesWithoutDuplicity[libraryKey] = removeDuplicateStrings(library)
+				if optimizedCommit.LibraryCounts != nil {
+					preparedCommitsDataForExport[index].LibraryCounts = addLibraryCounts(preparedCommitsDataForExport[index].LibraryCounts, optimizedCommit.LibraryCounts)
 				}
-				var authorEmails []string
-				authorEmails = append(authorEmails, commitFromPipeline.AuthorEmail)
-				optimizedCommit := commit.OptimizedCommitForExport{
-					AuthorEmails: authorEmails,
-					Date:         commitDateStartHour.String(),
-					Languages:    commitLanguages,
-					Libraries:    librariesWithoutDuplicity,
-					Insertions:   commitInsertions,
-					Deletions:    commitDeletions,
-					Commits:      1,
+				for _, authorEmail := range optimizedCommit.AuthorEmails {
+					preparedCommitsDataForExport[index].AuthorEmails = addUniqueEmailToCommitAuthorEmailsSlice(preparedCommitsDataForExport[index].AuthorEmails, authorEmail)
 				}
 
-				if r.HashImportant {
-					obfuscation.Obfuscate(&optimizedCommit)
-				}
+			} else {
 				preparedCommitsDataForExport = append(preparedCommitsDataForExport, optimizedCommit)
 			}
 
@@ -712,22 +1646,367 @@ loop:
 		return preparedCommitsDataForExport[i].Date < preparedCommitsDataForExport[j].Date
 	})
 
+	summary := exportSummary{Languages: mergeLanguageSummaries(r.previousSummary.Languages, languages), Partial: r.partial}
+	if r.IncludeActivity {
+		summary.Activity = getAuthorActivity(preparedCommitsDataForExport)
+	}
+
 	for preparedCommitsDataForExportItemIndex, preparedCommitsDataForExportItem := range preparedCommitsDataForExport {
-		commitData, err := json.Marshal(preparedCommitsDataForExportItem)
+		commitData, err := r.marshalJSONRecord(preparedCommitsDataForExportItem)
 		if err != nil {
-			fmt.Printf("Couldn't write commit day data to file. CommitDate: %s Error: %s", preparedCommitsDataForExportItem.Date, err.Error())
+			r.Logger.Error("Couldn't write commit day data to file", logger.Fields{"date": preparedCommitsDataForExportItem.Date, "error": err.Error()})
 			continue
 		}
 
-		fmt.Fprintln(w, string(commitData)+getCommitJSonSuffix(len(preparedCommitsDataForExport), preparedCommitsDataForExportItemIndex))
+		fmt.Fprintln(w, commitData+getCommitJSonSuffix(len(preparedCommitsDataForExport), preparedCommitsDataForExportItemIndex))
 	}
-	fmt.Fprintln(w, "]")
-	w.Flush() // important
-	file.Close()
+	r.writeJSONFooter(w, summary)
+}
 
-	fmt.Println("Exported!")
-	fmt.Printf("File is located in folder export (%v)\n", repoDataPath)
-	return nil
+// exportNDJSON writes one JSON object per line for every commit as soon as it leaves the pipeline
+// Unlike exportJSON, the commits are not aggregated per day, so nothing is held in memory.
+func (r *RepoExtractor) exportNDJSON(w *bufio.Writer) {
+	for {
+		select {
+		case commitFromPipeline := <-r.commitPipeline:
+			optimizedCommit := r.getOptimizedCommitForExport(commitFromPipeline)
+			r.obfuscateCommit(&optimizedCommit)
+
+			commitData, err := json.Marshal(optimizedCommit)
+			if err != nil {
+				r.Logger.Error("Couldn't write commit data to file", logger.Fields{"date": optimizedCommit.Date, "error": err.Error()})
+				continue
+			}
+			fmt.Fprintln(w, string(commitData))
+
+		case <-r.libraryExtractionCompleted:
+			return
+		}
+	}
+}
+
+// exportRaw writes every commit as a separate record, without merging the commits of the same day
+// The records are written as a JSON array, or one record per line with the ndjson output format.
+// The previous commits are the records of the previous export in case of incremental extraction.
+func (r *RepoExtractor) exportRaw(w *bufio.Writer, previousRawCommits []commit.RawCommitForExport) {
+	rawCommits := previousRawCommits
+	languages := map[string]LanguageSummary{}
+
+loop:
+	for {
+		select {
+		case commitFromPipeline := <-r.commitPipeline:
+			addToLanguageSummary(languages, commitFromPipeline)
+			rawCommit := r.getRawCommitForExport(commitFromPipeline)
+			r.obfuscateRawCommit(&rawCommit)
+
+			if r.OutputFormat != OutputFormatNDJSON {
+				rawCommits = append(rawCommits, rawCommit)
+				continue
+			}
+
+			commitData, err := json.Marshal(rawCommit)
+			if err != nil {
+				r.Logger.Error("Couldn't write commit data to file", logger.Fields{"hash": rawCommit.Hash, "error": err.Error()})
+				continue
+			}
+			fmt.Fprintln(w, string(commitData))
+
+		case <-r.libraryExtractionCompleted:
+			break loop
+		}
+	}
+
+	if r.OutputFormat == OutputFormatNDJSON {
+		return
+	}
+
+	sort.Slice(rawCommits, func(i, j int) bool {
+		return rawCommits[i].Date < rawCommits[j].Date
+	})
+
+	summary := exportSummary{Languages: mergeLanguageSummaries(r.previousSummary.Languages, languages), Partial: r.partial}
+	if r.IncludeActivity {
+		summary.Activity = getRawAuthorActivity(rawCommits)
+	}
+
+	r.writeJSONHeader(w)
+	for rawCommitIndex, rawCommit := range rawCommits {
+		commitData, err := r.marshalJSONRecord(rawCommit)
+		if err != nil {
+			r.Logger.Error("Couldn't write commit data to file", logger.Fields{"hash": rawCommit.Hash, "error": err.Error()})
+			continue
+		}
+
+		fmt.Fprintln(w, commitData+getCommitJSonSuffix(len(rawCommits), rawCommitIndex))
+	}
+	r.writeJSONFooter(w, summary)
+}
+
+// writeJSONHeader opens the envelope of the JSON export, up to the start of the commits array
+// The envelope looks like {"schemaVersion":1,"toolVersion":"v1.0.0","repo":"name","commits":[...]}
+func (r *RepoExtractor) writeJSONHeader(w *bufio.Writer) {
+	if r.LegacyFormat {
+		fmt.Fprintln(w, "[")
+		return
+	}
+
+	repoName := ""
+	if r.repo != nil {
+		repoName = r.repo.RepoName
+	}
+	toolVersion, _ := json.Marshal(r.ToolVersion)
+	repo, _ := json.Marshal(repoName)
+	if r.Pretty {
+		fmt.Fprintf(w, "{\n  \"schemaVersion\": %d,\n  \"toolVersion\": %s,\n  \"repo\": %s,\n  \"commits\": [\n", SchemaVersion, toolVersion, repo)
+		return
+	}
+	fmt.Fprintf(w, "{\"schemaVersion\":%d,\"toolVersion\":%s,\"repo\":%s,\"commits\":[\n", SchemaVersion, toolVersion, repo)
+}
+
+// exportSummary is written to the envelope of the JSON export after the commits
+// The empty fields are left out, e.g. the activity is only written if it is requested.
+type exportSummary struct {
+	Languages map[string]LanguageSummary `json:"languages,omitempty"`
+	Activity  map[string]AuthorActivity  `json:"activity,omitempty"`
+	Partial   *partialExport             `json:"partial,omitempty"`
+}
+
+// partialExport tells how many commits of the selected emails were analysed when the time limit was exceeded,
+// and how many of them are in the selected history
+type partialExport struct {
+	ProcessedCommits int `json:"processedCommits"`
+	TotalCommits     int `json:"totalCommits"`
+}
+
+// writeJSONFooter closes the commits array, and the envelope of the JSON export after the fields of the summary
+func (r *RepoExtractor) writeJSONFooter(w *bufio.Writer, summary exportSummary) {
+	if r.LegacyFormat {
+		fmt.Fprintln(w, "]")
+		return
+	}
+
+	var fields []byte
+	if r.Pretty {
+		fields, _ = json.MarshalIndent(summary, "", "  ")
+	} else {
+		fields, _ = json.Marshal(summary)
+	}
+	// The braces of the summary are removed, its fields are written into the envelope
+	fields = bytes.TrimSpace(fields[1 : len(fields)-1])
+
+	if r.Pretty {
+		if len(fields) > 0 {
+			fmt.Fprintf(w, "  ],\n  %s\n}\n", fields)
+			return
+		}
+		fmt.Fprintln(w, "  ]\n}")
+		return
+	}
+	if len(fields) > 0 {
+		fmt.Fprintf(w, "],%s}\n", fields)
+		return
+	}
+	fmt.Fprintln(w, "]}")
+}
+
+// marshalJSONRecord returns with a record of the commits array of the JSON export
+// With Pretty the record is indented to its depth in the envelope, or in the bare array of the legacy format.
+func (r *RepoExtractor) marshalJSONRecord(record interface{}) (string, error) {
+	if !r.Pretty {
+		data, err := json.Marshal(record)
+		return string(data), err
+	}
+
+	indent := "    "
+	if r.LegacyFormat {
+		indent = "  "
+	}
+	data, err := json.MarshalIndent(record, indent, "  ")
+	return indent + string(data), err
+}
+
+// shouldObfuscateEmails tells if the author emails have to be hashed in the export
+// The emails are only hashed on export, so the email selection still shows them in clear text.
+func (r *RepoExtractor) shouldObfuscateEmails() bool {
+	return r.HashImportant || r.ObfuscateEmails
+}
+
+// obfuscateCommit hashes the fields of the aggregated commit selected by the options
+func (r *RepoExtractor) obfuscateCommit(c *commit.OptimizedCommitForExport) {
+	if r.shouldObfuscateEmails() {
+		r.obfuscator.ObfuscateAuthorEmails(c)
+	}
+	if r.HashLibraries {
+		r.obfuscator.ObfuscateLibraries(c.Libraries)
+		r.obfuscator.ObfuscateLibraryCounts(c.LibraryCounts)
+	}
+}
+
+// obfuscateRawCommit hashes the fields of the non-aggregated commit selected by the options
+// HashImportant hashes the author names too, which are only exported in the raw commits.
+func (r *RepoExtractor) obfuscateRawCommit(c *commit.RawCommitForExport) {
+	if r.HashImportant {
+		r.obfuscator.ObfuscateRaw(c)
+	} else if r.ObfuscateEmails {
+		r.obfuscator.ObfuscateRawEmails(c)
+	}
+	if r.HashLibraries {
+		r.obfuscator.ObfuscateLibraries(c.Libraries)
+		r.obfuscator.ObfuscateLibraryCounts(c.LibraryCounts)
+	}
+}
+
+// getOptimizedCommitForExport converts a single commit into the exported format
+func (r *RepoExtractor) getOptimizedCommitForExport(c commit.Commit) commit.OptimizedCommitForExport {
+	commitDateStartHour := getAggregationStartFromStringDate(c.Date, r.Aggregation, r.UseAuthorTimezone)
+	commitLanguages, commitInsertions, commitDeletions := getCommitStats(c)
+
+	optimizedCommit := commit.OptimizedCommitForExport{
+		AuthorEmails:  append([]string{c.AuthorEmail}, getCoAuthorEmails(c)...),
+		Date:          commitDateStartHour.String(),
+		Languages:     commitLanguages,
+		Libraries:     getLibrariesWithoutDuplicity(c),
+		Insertions:    commitInsertions,
+		Deletions:     commitDeletions,
+		BinaryFiles:   getNumberOfBinaryFiles(c),
+		FilesChanged:  len(c.ChangedFiles),
+		LanguageLines: getLanguageLines(c),
+		Commits:       1,
+	}
+	if r.IncludeMessages {
+		optimizedCommit.Messages = []string{c.Message}
+	}
+	if r.IncludeLibraryCounts {
+		optimizedCommit.LibraryCounts = getLibraryCounts(c)
+	}
+	return optimizedCommit
+}
+
+// getRawCommitForExport converts a single commit into the non-aggregated export format
+func (r *RepoExtractor) getRawCommitForExport(c commit.Commit) commit.RawCommitForExport {
+	commitDate := getAggregationStartFromStringDate(c.Date, AggregationNone, r.UseAuthorTimezone)
+	commitLanguages, commitInsertions, commitDeletions := getCommitStats(c)
+
+	rawCommit := commit.RawCommitForExport{
+		Hash:           c.Hash,
+		AuthorName:     c.AuthorName,
+		AuthorEmail:    c.AuthorEmail,
+		CoAuthorEmails: getCoAuthorEmails(c),
+		Date:           commitDate.String(),
+		Languages:      commitLanguages,
+		Libraries:      getLibrariesWithoutDuplicity(c),
+		Insertions:     commitInsertions,
+		Deletions:      commitDeletions,
+		BinaryFiles:    getNumberOfBinaryFiles(c),
+		FilesChanged:   len(c.ChangedFiles),
+		LanguageLines:  getLanguageLines(c),
+	}
+	if r.IncludeMessages {
+		rawCommit.Message = c.Message
+	}
+	if r.IncludeLibraryCounts {
+		rawCommit.LibraryCounts = getLibraryCounts(c)
+	}
+	return rawCommit
+}
+
+func getNumberOfBinaryFiles(c commit.Commit) int {
+	binaryFiles := 0
+	for _, changedFile := range c.ChangedFiles {
+		if changedFile.Binary {
+			binaryFiles++
+		}
+	}
+	return binaryFiles
+}
+
+func getCoAuthorEmails(c commit.Commit) []string {
+	var emails []string
+	for _, coAuthor := range c.CoAuthors {
+		emails = append(emails, coAuthor.Email)
+	}
+	return emails
+}
+
+// getCommitStats returns with the languages and the number of inserted and deleted lines of the commit
+func getCommitStats(c commit.Commit) (languages []string, insertions, deletions int) {
+	for _, commitChangedFile := range c.ChangedFiles {
+		if !contains(languages, commitChangedFile.Language) && commitChangedFile.Language != "" {
+			languages = append(languages, commitChangedFile.Language)
+		}
+		insertions += commitChangedFile.Insertions
+		deletions += commitChangedFile.Deletions
+	}
+	return languages, insertions, deletions
+}
+
+// getLanguageLines returns with the inserted and deleted lines of the languages of the commit
+func getLanguageLines(c commit.Commit) map[string]commit.LanguageLines {
+	languageLines := make(map[string]commit.LanguageLines)
+	for _, changedFile := range c.ChangedFiles {
+		if changedFile.Language == "" {
+			continue
+		}
+		lines := languageLines[changedFile.Language]
+		lines.Insertions += changedFile.Insertions
+		lines.Deletions += changedFile.Deletions
+		languageLines[changedFile.Language] = lines
+	}
+	return languageLines
+}
+
+// addLanguageLines adds the changed lines of the other commit to the lines of the aggregated commit
+// The exports of the versions before the language lines don't have them, so the map might be nil.
+func addLanguageLines(languageLines, other map[string]commit.LanguageLines) map[string]commit.LanguageLines {
+	if languageLines == nil {
+		languageLines = make(map[string]commit.LanguageLines, len(other))
+	}
+	for language, otherLines := range other {
+		lines := languageLines[language]
+		lines.Insertions += otherLines.Insertions
+		lines.Deletions += otherLines.Deletions
+		languageLines[language] = lines
+	}
+	return languageLines
+}
+
+func getLibrariesWithoutDuplicity(c commit.Commit) map[string][]string {
+	librariesWithoutDuplicity := make(map[string][]string)
+	for libraryKey, library := range c.Libraries {
+		librariesWithoutDuplicity[libraryKey] = removeDuplicateStrings(library)
+	}
+	return librariesWithoutDuplicity
+}
+
+// getLibraryCounts returns with the number of the changed files using the libraries by language
+func getLibraryCounts(c commit.Commit) map[string]map[string]int {
+	libraryCounts := make(map[string]map[string]int, len(c.Libraries))
+	for language, libraries := range c.Libraries {
+		counts := make(map[string]int, len(libraries))
+		for _, library := range libraries {
+			counts[library]++
+		}
+		libraryCounts[language] = counts
+	}
+	return libraryCounts
+}
+
+// addLibraryCounts adds the library counts of the other commit to the counts of the aggregated commit
+// The records of the previous exports without the counts don't have them, so the map might be nil.
+func addLibraryCounts(libraryCounts, other map[string]map[string]int) map[string]map[string]int {
+	if libraryCounts == nil {
+		libraryCounts = make(map[string]map[string]int, len(other))
+	}
+	for language, otherCounts := range other {
+		if libraryCounts[language] == nil {
+			libraryCounts[language] = make(map[string]int, len(otherCounts))
+		}
+		for library, count := range otherCounts {
+			libraryCounts[language][library] += count
+		}
+	}
+	return libraryCounts
 }
 
 type repo struct {
@@ -735,8 +2014,3 @@ type repo struct {
 	Emails          []string `json:"emails"`
 	SuggestedEmails []string `json:"suggestedEmails"`
 }
-
-type req struct {
-	Limit  int
-	Offset int
-}
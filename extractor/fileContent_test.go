@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetFileContent", func() {
+	var tempDir string
+
+	// newFakeGit creates a script which behaves like git show exiting with the given stderr and exit code,
+	// and like git ls-tree listing the given output
+	newFakeGit := func(stderr string, exitCode string, lsTree string) string {
+		gitPath := filepath.Join(tempDir, "git")
+		script := "#!/bin/sh\ncase \"$*\" in *ls-tree*) printf '" + lsTree + "'; exit 0;; esac\n" +
+			"echo \"" + stderr + "\" >&2\nexit " + exitCode + "\n"
+		err := ioutil.WriteFile(gitPath, []byte(script), 0755)
+		Expect(err).To(BeNil())
+		return gitPath
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "extractor_git_")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should return empty content for deleted files regardless of the git locale", func() {
+		r := RepoExtractor{
+			RepoPath: tempDir,
+			GitPath:  newFakeGit("fatal: Pfad 'main.go' existiert nicht in 'abc123'", "128", ""),
+		}
+
+		content, err := r.getFileContent("abc123", "main.go")
+
+		Expect(err).To(BeNil())
+		Expect(content).To(BeEmpty())
+	})
+
+	It("should return an error for other failures", func() {
+		r := RepoExtractor{
+			RepoPath: tempDir,
+			GitPath:  newFakeGit("error: something went wrong", "1", "main.go"),
+		}
+
+		_, err := r.getFileContent("abc123", "main.go")
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should return an error for the other fatal errors if the file exists", func() {
+		r := RepoExtractor{
+			RepoPath: tempDir,
+			GitPath:  newFakeGit("fatal: loose object abc123 is corrupt", "128", "main.go"),
+		}
+
+		_, err := r.getFileContent("abc123", "main.go")
+
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("should return an error if the commit cannot be read", func() {
+		repo := newFixtureRepo()
+		defer repo.remove()
+		repo.commit("main.go", "package main\n")
+		r := repo.extractor()
+
+		_, err := r.getFileContent("0000000000000000000000000000000000000000", "main.go")
+		Expect(err).NotTo(BeNil())
+
+		content, err := r.getFileContent("HEAD", "deleted.go")
+		Expect(err).To(BeNil())
+		Expect(content).To(BeEmpty())
+	})
+})
@@ -0,0 +1,99 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/commit"
+)
+
+// fixtureRepo is a temporary git repository used by the tests
+type fixtureRepo struct {
+	path string
+}
+
+// newFixtureRepo creates an empty git repository in a temporary directory
+func newFixtureRepo() *fixtureRepo {
+	path, err := ioutil.TempDir("", "extractor_fixture_")
+	Expect(err).To(BeNil())
+	repo := &fixtureRepo{path: path}
+	repo.git("init", "--quiet")
+	return repo
+}
+
+// git runs a git command in the repository and returns with its output
+func (f *fixtureRepo) git(args ...string) string {
+	args = append([]string{"-c", "user.name=Developer", "-c", "user.email=developer@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = f.path
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_DATE=2021-03-18T10:00:00+01:00",
+		"GIT_COMMITTER_DATE=2021-03-18T10:00:00+01:00",
+	)
+	out, err := cmd.CombinedOutput()
+	Expect(err).To(BeNil(), string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes the file and commits it
+func (f *fixtureRepo) commit(fileName, content string) {
+	filePath := filepath.Join(f.path, fileName)
+	Expect(os.MkdirAll(filepath.Dir(filePath), 0755)).To(BeNil())
+	Expect(ioutil.WriteFile(filePath, []byte(content), 0644)).To(BeNil())
+	f.git("add", "--all")
+	f.git("commit", "--quiet", "-m", "Change "+fileName)
+}
+
+// extractor returns with a RepoExtractor for the repository
+func (f *fixtureRepo) extractor() *RepoExtractor {
+	gitPath, err := exec.LookPath("git")
+	Expect(err).To(BeNil())
+	return &RepoExtractor{
+		RepoPath: f.path,
+		GitPath:  gitPath,
+	}
+}
+
+func (f *fixtureRepo) remove() {
+	os.RemoveAll(f.path)
+}
+
+// getCommits returns with every commit which is analysed by the extractor
+func getCommits(r *RepoExtractor) ([]*commit.Commit, error) {
+	var commits []*commit.Commit
+	err := r.streamCommits(context.Background(), true, func(c *commit.Commit) {
+		commits = append(commits, c)
+	})
+	return commits, err
+}
+
+// analyseCommitLibraries runs the library detection on every commit and returns with the analysed commits
+func analyseCommitLibraries(r *RepoExtractor) []commit.Commit {
+	commits, err := getCommits(r)
+	Expect(err).To(BeNil())
+
+	r.initAnalyzers()
+	r.blobCache = newBlobCache()
+	r.commitPipeline = make(chan commit.Commit)
+	jobs := make(chan *commit.Commit, len(commits))
+	for _, c := range commits {
+		jobs <- c
+	}
+	close(jobs)
+	go func() {
+		r.libraryWorker(context.Background(), jobs)
+		close(r.commitPipeline)
+	}()
+
+	var analysedCommits []commit.Commit
+	for c := range r.commitPipeline {
+		analysedCommits = append(analysedCommits, c)
+	}
+	return analysedCommits
+}
@@ -0,0 +1,47 @@
+package extractor
+
+import (
+	"regexp"
+)
+
+// DefaultGeneratedPaths are the glob patterns of the files generated by the common code generators
+// The minified bundles like "*.min.js" are in DefaultExcludePaths, they are not analysed at all.
+var DefaultGeneratedPaths = []string{
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*.pb.cc",
+	"*.pb.h",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"*_pb.js",
+	"*_pb.d.ts",
+	"*_generated.*",
+	"*.generated.*",
+	"*.g.dart",
+	"*.freezed.dart",
+	"*.Designer.cs",
+}
+
+// generatedHeaderSize is the size of the beginning of the files which is searched for the generated header
+const generatedHeaderSize = 1024
+
+// generatedHeaderRegex matches the comments of the generators, like "// Code generated by protoc-gen-go. DO NOT EDIT."
+// of Go, "@generated" of Facebook's tools, "<auto-generated>" of .NET or "Generated by: https://openapi-generator.tech"
+var generatedHeaderRegex = regexp.MustCompile(`\bDO NOT EDIT\b|@generated\b|<auto-generated|\b[Aa]uto-?generated\b|[Gg]enerated by:? .*(?:[Ss]wagger|[Oo]pen[Aa][Pp][Ii])`)
+
+// isGeneratedPath checks if the file name matches the patterns of the generated files
+func (r *RepoExtractor) isGeneratedPath(filePath string) bool {
+	patterns := r.GeneratedPaths
+	if patterns == nil {
+		patterns = DefaultGeneratedPaths
+	}
+	return isExcludedPath(filePath, patterns)
+}
+
+// isGeneratedContent checks if the beginning of the file has the comment of a code generator
+func isGeneratedContent(contents []byte) bool {
+	if len(contents) > generatedHeaderSize {
+		contents = contents[:generatedHeaderSize]
+	}
+	return generatedHeaderRegex.Match(contents)
+}
@@ -0,0 +1,73 @@
+package extractor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generated", func() {
+	It("should match the names of the generated files", func() {
+		r := &RepoExtractor{}
+		Expect(r.isGeneratedPath("api/user.pb.go")).To(BeTrue())
+		Expect(r.isGeneratedPath("api/user_pb2.py")).To(BeTrue())
+		Expect(r.isGeneratedPath("src/schema_generated.ts")).To(BeTrue())
+		Expect(r.isGeneratedPath("lib/user.g.dart")).To(BeTrue())
+		Expect(r.isGeneratedPath("api/user.go")).To(BeFalse())
+
+		r.GeneratedPaths = []string{"gen/"}
+		Expect(r.isGeneratedPath("gen/client.go")).To(BeTrue())
+		Expect(r.isGeneratedPath("api/user.pb.go")).To(BeFalse())
+	})
+
+	It("should tell the generated files by their header", func() {
+		Expect(isGeneratedContent([]byte("// Code generated by mockgen. DO NOT EDIT.\n\npackage mocks\n"))).To(BeTrue())
+		Expect(isGeneratedContent([]byte("// @generated by the relay compiler\n"))).To(BeTrue())
+		Expect(isGeneratedContent([]byte("/**\n * Pet Store\n * Generated by: https://openapi-generator.tech\n */\n"))).To(BeTrue())
+		Expect(isGeneratedContent([]byte("package main\n\nimport \"fmt\"\n"))).To(BeFalse())
+
+		// Only the beginning of the file is checked
+		Expect(isGeneratedContent(append(make([]byte, generatedHeaderSize), "// DO NOT EDIT"...))).To(BeFalse())
+	})
+
+	It("should skip the generated files", func() {
+		fixture := newFixtureRepo()
+		defer fixture.remove()
+		fixture.commit("main.go", "package main\n\nimport \"fmt\"\n")
+		fixture.commit("user.pb.go", "package main\n\nimport \"google.golang.org/protobuf/proto\"\n")
+		fixture.commit("client.go", "// Code generated by swagger. DO NOT EDIT.\n\npackage main\n\nimport \"github.com/go-openapi/runtime\"\n")
+
+		r := fixture.extractor()
+		var paths []string
+		var libraries []string
+		for _, c := range analyseCommitLibraries(r) {
+			for _, changedFile := range c.ChangedFiles {
+				paths = append(paths, changedFile.Path)
+			}
+			libraries = append(libraries, c.Libraries["Go"]...)
+		}
+		Expect(paths).To(ConsistOf("main.go"))
+		Expect(libraries).To(ConsistOf("fmt"))
+	})
+
+	It("should count the lines of the generated files if requested", func() {
+		fixture := newFixtureRepo()
+		defer fixture.remove()
+		fixture.commit("main.go", "package main\n\nimport \"fmt\"\n")
+		fixture.commit("user.pb.go", "package main\n\nimport \"google.golang.org/protobuf/proto\"\n")
+		fixture.commit("client.go", "// Code generated by swagger. DO NOT EDIT.\n\npackage main\n\nimport \"github.com/go-openapi/runtime\"\n")
+
+		r := fixture.extractor()
+		r.CountGeneratedFiles = true
+		var paths []string
+		var libraries []string
+		for _, c := range analyseCommitLibraries(r) {
+			for _, changedFile := range c.ChangedFiles {
+				paths = append(paths, changedFile.Path)
+				Expect(changedFile.Language).To(Equal("Go"))
+			}
+			libraries = append(libraries, c.Libraries["Go"]...)
+		}
+		Expect(paths).To(ConsistOf("main.go", "user.pb.go", "client.go"))
+		Expect(libraries).To(ConsistOf("fmt"))
+	})
+})
@@ -0,0 +1,127 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+// MinGitVersion is the oldest supported version of git
+// git log formats the author dates in strict ISO 8601 (%aI) since 2.2.0.
+var MinGitVersion = gitVersion{2, 2, 0}
+
+var (
+	// ErrGitNotFound is returned if the git executable cannot be run
+	ErrGitNotFound = errors.New("git is not found")
+	// ErrGitTooOld is returned if git is older than MinGitVersion
+	ErrGitTooOld = errors.New("git is too old")
+	// ErrNotGitRepository is returned if the repo path is not a git repository
+	ErrNotGitRepository = errors.New("not a git repository")
+	// ErrInvalidOption is returned if an option set by the user is invalid, like an unknown output format
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// gitVersion is the major, minor and patch version of git
+type gitVersion [3]int
+
+func (v gitVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// olderThan tells if the version is older than the other one
+func (v gitVersion) olderThan(other gitVersion) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] < other[i]
+		}
+	}
+	return false
+}
+
+// gitVersionRegex matches the version in the output of git --version, like "git version 2.30.1 (Apple Git-130)"
+// or "git version 2.31.1.windows.1"
+var gitVersionRegex = regexp.MustCompile(`git version (\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseGitVersion returns with the version in the output of git --version
+func parseGitVersion(output string) (gitVersion, error) {
+	match := gitVersionRegex.FindStringSubmatch(output)
+	if match == nil {
+		return gitVersion{}, fmt.Errorf("unknown git version: %s", strings.TrimSpace(output))
+	}
+	var version gitVersion
+	for i := range version {
+		if match[i+1] != "" {
+			version[i], _ = strconv.Atoi(match[i+1])
+		}
+	}
+	return version, nil
+}
+
+// defaultGitPath is tried if git is not in the PATH
+const defaultGitPath = "/usr/bin/git"
+
+// FindGit returns with the path of the git executable
+// If the path is not given, git is looked up in the PATH, then at the default /usr/bin/git.
+func FindGit(gitPath string) (string, error) {
+	if gitPath != "" {
+		path, err := exec.LookPath(gitPath)
+		if err != nil {
+			return "", fmt.Errorf("%w at %s: %s", ErrGitNotFound, gitPath, err.Error())
+		}
+		return path, nil
+	}
+
+	for _, candidate := range []string{"git", defaultGitPath} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w in the PATH or at %s, set its path with --git_path", ErrGitNotFound, defaultGitPath)
+}
+
+// checkGit verifies that git can be run and it is not older than MinGitVersion
+// If GitPath is not set, it is set to the git found by FindGit.
+// The version is only logged if it cannot be parsed, as the unusual builds of git might still work.
+func (r *RepoExtractor) checkGit() error {
+	gitPath, err := FindGit(r.GitPath)
+	if err != nil {
+		return err
+	}
+	r.GitPath = gitPath
+
+	out, err := exec.Command(r.GitPath, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("%w, %s cannot be run: %s", ErrGitNotFound, r.GitPath, err.Error())
+	}
+
+	version, err := parseGitVersion(string(out))
+	if err != nil {
+		r.Logger.Warning("Cannot check the version of git", logger.Fields{"error": err.Error()})
+		return nil
+	}
+	if version.olderThan(MinGitVersion) {
+		return fmt.Errorf("%w: version %s is installed, at least %s is needed", ErrGitTooOld, version, MinGitVersion)
+	}
+	return nil
+}
+
+// checkRepository verifies that the repo path is a git repository, a working tree or a bare one
+func (r *RepoExtractor) checkRepository() error {
+	cmd := exec.Command(r.GitPath, "rev-parse", "--git-dir")
+	cmd.Dir = r.RepoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		// The output of git tells the reason, the error only if git couldn't even start, like a missing directory
+		reason := strings.TrimSpace(string(out))
+		if reason == "" {
+			reason = err.Error()
+		}
+		return fmt.Errorf("%w: %s: %s", ErrNotGitRepository, r.RepoPath, reason)
+	}
+	return nil
+}
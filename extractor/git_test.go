@@ -0,0 +1,89 @@
+package extractor
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseGitVersion", func() {
+	It("should parse the version of the different builds of git", func() {
+		Expect(parseGitVersion("git version 2.30.1\n")).To(Equal(gitVersion{2, 30, 1}))
+		Expect(parseGitVersion("git version 2.30.1 (Apple Git-130)\n")).To(Equal(gitVersion{2, 30, 1}))
+		Expect(parseGitVersion("git version 2.31.1.windows.1\n")).To(Equal(gitVersion{2, 31, 1}))
+		Expect(parseGitVersion("git version 2.40\n")).To(Equal(gitVersion{2, 40, 0}))
+
+		_, err := parseGitVersion("hub version 2.14.2\n")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should compare the versions", func() {
+		Expect(gitVersion{1, 9, 5}.olderThan(MinGitVersion)).To(BeTrue())
+		Expect(gitVersion{2, 1, 4}.olderThan(MinGitVersion)).To(BeTrue())
+		Expect(gitVersion{2, 2, 0}.olderThan(MinGitVersion)).To(BeFalse())
+		Expect(gitVersion{2, 10, 0}.olderThan(MinGitVersion)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Preflight", func() {
+	var fixture *fixtureRepo
+
+	BeforeEach(func() {
+		fixture = newFixtureRepo()
+		fixture.commit("main.go", "package main\n")
+	})
+
+	AfterEach(func() {
+		fixture.remove()
+	})
+
+	It("should accept the installed git and the repository", func() {
+		r := fixture.extractor()
+		Expect(r.checkGit()).To(Succeed())
+		Expect(r.checkRepository()).To(Succeed())
+	})
+
+	It("should look up git in the PATH if its path is not set", func() {
+		r := fixture.extractor()
+		gitPath := r.GitPath
+		r.GitPath = ""
+
+		Expect(r.checkGit()).To(Succeed())
+		Expect(r.GitPath).To(Equal(gitPath))
+	})
+
+	It("should fail if git is not found", func() {
+		r := fixture.extractor()
+		r.GitPath = filepath.Join(fixture.path, "git")
+
+		err := r.checkGit()
+		Expect(errors.Is(err, ErrGitNotFound)).To(BeTrue())
+	})
+
+	It("should fail if git is too old", func() {
+		r := fixture.extractor()
+		r.GitPath = filepath.Join(fixture.path, "old-git")
+		Expect(ioutil.WriteFile(r.GitPath, []byte("#!/bin/sh\necho 'git version 1.8.3.1'\n"), 0755)).To(Succeed())
+
+		err := r.checkGit()
+		Expect(errors.Is(err, ErrGitTooOld)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("1.8.3"))
+	})
+
+	It("should fail if the path is not a repository", func() {
+		dir, err := ioutil.TempDir("", "extractor_not_repo_")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		r := fixture.extractor()
+		r.RepoPath = dir
+		Expect(errors.Is(r.checkRepository(), ErrNotGitRepository)).To(BeTrue())
+
+		r.RepoPath = filepath.Join(dir, "missing")
+		Expect(errors.Is(r.checkRepository(), ErrNotGitRepository)).To(BeTrue())
+	})
+})
@@ -0,0 +1,192 @@
+package extractor
+
+import (
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+// gitignore is the ignore rules of the .gitignore files of the repository
+// The committed files are not ignored by git, but the build artifacts committed by mistake can be excluded
+// from the analysis with the same rules.
+type gitignore struct {
+	rules []gitignoreRule
+}
+
+// gitignoreRule is a pattern of a .gitignore file
+type gitignoreRule struct {
+	// dir is the directory of the .gitignore file, the pattern only matches the paths under it
+	dir     string
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	// anchored patterns, which have a slash, are matched against the path relative to dir, the others against the name
+	anchored bool
+}
+
+// loadGitignore reads the .gitignore files of the HEAD commit, so the current rules are applied to the whole history
+// The repository might have no commit or no .gitignore file, then nothing is ignored.
+func (r *RepoExtractor) loadGitignore() *gitignore {
+	cmd := exec.Command(r.GitPath, "ls-tree", "-r", "-z", "--name-only", "HEAD")
+	cmd.Dir = r.RepoPath
+	out, err := cmd.Output()
+	if err != nil {
+		r.Logger.Warning("Cannot list the .gitignore files, nothing is ignored", logger.Fields{"error": err.Error()})
+		return nil
+	}
+
+	var filePaths []string
+	for _, filePath := range strings.Split(string(out), "\x00") {
+		if path.Base(filePath) == ".gitignore" {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	// The rules of the parent directories come first, so the rules of the nested .gitignore files override them
+	sort.SliceStable(filePaths, func(i, j int) bool {
+		return strings.Count(filePaths[i], "/") < strings.Count(filePaths[j], "/")
+	})
+
+	ignore := &gitignore{}
+	for _, filePath := range filePaths {
+		content, err := r.getFileContent("HEAD", filePath)
+		if err != nil {
+			r.Logger.Warning("Cannot read .gitignore file", logger.Fields{"path": filePath, "error": err.Error()})
+			continue
+		}
+		dir := path.Dir(filePath)
+		if dir == "." {
+			dir = ""
+		}
+		ignore.rules = append(ignore.rules, parseGitignore(dir, string(content))...)
+	}
+	return ignore
+}
+
+// parseGitignore returns with the rules of a .gitignore file in the directory
+func parseGitignore(dir, content string) []gitignoreRule {
+	var rules []gitignoreRule
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// Trailing spaces are ignored unless they are escaped
+		if !strings.HasSuffix(line, "\\ ") {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := gitignoreRule{dir: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.regex = gitignorePatternRegex(line)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// gitignorePatternRegex converts the wildcards of a .gitignore pattern to a regular expression
+// "*" and "?" don't match slashes, "**" matches any number of directories.
+func gitignorePatternRegex(pattern string) *regexp.Regexp {
+	var res strings.Builder
+	res.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			res.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			res.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			res.WriteString(".*")
+			i++
+		case c == '*':
+			res.WriteString("[^/]*")
+		case c == '?':
+			res.WriteString("[^/]")
+		case c == '[':
+			end := strings.Index(pattern[i+1:], "]")
+			if end == -1 {
+				res.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			res.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			res.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			res.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	res.WriteString("$")
+	regex, err := regexp.Compile(res.String())
+	if err != nil {
+		// Malformed patterns never match
+		return regexp.MustCompile(`[^\s\S]`)
+	}
+	return regex
+}
+
+// ignores tells if the file is ignored by the rules
+// Like in git, the files in an ignored directory cannot be re-included by a negated pattern.
+func (g *gitignore) ignores(filePath string) bool {
+	if g == nil || len(g.rules) == 0 {
+		return false
+	}
+	segments := strings.Split(filePath, "/")
+	for i := 1; i < len(segments); i++ {
+		if g.matches(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return g.matches(filePath, false)
+}
+
+// matches returns with the result of the last matching rule, a path is not ignored if no rule matches
+func (g *gitignore) matches(filePath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range g.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		relativePath := filePath
+		if rule.dir != "" {
+			if !strings.HasPrefix(filePath, rule.dir+"/") {
+				continue
+			}
+			relativePath = filePath[len(rule.dir)+1:]
+		}
+		name := relativePath
+		if !rule.anchored {
+			name = path.Base(relativePath)
+		}
+		if rule.regex.MatchString(name) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
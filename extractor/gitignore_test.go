@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Gitignore", func() {
+	It("should match the patterns like git", func() {
+		ignore := &gitignore{rules: parseGitignore("", `# build output
+dist/
+*.log
+!important.log
+/coverage.out
+docs/**/*.html
+build-?.tar
+\#notes
+`)}
+
+		Expect(ignore.ignores("dist/app.js")).To(BeTrue())
+		Expect(ignore.ignores("web/dist/app.js")).To(BeTrue())
+		Expect(ignore.ignores("dist")).To(BeFalse())
+		Expect(ignore.ignores("server.log")).To(BeTrue())
+		Expect(ignore.ignores("logs/server.log")).To(BeTrue())
+		Expect(ignore.ignores("important.log")).To(BeFalse())
+		Expect(ignore.ignores("coverage.out")).To(BeTrue())
+		Expect(ignore.ignores("cmd/coverage.out")).To(BeFalse())
+		Expect(ignore.ignores("docs/index.html")).To(BeTrue())
+		Expect(ignore.ignores("docs/api/v1/index.html")).To(BeTrue())
+		Expect(ignore.ignores("web/docs/index.html")).To(BeFalse())
+		Expect(ignore.ignores("build-1.tar")).To(BeTrue())
+		Expect(ignore.ignores("build-10.tar")).To(BeFalse())
+		Expect(ignore.ignores("#notes")).To(BeTrue())
+		Expect(ignore.ignores("main.go")).To(BeFalse())
+	})
+
+	It("should not re-include the files of an ignored directory", func() {
+		ignore := &gitignore{rules: parseGitignore("", "generated/\n!generated/keep.go\n")}
+
+		Expect(ignore.ignores("generated/keep.go")).To(BeTrue())
+	})
+
+	It("should only apply the rules of a nested .gitignore file under its directory", func() {
+		var rules []gitignoreRule
+		rules = append(rules, parseGitignore("", "*.tmp\n")...)
+		rules = append(rules, parseGitignore("web", "/public\n!keep.tmp\n")...)
+		ignore := &gitignore{rules: rules}
+
+		Expect(ignore.ignores("web/public/app.js")).To(BeTrue())
+		Expect(ignore.ignores("public/app.js")).To(BeFalse())
+		Expect(ignore.ignores("web/keep.tmp")).To(BeFalse())
+		Expect(ignore.ignores("keep.tmp")).To(BeTrue())
+	})
+
+	It("should not ignore anything without rules", func() {
+		var ignore *gitignore
+		Expect(ignore.ignores("dist/app.js")).To(BeFalse())
+	})
+
+	It("should skip the committed files matching the .gitignore files of the repository", func() {
+		fixture := newFixtureRepo()
+		defer fixture.remove()
+		fixture.commit(".gitignore", "dist/\n")
+		fixture.commit("main.go", "package main\n\nimport \"fmt\"\n")
+		// The ignored files can only be committed by force
+		Expect(os.MkdirAll(filepath.Join(fixture.path, "web", "dist"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(fixture.path, "web", "dist", "bundle.js"), []byte("require('lodash')\n"), 0644)).To(Succeed())
+		fixture.git("add", "--force", "web/dist/bundle.js")
+		fixture.git("commit", "--quiet", "-m", "Commit the bundle")
+
+		r := fixture.extractor()
+		r.RespectGitignore = true
+		r.gitignore = r.loadGitignore()
+
+		var paths []string
+		for _, c := range analyseCommitLibraries(r) {
+			for _, changedFile := range c.ChangedFiles {
+				paths = append(paths, changedFile.Path)
+			}
+		}
+		Expect(paths).To(ConsistOf(".gitignore", "main.go"))
+	})
+})
@@ -0,0 +1,65 @@
+package extractor
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+// hashMap is the side-car file of the hashed values, so the authorized users can reverse the hashes of the export
+type hashMap struct {
+	Algorithm string `json:"algorithm"`
+	// Fingerprint is the hash of the empty text, the hashes of a different salt or algorithm are not merged
+	Fingerprint string `json:"fingerprint"`
+	// Hashes are the hashes by the original values
+	Hashes map[string]string `json:"hashes"`
+}
+
+// writeHashMap writes the values hashed during the export with their hashes to HashMapOut
+// The hashes of the previous extractions, like the other repositories of the same run, are kept if they were created
+// with the same algorithm and salt. The file is only readable by the owner.
+func (r *RepoExtractor) writeHashMap() error {
+	if r.HashMapOut == "" {
+		return nil
+	}
+
+	hashes := map[string]string{}
+	fingerprint := r.obfuscator.Fingerprint()
+	var previous hashMap
+	content, err := ioutil.ReadFile(r.HashMapOut)
+	if err == nil && json.Unmarshal(content, &previous) == nil {
+		if previous.Fingerprint == fingerprint {
+			hashes = previous.Hashes
+		} else {
+			r.Logger.Warning("The hash map was created with another algorithm or salt, it is overwritten", logger.Fields{"path": r.HashMapOut})
+		}
+	}
+	for text, hashed := range r.obfuscator.Hashes() {
+		hashes[text] = hashed
+	}
+
+	data, err := json.MarshalIndent(hashMap{
+		Algorithm:   r.obfuscator.Algorithm(),
+		Fingerprint: fingerprint,
+		Hashes:      hashes,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomically(r.HashMapOut, 0600, false, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	r.Logger.Warning("The hash map contains the original values of the hashes, keep it secret", logger.Fields{"path": r.HashMapOut})
+	return nil
+}
+
+// isHashing tells if any value is hashed in the export
+func (r *RepoExtractor) isHashing() bool {
+	return r.shouldObfuscateEmails() || r.HashLibraries
+}
@@ -0,0 +1,81 @@
+package extractor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/logger"
+	"github.com/Techloopio/extractor_tool/obfuscation"
+)
+
+var _ = Describe("HashMap", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "extractor_hash_map_")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	newExtractor := func(salt string) *RepoExtractor {
+		r := &RepoExtractor{
+			HashLibraries: true,
+			HashAlgorithm: obfuscation.AlgorithmSHA256,
+			HashSalt:      salt,
+			HashMapOut:    filepath.Join(dir, "hashes.json"),
+		}
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(r.validateOptions()).To(Succeed())
+		return r
+	}
+
+	readHashMap := func() hashMap {
+		content, err := ioutil.ReadFile(filepath.Join(dir, "hashes.json"))
+		Expect(err).ToNot(HaveOccurred())
+		var hashes hashMap
+		Expect(json.Unmarshal(content, &hashes)).To(Succeed())
+		return hashes
+	}
+
+	It("should write the hashed values with their hashes", func() {
+		r := newExtractor("salt")
+		hashed := r.obfuscator.Hash("github.com/acme/billing")
+		Expect(r.writeHashMap()).To(Succeed())
+
+		hashes := readHashMap()
+		Expect(hashes.Algorithm).To(Equal(obfuscation.AlgorithmSHA256))
+		Expect(hashes.Hashes).To(Equal(map[string]string{"github.com/acme/billing": hashed}))
+		info, err := os.Stat(filepath.Join(dir, "hashes.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("should keep the hashes of the previous extractions with the same salt", func() {
+		first := newExtractor("salt")
+		first.obfuscator.Hash("github.com/acme/billing")
+		Expect(first.writeHashMap()).To(Succeed())
+		second := newExtractor("salt")
+		second.obfuscator.Hash("github.com/acme/auth")
+		Expect(second.writeHashMap()).To(Succeed())
+		Expect(readHashMap().Hashes).To(HaveLen(2))
+
+		other := newExtractor("other-salt")
+		hashed := other.obfuscator.Hash("github.com/acme/auth")
+		Expect(other.writeHashMap()).To(Succeed())
+		Expect(readHashMap().Hashes).To(Equal(map[string]string{"github.com/acme/auth": hashed}))
+	})
+
+	It("should need hashing", func() {
+		r := &RepoExtractor{HashMapOut: filepath.Join(dir, "hashes.json")}
+		Expect(r.validateOptions()).ToNot(Succeed())
+	})
+})
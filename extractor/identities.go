@@ -0,0 +1,185 @@
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+// identities maps the emails of the same person to one email, so the stats of a person are not split across
+// the emails of the work laptop, the personal one or the CI
+// The emails are compared case-insensitively.
+type identities struct {
+	emails map[string]string // Canonical emails by alias email
+	// Canonical emails by domain, like "users.noreply.github.com". The empty canonical email means the emails
+	// of the domain are mapped by the name of the author, see byName.
+	domains map[string]string
+	byName  map[string]string // Canonical emails of the domains mapped by name, by the lower-case name of the author
+	// If it is true the GitHub noreply emails with the ID of the user, like "1234+octocat@users.noreply.github.com",
+	// are replaced with the emails without the ID, like "octocat@users.noreply.github.com", before the rules
+	githubNoreply bool
+}
+
+// githubNoreplyRegex matches the noreply emails of the commits made on GitHub, with the ID and the name of the user
+var githubNoreplyRegex = regexp.MustCompile(`^\d+\+([^@]+)@users\.noreply\.github\.com$`)
+
+// parseIdentities parses the identity rules like "me@home.com=me@work.com", "*@home.com=me@work.com" or
+// "*@users.noreply.github.com", which maps the emails of the domain to the other email of the author with the same name
+func parseIdentities(rules []string, githubNoreply bool) (*identities, error) {
+	if len(rules) == 0 && !githubNoreply {
+		return nil, nil
+	}
+	ids := &identities{
+		emails:        map[string]string{},
+		domains:       map[string]string{},
+		byName:        map[string]string{},
+		githubNoreply: githubNoreply,
+	}
+	for _, rule := range rules {
+		parts := strings.SplitN(rule, "=", 2)
+		alias := strings.ToLower(strings.TrimSpace(parts[0]))
+		canonical := ""
+		if len(parts) == 2 {
+			canonical = strings.TrimSpace(parts[1])
+		}
+		if !strings.Contains(alias, "@") || (len(parts) == 2 && !strings.Contains(canonical, "@")) {
+			return nil, fmt.Errorf("invalid identity %q, expected alias@example.com=canonical@example.com or *@example.com", rule)
+		}
+
+		if strings.HasPrefix(alias, "*@") {
+			ids.domains[strings.TrimPrefix(alias, "*@")] = canonical
+			continue
+		}
+		if canonical == "" {
+			return nil, fmt.Errorf("invalid identity %q, only the domains can be mapped by name, like *@example.com", rule)
+		}
+		ids.emails[alias] = canonical
+	}
+	return ids, nil
+}
+
+// canonicalEmail returns with the email of the identity of the author, or the email itself if it is not mapped
+func (ids *identities) canonicalEmail(name, email string) string {
+	if ids == nil {
+		return email
+	}
+	lowerEmail := strings.ToLower(email)
+	if ids.githubNoreply {
+		if match := githubNoreplyRegex.FindStringSubmatch(lowerEmail); match != nil {
+			// The name of the user is stable, the email without the ID is a valid noreply email too
+			email = match[1] + "@users.noreply.github.com"
+			lowerEmail = email
+		}
+	}
+	if canonical, ok := ids.emails[lowerEmail]; ok {
+		return canonical
+	}
+	canonical, ok := ids.domains[emailDomain(lowerEmail)]
+	if !ok {
+		return email
+	}
+	if canonical == "" {
+		canonical = ids.byName[normalizeAuthorName(name)]
+	}
+	if canonical == "" {
+		return email
+	}
+	return canonical
+}
+
+// canonicalize replaces the emails of the author and the co-authors of the commit with the emails of their identities
+// The co-authors having the same identity as the author or as another co-author are left out.
+func (ids *identities) canonicalize(c *commit.Commit) {
+	if ids == nil {
+		return
+	}
+	c.AuthorEmail = ids.canonicalEmail(c.AuthorName, c.AuthorEmail)
+	seenEmails := map[string]bool{strings.ToLower(c.AuthorEmail): true}
+	coAuthors := c.CoAuthors[:0]
+	for _, coAuthor := range c.CoAuthors {
+		coAuthor.Email = ids.canonicalEmail(coAuthor.Name, coAuthor.Email)
+		if seenEmails[strings.ToLower(coAuthor.Email)] {
+			continue
+		}
+		seenEmails[strings.ToLower(coAuthor.Email)] = true
+		coAuthors = append(coAuthors, coAuthor)
+	}
+	c.CoAuthors = coAuthors
+}
+
+// mapsByName tells if any domain is mapped by the name of the authors
+func (ids *identities) mapsByName() bool {
+	if ids == nil {
+		return false
+	}
+	for _, canonical := range ids.domains {
+		if canonical == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIdentityNames finds the emails of the domains mapped by name: the email of an author is the email used in the
+// most commits by the same name, outside of these domains. It needs an additional pass over the history.
+// The aliases found in the history are mapped by their email too, so they can be given in UserEmails.
+func (r *RepoExtractor) loadIdentityNames(ctx context.Context) error {
+	if !r.identities.mapsByName() {
+		return nil
+	}
+
+	commits := map[string]map[string]int{}
+	aliases := map[string]string{}
+	count := func(name, email string) {
+		name = normalizeAuthorName(name)
+		if canonical, ok := r.identities.domains[emailDomain(strings.ToLower(email))]; ok && canonical == "" {
+			aliases[strings.ToLower(email)] = name
+			return
+		}
+		if commits[name] == nil {
+			commits[name] = map[string]int{}
+		}
+		commits[name][email]++
+	}
+	err := r.streamCommits(ctx, false, func(c *commit.Commit) {
+		count(c.AuthorName, c.AuthorEmail)
+		for _, coAuthor := range c.CoAuthors {
+			count(coAuthor.Name, coAuthor.Email)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for name, emails := range commits {
+		canonical := ""
+		for email, n := range emails {
+			if canonical == "" || n > emails[canonical] || (n == emails[canonical] && email < canonical) {
+				canonical = email
+			}
+		}
+		r.identities.byName[name] = canonical
+	}
+	for alias, name := range aliases {
+		if canonical, ok := r.identities.byName[name]; ok {
+			r.identities.emails[alias] = canonical
+		}
+	}
+	r.Logger.Info("Identities mapped by name", logger.Fields{"names": len(r.identities.byName)})
+	return nil
+}
+
+// emailDomain returns with the domain of the email, like "example.com" for "me@example.com"
+func emailDomain(email string) string {
+	return email[strings.LastIndex(email, "@")+1:]
+}
+
+// normalizeAuthorName makes the names written with different cases or spaces equal
+func normalizeAuthorName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,93 @@
+package extractor
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+var _ = Describe("Identities", func() {
+	It("should map the emails and the domains to the canonical emails", func() {
+		ids, err := parseIdentities([]string{"Me@Home.com=me@work.com", "*@ci.example.com=ci@example.com"}, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ids.canonicalEmail("Me", "me@home.com")).To(Equal("me@work.com"))
+		Expect(ids.canonicalEmail("CI", "runner-12@ci.example.com")).To(Equal("ci@example.com"))
+		Expect(ids.canonicalEmail("Other", "other@example.com")).To(Equal("other@example.com"))
+	})
+
+	It("should leave out the co-authors having the identity of the author", func() {
+		ids, _ := parseIdentities([]string{"me@home.com=me@work.com"}, false)
+		c := &commit.Commit{
+			AuthorEmail: "me@work.com",
+			CoAuthors:   []commit.CoAuthor{{Name: "Me", Email: "me@home.com"}, {Name: "Colleague", Email: "colleague@work.com"}},
+		}
+		ids.canonicalize(c)
+		Expect(c.CoAuthors).To(Equal([]commit.CoAuthor{{Name: "Colleague", Email: "colleague@work.com"}}))
+	})
+
+	It("should reject the invalid rules", func() {
+		_, err := parseIdentities([]string{"me=me@work.com"}, false)
+		Expect(err).To(HaveOccurred())
+		_, err = parseIdentities([]string{"me@home.com"}, false)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should remove the ID from the GitHub noreply emails", func() {
+		ids, err := parseIdentities(nil, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids.canonicalEmail("Octocat", "583231+Octocat@users.noreply.github.com")).To(Equal("octocat@users.noreply.github.com"))
+		Expect(ids.canonicalEmail("Octocat", "octocat@users.noreply.github.com")).To(Equal("octocat@users.noreply.github.com"))
+		Expect(ids.canonicalEmail("Octocat", "583231+octocat@example.com")).To(Equal("583231+octocat@example.com"))
+
+		ids, _ = parseIdentities([]string{"octocat@users.noreply.github.com=octocat@example.com"}, true)
+		Expect(ids.canonicalEmail("Octocat", "583231+octocat@users.noreply.github.com")).To(Equal("octocat@example.com"))
+	})
+
+	Describe("by name", func() {
+		var fixture *fixtureRepo
+
+		BeforeEach(func() {
+			fixture = newFixtureRepo()
+			fixture.commit("main.go", "package main\n")
+			fixture.git("-c", "user.email=1234+developer@users.noreply.github.com", "commit", "--quiet", "--allow-empty", "-m", "Web UI")
+			fixture.git("-c", "user.name=Stranger", "-c", "user.email=5678+stranger@users.noreply.github.com", "commit", "--quiet", "--allow-empty", "-m", "Web UI")
+		})
+
+		AfterEach(func() {
+			fixture.remove()
+		})
+
+		It("should map the emails of the domain to the email of the author with the same name", func() {
+			r := fixture.extractor()
+			r.Identities = []string{"*@users.noreply.github.com"}
+			r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+			Expect(r.validateOptions()).To(Succeed())
+			Expect(r.loadIdentityNames(context.Background())).To(Succeed())
+
+			_, commits, err := r.getAllEmails(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(commits).To(Equal(map[string]int{
+				"developer@example.com":                  2,
+				"5678+stranger@users.noreply.github.com": 1,
+			}))
+		})
+
+		It("should export the commits of the aliases as the commits of the identity", func() {
+			r := fixture.extractor()
+			r.Identities = []string{"*@users.noreply.github.com"}
+			r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+			Expect(r.validateOptions()).To(Succeed())
+			Expect(r.loadIdentityNames(context.Background())).To(Succeed())
+			r.repo = &repo{}
+			r.UserEmails = []string{"1234+developer@users.noreply.github.com"}
+			Expect(r.analyseCommits(context.Background())).To(Succeed())
+			Expect(r.repo.Emails).To(Equal([]string{"developer@example.com"}))
+		})
+	})
+})
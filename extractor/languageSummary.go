@@ -0,0 +1,59 @@
+package extractor
+
+import (
+	"math"
+
+	"github.com/Techloopio/extractor_tool/commit"
+)
+
+// LanguageSummary is the total of the changes of a language in the analysed commits
+type LanguageSummary struct {
+	Insertions  int `json:"insertions"`
+	Deletions   int `json:"deletions"`
+	FileChanges int `json:"fileChanges"`
+	// Share is the ratio of the changed lines of the language to the changed lines of every language, between 0 and 1
+	Share float64 `json:"share"`
+}
+
+// addToLanguageSummary adds the changed files of the commit to the totals of their languages
+// The files without detected language are left out.
+func addToLanguageSummary(summary map[string]LanguageSummary, c commit.Commit) {
+	for _, changedFile := range c.ChangedFiles {
+		if changedFile.Language == "" {
+			continue
+		}
+		languageSummary := summary[changedFile.Language]
+		languageSummary.Insertions += changedFile.Insertions
+		languageSummary.Deletions += changedFile.Deletions
+		languageSummary.FileChanges++
+		summary[changedFile.Language] = languageSummary
+	}
+}
+
+// mergeLanguageSummaries returns with the totals of both summaries and their shares recalculated
+func mergeLanguageSummaries(summary, other map[string]LanguageSummary) map[string]LanguageSummary {
+	merged := make(map[string]LanguageSummary, len(summary)+len(other))
+	for _, s := range []map[string]LanguageSummary{summary, other} {
+		for language, languageSummary := range s {
+			total := merged[language]
+			total.Insertions += languageSummary.Insertions
+			total.Deletions += languageSummary.Deletions
+			total.FileChanges += languageSummary.FileChanges
+			merged[language] = total
+		}
+	}
+
+	changedLines := 0
+	for _, languageSummary := range merged {
+		changedLines += languageSummary.Insertions + languageSummary.Deletions
+	}
+	for language, languageSummary := range merged {
+		languageSummary.Share = 0
+		if changedLines > 0 {
+			share := float64(languageSummary.Insertions+languageSummary.Deletions) / float64(changedLines)
+			languageSummary.Share = math.Round(share*10000) / 10000
+		}
+		merged[language] = languageSummary
+	}
+	return merged
+}
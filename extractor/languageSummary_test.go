@@ -0,0 +1,72 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/obfuscation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LanguageSummary", func() {
+	It("should total the changes of every language", func() {
+		summary := map[string]LanguageSummary{}
+		addToLanguageSummary(summary, commit.Commit{ChangedFiles: []*commit.ChangedFile{
+			{Path: "main.go", Language: "Go", Insertions: 50, Deletions: 10},
+			{Path: "app.js", Language: "JavaScript", Insertions: 20, Deletions: 0},
+			{Path: "LICENSE", Insertions: 20},
+		}})
+		addToLanguageSummary(summary, commit.Commit{ChangedFiles: []*commit.ChangedFile{
+			{Path: "util.go", Language: "Go", Insertions: 10, Deletions: 10},
+		}})
+
+		Expect(mergeLanguageSummaries(summary, nil)).To(Equal(map[string]LanguageSummary{
+			"Go":         {Insertions: 60, Deletions: 20, FileChanges: 2, Share: 0.8},
+			"JavaScript": {Insertions: 20, Deletions: 0, FileChanges: 1, Share: 0.2},
+		}))
+	})
+
+	It("should add the summary of the previous export", func() {
+		previous := map[string]LanguageSummary{"Go": {Insertions: 30, Deletions: 10, FileChanges: 4, Share: 1}}
+		current := map[string]LanguageSummary{"Python": {Insertions: 40, FileChanges: 1}}
+
+		Expect(mergeLanguageSummaries(previous, current)).To(Equal(map[string]LanguageSummary{
+			"Go":     {Insertions: 30, Deletions: 10, FileChanges: 4, Share: 0.5},
+			"Python": {Insertions: 40, Deletions: 0, FileChanges: 1, Share: 0.5},
+		}))
+	})
+
+	It("should write the summary to the envelope", func() {
+		obfuscator, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		r := &RepoExtractor{
+			obfuscator:                 obfuscator,
+			commitPipeline:             make(chan commit.Commit),
+			libraryExtractionCompleted: make(chan bool),
+		}
+		go func() {
+			r.commitPipeline <- commit.Commit{
+				AuthorEmail:  "developer@example.com",
+				Date:         "2021-03-18 10:00:00 +0000",
+				ChangedFiles: []*commit.ChangedFile{{Path: "main.go", Language: "Go", Insertions: 5, Deletions: 1}},
+			}
+			r.libraryExtractionCompleted <- true
+		}()
+
+		var buffer bytes.Buffer
+		w := bufio.NewWriter(&buffer)
+		Expect(r.writeExport(w, nil, nil)).To(Succeed())
+		w.Flush()
+
+		var envelope struct {
+			Languages map[string]LanguageSummary `json:"languages"`
+		}
+		Expect(json.Unmarshal(buffer.Bytes(), &envelope)).To(Succeed())
+		Expect(envelope.Languages).To(Equal(map[string]LanguageSummary{
+			"Go": {Insertions: 5, Deletions: 1, FileChanges: 1, Share: 1},
+		}))
+	})
+})
@@ -0,0 +1,259 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MinLanguageConfidence", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		repo.commit("list.h", "#include <stdlib.h>\n\nstruct list;\n")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should extract the libraries of every file by default", func() {
+		r := repo.extractor()
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"C": {"stdlib.h"}}))
+	})
+
+	It("should not extract the libraries of the files detected with lower confidence", func() {
+		r := repo.extractor()
+		r.MinLanguageConfidence = 0.6
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(BeEmpty())
+		Expect(commits[0].ChangedFiles[0].Language).To(Equal("C"))
+	})
+})
+
+var _ = Describe("SkipLanguages", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "main.go"), []byte("package main\n\nimport \"fmt\"\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "schema.sql"), []byte("CREATE TABLE users (id INT);\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "package.json"), []byte(`{"devDependencies": {"jest": "26.6.3"}}`), 0644)).To(Succeed())
+		repo.git("add", "--all")
+		repo.git("commit", "--quiet", "-m", "Add files")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should ignore the files and the dependencies of the skipped languages", func() {
+		r := repo.extractor()
+		r.SkipLanguages = []string{"SQL", "JSON", "JavaScript"}
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"Go": {"fmt"}}))
+		languages, insertions, _ := getCommitStats(commits[0])
+		Expect(languages).To(Equal([]string{"Go"}))
+		Expect(insertions).To(Equal(3))
+	})
+
+	It("should only analyse the allowed languages", func() {
+		r := repo.extractor()
+		r.OnlyLanguages = []string{"SQL"}
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"SQL": {}}))
+		languages, insertions, _ := getCommitStats(commits[0])
+		Expect(languages).To(Equal([]string{"SQL"}))
+		Expect(insertions).To(Equal(1))
+	})
+})
+
+var _ = Describe("MaxFileSize", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		repo.commit("bundle.js", "import React from 'react'\n"+strings.Repeat("console.log(1)\n", 100))
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should not extract the libraries of the files above the size limit", func() {
+		r := repo.extractor()
+		r.MaxFileSize = 1024
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(BeEmpty())
+		languages, insertions, _ := getCommitStats(commits[0])
+		Expect(languages).To(Equal([]string{"JavaScript"}))
+		Expect(insertions).To(Equal(101))
+	})
+
+	It("should extract the libraries of the files below the size limit", func() {
+		r := repo.extractor()
+		r.MaxFileSize = 1024 * 1024
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"JavaScript": {"react"}}))
+	})
+})
+
+var _ = Describe("FileExtensions", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "MAIN.PY"), []byte("import requests\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "index.ts"), []byte("import express from 'express'\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "globals.d.ts"), []byte("import { Moment } from 'moment'\n"), 0644)).To(Succeed())
+		repo.git("add", "--all")
+		repo.git("commit", "--quiet", "-m", "Add files")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should detect the uppercase extensions and skip the libraries of the declaration files", func() {
+		r := repo.extractor()
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{
+			"Python":     {"requests"},
+			"TypeScript": {"express"},
+		}))
+		languages := map[string]string{}
+		for _, changedFile := range commits[0].ChangedFiles {
+			languages[changedFile.Path] = changedFile.Language
+		}
+		Expect(languages).To(Equal(map[string]string{
+			"MAIN.PY":      "Python",
+			"index.ts":     "TypeScript",
+			"globals.d.ts": "TypeScript",
+		}))
+	})
+})
+
+var _ = Describe("BuildLibraries", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "Makefile"), []byte("build:\n\tgo build ./...\n\tdocker build .\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "CMakeLists.txt"), []byte("find_package(Boost REQUIRED)\n"), 0644)).To(Succeed())
+		repo.git("add", "--all")
+		repo.git("commit", "--quiet", "-m", "Add build files")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should report the libraries of the build files together", func() {
+		r := repo.extractor()
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(HaveLen(1))
+		Expect(commits[0].Libraries[BuildLibraries]).To(ConsistOf("Boost", "go", "docker"))
+	})
+
+	It("should only report the libraries of the allowed build languages", func() {
+		r := repo.extractor()
+		r.OnlyLanguages = []string{"Makefile"}
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{BuildLibraries: {"go", "docker"}}))
+	})
+})
+
+var _ = Describe("CILibraries", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		Expect(os.MkdirAll(filepath.Join(repo.path, ".github", "workflows"), 0755)).To(Succeed())
+		workflow := "jobs:\n  test:\n    container: node:18\n    steps:\n      - uses: actions/checkout@v4\n"
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, ".github", "workflows", "ci.yml"), []byte(workflow), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "config.yml"), []byte("uses: actions/setup-go@v5\n"), 0644)).To(Succeed())
+		repo.git("add", "--all")
+		repo.git("commit", "--quiet", "-m", "Add the workflow")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should report the dependencies of the workflows only", func() {
+		r := repo.extractor()
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"CI": {"node:18", "actions/checkout@v4"}}))
+	})
+})
+
+var _ = Describe("ManifestNames", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		Expect(os.MkdirAll(filepath.Join(repo.path, "docs"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "DESCRIPTION"), []byte("Package: app\nImports: dplyr\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "docs", "description"), []byte("Package: docs\nImports: ggplot2\n"), 0644)).To(Succeed())
+		repo.git("add", "--all")
+		repo.git("commit", "--quiet", "-m", "Add the manifests")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should match the names of the manifests case-sensitively", func() {
+		r := repo.extractor()
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Libraries).To(Equal(map[string][]string{"R": {"dplyr"}}))
+	})
+
+	It("should not analyse the deleted manifests", func() {
+		Expect(ioutil.WriteFile(filepath.Join(repo.path, "package.json"), []byte(`{"dependencies": {"react": "17.0.2"}}`), 0644)).To(Succeed())
+		repo.git("add", "--all")
+		repo.git("commit", "--quiet", "-m", "Add the package.json")
+		repo.git("rm", "--quiet", "DESCRIPTION", "package.json")
+		repo.git("commit", "--quiet", "-m", "Remove the manifests")
+		r := repo.extractor()
+
+		commits := analyseCommitLibraries(r)
+		Expect(commits).To(HaveLen(3))
+		removed := 0
+		for _, c := range commits {
+			if strings.TrimSpace(c.Message) == "Remove the manifests" {
+				removed++
+				Expect(c.Libraries).To(BeEmpty())
+			}
+		}
+		Expect(removed).To(Equal(1))
+	})
+})
@@ -0,0 +1,59 @@
+package extractor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseNumstatLine", func() {
+	It("should parse a regular numstat line", func() {
+		changedFile, err := parseNumstatLine("12\t3\tsrc/main.go")
+
+		Expect(err).To(BeNil())
+		Expect(changedFile.Path).To(Equal("src/main.go"))
+		Expect(changedFile.Insertions).To(Equal(12))
+		Expect(changedFile.Deletions).To(Equal(3))
+		Expect(changedFile.Binary).To(BeFalse())
+	})
+
+	It("should mark binary files", func() {
+		changedFile, err := parseNumstatLine("-\t-\timage.png")
+
+		Expect(err).To(BeNil())
+		Expect(changedFile.Path).To(Equal("image.png"))
+		Expect(changedFile.Insertions).To(Equal(0))
+		Expect(changedFile.Deletions).To(Equal(0))
+		Expect(changedFile.Binary).To(BeTrue())
+	})
+
+	It("should record the post-rename path of a brace-rename", func() {
+		changedFile, err := parseNumstatLine("5\t1\tsrc/{old => new}/file.go")
+
+		Expect(err).To(BeNil())
+		Expect(changedFile.Path).To(Equal("src/new/file.go"))
+		Expect(changedFile.Insertions).To(Equal(5))
+		Expect(changedFile.Deletions).To(Equal(1))
+	})
+
+	It("should record the post-rename path of renames with an empty side", func() {
+		added, err := parseNumstatLine("0\t0\tsrc/{ => pkg}/file.go")
+		Expect(err).To(BeNil())
+		Expect(added.Path).To(Equal("src/pkg/file.go"))
+
+		removed, err := parseNumstatLine("0\t0\tsrc/{pkg => }/file.go")
+		Expect(err).To(BeNil())
+		Expect(removed.Path).To(Equal("src/file.go"))
+	})
+
+	It("should record the post-rename path of a full rename", func() {
+		changedFile, err := parseNumstatLine("0\t0\told name.go => new name.go")
+
+		Expect(err).To(BeNil())
+		Expect(changedFile.Path).To(Equal("new name.go"))
+	})
+
+	It("should return an error for malformed lines", func() {
+		_, err := parseNumstatLine("x\t1\tfile.go")
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,142 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Techloopio/extractor_tool/state"
+)
+
+// loadState reads the state of the previous extraction and gets the refs which are analysed now
+// The commits reachable from the refs of the previous extraction are not analysed again.
+func (r *RepoExtractor) loadState() error {
+	if r.StateFile == "" {
+		return nil
+	}
+
+	refs, err := r.getRefCommits()
+	if err != nil {
+		return fmt.Errorf("cannot get the refs of the repository: %s", err.Error())
+	}
+	r.currentRefs = refs
+
+	if r.ForceRefresh {
+		r.previousState = &state.State{}
+		return nil
+	}
+
+	previousState, err := state.Load(r.StateFile)
+	if err != nil {
+		return fmt.Errorf("cannot read state file %s: %s", r.StateFile, err.Error())
+	}
+	// Commits can disappear after a force push, git log would fail with them
+	existingRefs := make([]string, 0, len(previousState.ProcessedRefs))
+	for _, ref := range previousState.ProcessedRefs {
+		if r.commitExists(ref) {
+			existingRefs = append(existingRefs, ref)
+		}
+	}
+	previousState.ProcessedRefs = existingRefs
+	r.previousState = previousState
+	return nil
+}
+
+// saveState saves the refs analysed by this extraction
+func (r *RepoExtractor) saveState() error {
+	if r.StateFile == "" {
+		return nil
+	}
+
+	s := &state.State{
+		ProcessedRefs: r.currentRefs,
+		UpdatedAt:     time.Now(),
+	}
+	return s.Save(r.StateFile)
+}
+
+// isIncremental tells if the extraction only analyses the commits which are new since the previous extraction
+func (r *RepoExtractor) isIncremental() bool {
+	return r.previousState != nil && !r.previousState.IsEmpty()
+}
+
+// getRefCommits returns with the commit hashes of the analysed refs
+func (r *RepoExtractor) getRefCommits() ([]string, error) {
+	args := []string{"rev-parse"}
+	if len(r.Refs) > 0 {
+		args = append(args, r.Refs...)
+	} else {
+		args = append(args, "--all")
+	}
+	cmd := exec.Command(r.GitPath, args...)
+	cmd.Dir = r.RepoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	seenRefs := make(map[string]bool)
+	for _, ref := range strings.Fields(string(out)) {
+		if !seenRefs[ref] {
+			seenRefs[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+func (r *RepoExtractor) commitExists(hash string) bool {
+	cmd := exec.Command(r.GitPath, "cat-file", "-e", hash+"^{commit}")
+	cmd.Dir = r.RepoPath
+	return cmd.Run() == nil
+}
+
+// readPreviousExport reads the records of an existing JSON export, with or without the envelope
+// The summary is read from the envelope if it is not nil. Exports ending with .gz are decompressed first.
+func readPreviousExport(path string, records interface{}, summary *exportSummary) error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(path, ".gz") {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return err
+		}
+		content, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+	}
+
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("[")) {
+		return json.Unmarshal(content, records)
+	}
+
+	var envelope struct {
+		Commits   json.RawMessage            `json:"commits"`
+		Languages map[string]LanguageSummary `json:"languages"`
+	}
+	err = json.Unmarshal(content, &envelope)
+	if err != nil {
+		return err
+	}
+	if summary != nil {
+		summary.Languages = envelope.Languages
+	}
+	if len(envelope.Commits) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Commits, records)
+}
@@ -0,0 +1,100 @@
+package extractor
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/commit"
+)
+
+var _ = Describe("IncrementalExtraction", func() {
+	var repo *fixtureRepo
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		repo.commit("main.go", "package main\n")
+		repo.commit("util.go", "package main\n")
+	})
+
+	AfterEach(func() {
+		repo.remove()
+	})
+
+	It("should only get the commits added since the previous extraction", func() {
+		stateFile := filepath.Join(repo.path, ".git", "extractor_state.json")
+
+		r := repo.extractor()
+		r.StateFile = stateFile
+		Expect(r.loadState()).To(Succeed())
+		Expect(r.isIncremental()).To(BeFalse())
+		commits, err := getCommits(r)
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(2))
+		Expect(r.saveState()).To(Succeed())
+
+		repo.commit("new.go", "package main\n")
+
+		r = repo.extractor()
+		r.StateFile = stateFile
+		Expect(r.loadState()).To(Succeed())
+		Expect(r.isIncremental()).To(BeTrue())
+		commits, err = getCommits(r)
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Message).To(Equal("Change new.go"))
+
+		r = repo.extractor()
+		r.StateFile = stateFile
+		r.ForceRefresh = true
+		Expect(r.loadState()).To(Succeed())
+		commits, err = getCommits(r)
+		Expect(err).To(BeNil())
+		Expect(commits).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("ReadPreviousExport", func() {
+	var exportPath string
+
+	BeforeEach(func() {
+		file, err := ioutil.TempFile("", "extractor_export_")
+		Expect(err).To(BeNil())
+		file.Close()
+		exportPath = file.Name()
+	})
+
+	AfterEach(func() {
+		os.Remove(exportPath)
+	})
+
+	It("should read the commits from the envelope", func() {
+		Expect(ioutil.WriteFile(exportPath, []byte(`{"schemaVersion":1,"toolVersion":"v1","repo":"r","commits":[
+{"date":"2021-01-01 00:00:00 +0000 UTC","commits":2}
+]}`), 0644)).To(Succeed())
+
+		var commits []commit.OptimizedCommitForExport
+		Expect(readPreviousExport(exportPath, &commits, nil)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+		Expect(commits[0].Commits).To(Equal(2))
+	})
+
+	It("should read the commits from the legacy format", func() {
+		Expect(ioutil.WriteFile(exportPath, []byte(`[
+{"date":"2021-01-01 00:00:00 +0000 UTC","commits":2}
+]`), 0644)).To(Succeed())
+
+		var commits []commit.OptimizedCommitForExport
+		Expect(readPreviousExport(exportPath, &commits, nil)).To(Succeed())
+		Expect(commits).To(HaveLen(1))
+	})
+
+	It("should not fail without a previous export", func() {
+		var commits []commit.OptimizedCommitForExport
+		Expect(readPreviousExport(exportPath+"_missing", &commits, nil)).To(Succeed())
+		Expect(commits).To(BeEmpty())
+	})
+})
@@ -0,0 +1,156 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+var _ = Describe("TimeLimit", func() {
+	var repo *fixtureRepo
+	var outputDir string
+
+	BeforeEach(func() {
+		repo = newFixtureRepo()
+		repo.commit("main.go", "package main\n\nimport \"fmt\"\n")
+		repo.commit("server.go", "package main\n\nimport \"net/http\"\n")
+		repo.commit("client.go", "package main\n\nimport \"net/url\"\n")
+
+		var err error
+		outputDir, err = ioutil.TempDir("", "extractor_time_limit_")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		repo.remove()
+		os.RemoveAll(outputDir)
+	})
+
+	extract := func() []byte {
+		r := repo.extractor()
+		r.TimeLimit = time.Nanosecond
+		r.Quiet = true
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(outputDir, "fixture")
+		var log bytes.Buffer
+		r.Logger, _ = logger.NewLogger(logger.FormatText, &log, true)
+		Expect(r.Extract()).To(Succeed())
+		// git log is not even started, it is not an error
+		Expect(log.String()).ToNot(ContainSubstring("Error during execution of Git command"))
+		// There is no state file to keep
+		Expect(log.String()).ToNot(ContainSubstring("The state file is not updated"))
+
+		export, err := ioutil.ReadFile(r.exportFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		return export
+	}
+
+	It("should write the same valid partial export every time", func() {
+		export := extract()
+
+		var envelope struct {
+			Commits []commit.OptimizedCommitForExport `json:"commits"`
+			Partial *partialExport                    `json:"partial"`
+		}
+		Expect(json.Unmarshal(export, &envelope)).To(Succeed())
+		Expect(envelope.Commits).To(BeEmpty())
+		Expect(envelope.Partial).To(Equal(&partialExport{ProcessedCommits: 0, TotalCommits: 3}))
+
+		Expect(extract()).To(Equal(export))
+	})
+
+	It("should export the commits analysed before the time limit", func() {
+		// The commits of the other authors are not counted
+		repo.git("-c", "user.email=other@example.com", "commit", "--allow-empty", "-m", "other")
+		r := repo.extractor()
+		// The second commit of the user holds the only worker until the time limit is exceeded
+		slowGit := filepath.Join(outputDir, "slow-git")
+		script := "#!/bin/sh\ncase \"$*\" in *ls-tree*" + repo.git("rev-parse", "HEAD~2") + "*) sleep 3;; esac\nexec " + r.GitPath + " \"$@\"\n"
+		Expect(ioutil.WriteFile(slowGit, []byte(script), 0755)).To(Succeed())
+		r.GitPath = slowGit
+		r.TimeLimit = 2 * time.Second
+		r.Workers = 1
+		r.Aggregation = AggregationNone
+		r.Quiet = true
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(outputDir, "fixture")
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		Expect(r.Extract()).To(Succeed())
+
+		export, err := ioutil.ReadFile(r.exportFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		var envelope struct {
+			Commits []commit.OptimizedCommitForExport `json:"commits"`
+			Partial *partialExport                    `json:"partial"`
+		}
+		Expect(json.Unmarshal(export, &envelope)).To(Succeed())
+		// The first commit of the user is analysed, the second one is interrupted and the third one is dropped
+		Expect(envelope.Partial).To(Equal(&partialExport{ProcessedCommits: 1, TotalCommits: 3}))
+		Expect(envelope.Commits).To(HaveLen(1))
+	})
+
+	It("should write the export promptly after the time limit", func() {
+		r := repo.extractor()
+		// The second commit would hold the only worker much longer than the time limit
+		hangingGit := filepath.Join(outputDir, "hanging-git")
+		script := "#!/bin/sh\ncase \"$*\" in *ls-tree*" + repo.git("rev-parse", "HEAD~1") + "*) exec sleep 30;; esac\nexec " + r.GitPath + " \"$@\"\n"
+		Expect(ioutil.WriteFile(hangingGit, []byte(script), 0755)).To(Succeed())
+		r.GitPath = hangingGit
+		r.TimeLimit = time.Second
+		r.Workers = 1
+		r.Quiet = true
+		r.UserEmails = []string{"developer@example.com"}
+		r.OutputPath = filepath.Join(outputDir, "fixture")
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+
+		start := time.Now()
+		Expect(r.Extract()).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+
+		export, err := ioutil.ReadFile(r.exportFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Valid(export)).To(BeTrue())
+	})
+
+	It("should only export the completely analysed commits", func() {
+		r := repo.extractor()
+		r.Logger, _ = logger.NewLogger(logger.FormatText, ioutil.Discard, true)
+		r.initAnalyzers()
+		r.blobCache = newBlobCache()
+		r.commitPipeline = make(chan commit.Commit)
+		commits, err := getCommits(r)
+		Expect(err).ToNot(HaveOccurred())
+		jobs := make(chan *commit.Commit, len(commits))
+		for _, c := range commits {
+			jobs <- c
+		}
+		close(jobs)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			r.libraryWorker(ctx, jobs)
+			close(r.commitPipeline)
+		}()
+		analysedCommits := []commit.Commit{<-r.commitPipeline}
+		// The time limit is exceeded after the first commit
+		cancel()
+		for c := range r.commitPipeline {
+			analysedCommits = append(analysedCommits, c)
+		}
+
+		Expect(len(analysedCommits) + int(r.droppedCommits)).To(Equal(3))
+		for _, c := range analysedCommits {
+			Expect(c.Libraries["Go"]).To(HaveLen(1))
+		}
+	})
+})
@@ -0,0 +1,130 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/Techloopio/extractor_tool/logger"
+)
+
+// DefaultUploadTimeout is the timeout of a single upload attempt if UploadTimeout is not set
+const DefaultUploadTimeout = 30 * time.Second
+
+// uploadRetryDelay is the delay before the first retry of a failed upload, it is doubled after every retry
+var uploadRetryDelay = time.Second
+
+// UploadError is returned if the export couldn't be uploaded, the export file is still available locally
+type UploadError struct {
+	Path string
+	Err  error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("cannot upload %s: %s", e.Path, e.Err.Error())
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// Uploader uploads the export file to a storage, like an S3 bucket
+type Uploader interface {
+	Upload(exportPath, repoName string) error
+}
+
+// upload sends the export file to UploadURL and to every Uploader
+func (r *RepoExtractor) upload() error {
+	path := r.exportFilePath()
+	if r.UploadURL != "" {
+		err := r.uploadToURL(path)
+		if err != nil {
+			return &UploadError{Path: path, Err: err}
+		}
+	}
+
+	repoName := ""
+	if r.repo != nil {
+		repoName = r.repo.RepoName
+	}
+	for _, uploader := range r.Uploaders {
+		err := uploader.Upload(path, repoName)
+		if err != nil {
+			return &UploadError{Path: path, Err: err}
+		}
+		r.Logger.Info("Uploaded!", logger.Fields{"path": path})
+	}
+	return nil
+}
+
+// uploadToURL POSTs the export file to UploadURL, retrying the network errors and the server errors
+func (r *RepoExtractor) uploadToURL(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	timeout := r.UploadTimeout
+	if timeout == 0 {
+		timeout = DefaultUploadTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	r.Logger.Info("Uploading export", logger.Fields{"path": path, "url": r.UploadURL})
+	delay := uploadRetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := r.uploadOnce(client, content)
+		if err == nil {
+			break
+		}
+		if !retry || attempt >= r.UploadRetries {
+			return err
+		}
+		r.Logger.Warning("Upload failed, retrying", logger.Fields{"error": err.Error(), "attempt": attempt + 1})
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	r.Logger.Info("Uploaded!", logger.Fields{"url": r.UploadURL})
+	return nil
+}
+
+// uploadOnce makes a single upload attempt
+// It tells if the failed attempt can be retried, the client errors like an invalid token are not retried.
+func (r *RepoExtractor) uploadOnce(client *http.Client, content []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, r.UploadURL, bytes.NewReader(content))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if r.OutputFormat == OutputFormatNDJSON {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if r.Compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if r.repo != nil {
+		req.Header.Set("X-Repo-Name", r.repo.RepoName)
+	}
+	req.Header.Set("X-Tool-Version", r.ToolVersion)
+	if r.UploadToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.UploadToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// The body is read to reuse the connection for the retries
+	ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status: %s", resp.Status)
+}
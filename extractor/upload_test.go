@@ -0,0 +1,113 @@
+package extractor
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeUploader struct {
+	exportPath string
+	repoName   string
+	err        error
+}
+
+func (u *fakeUploader) Upload(exportPath, repoName string) error {
+	u.exportPath = exportPath
+	u.repoName = repoName
+	return u.err
+}
+
+var _ = Describe("Upload", func() {
+	var outputDir string
+	var r *RepoExtractor
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("", "extractor_upload_")
+		Expect(err).ToNot(HaveOccurred())
+		uploadRetryDelay = time.Millisecond
+
+		r = &RepoExtractor{
+			OutputPath:  outputDir + "/repo",
+			ToolVersion: "v1.2.3",
+			UploadToken: "secret",
+			repo:        &repo{RepoName: "owner/repo"},
+		}
+		Expect(ioutil.WriteFile(r.exportFilePath(), []byte(`{"commits":[]}`), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	It("should POST the export with the repo name, the tool version and the token", func() {
+		var request *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			request = req
+			body, _ = ioutil.ReadAll(req.Body)
+		}))
+		defer server.Close()
+		r.UploadURL = server.URL
+
+		Expect(r.upload()).To(Succeed())
+		Expect(request.Method).To(Equal(http.MethodPost))
+		Expect(request.Header.Get("X-Repo-Name")).To(Equal("owner/repo"))
+		Expect(request.Header.Get("X-Tool-Version")).To(Equal("v1.2.3"))
+		Expect(request.Header.Get("Authorization")).To(Equal("Bearer secret"))
+		Expect(string(body)).To(Equal(`{"commits":[]}`))
+	})
+
+	It("should retry the server errors", func() {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+		r.UploadURL = server.URL
+		r.UploadRetries = 2
+
+		Expect(r.upload()).To(Succeed())
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("should not retry the client errors and keep the export", func() {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		r.UploadURL = server.URL
+		r.UploadRetries = 2
+
+		err := r.upload()
+		var uploadErr *UploadError
+		Expect(errors.As(err, &uploadErr)).To(BeTrue())
+		Expect(attempts).To(Equal(1))
+		Expect(r.exportFilePath()).To(BeAnExistingFile())
+	})
+
+	It("should send the export to the uploaders", func() {
+		uploader := &fakeUploader{}
+		r.Uploaders = []Uploader{uploader}
+
+		Expect(r.upload()).To(Succeed())
+		Expect(uploader.exportPath).To(Equal(r.exportFilePath()))
+		Expect(uploader.repoName).To(Equal("owner/repo"))
+
+		uploader.err = errors.New("access denied")
+		var uploadErr *UploadError
+		Expect(errors.As(r.upload(), &uploadErr)).To(BeTrue())
+	})
+})
@@ -1,7 +1,9 @@
 package languagedetection
 
 import (
+	"bytes"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/src-d/enry/v2"
@@ -21,30 +23,71 @@ func NewLanguageAnalyzer() *LanguageAnalyzer {
 	}
 }
 
+const (
+	// ConfidenceCertain is the confidence of the languages detected by the file name or by an unambiguous extension
+	ConfidenceCertain = 1.0
+	// ConfidenceHigh is the confidence of the languages detected by the shebang line or by a distinctive content
+	ConfidenceHigh = 0.9
+	// ConfidenceMedium is the confidence of the languages detected by a content which is typical, but not distinctive
+	ConfidenceMedium = 0.7
+	// ConfidenceLow is the confidence of the fallback languages of ambiguous extensions
+	ConfidenceLow = 0.5
+)
+
 // Detect returns with the detected language
 // If no language could be detected it will return with an empty string.
 // The filePath is the path to the file.
 // For some file types it reads the content of the file.
 func (l *LanguageAnalyzer) Detect(filePath string, fileContent []byte) string {
+	lang, _ := l.DetectLanguageWithConfidence(filePath, fileContent)
+	return lang
+}
+
+// DetectLanguageWithConfidence returns with the detected language and the confidence of the detection between 0 and 1
+// If no language could be detected it will return with an empty string and 0.
+func (l *LanguageAnalyzer) DetectLanguageWithConfidence(filePath string, fileContent []byte) (string, float64) {
 	fileName := filepath.Base(filePath)
 
 	val := l.DetectLanguageFromFileName(fileName)
 	if val != "" {
-		return val
+		return val, ConfidenceCertain
 	}
 
-	extension := filepath.Ext(filePath)
+	extension := FileExtension(filePath)
 	if extension == "" {
-		return ""
+		// Scripts often don't have extension, but the interpreter is defined in the shebang line
+		return withConfidence(l.DetectLanguageFromShebang(fileContent), ConfidenceHigh)
 	}
 
-	// remove the trailing dot
-	extension = extension[1:]
 	if l.ShouldUseFile(extension) {
-		return l.DetectLanguageFromFile(filePath, fileContent)
-	} else {
-		return l.DetectLanguageFromExtension(extension)
+		return l.DetectLanguageFromFileWithConfidence(filePath, fileContent)
+	}
+	return withConfidence(l.DetectLanguageFromExtension(extension), ConfidenceCertain)
+}
+
+// FileExtension returns with the extension of the file in lowercase without the dot, like "py" for "main.PY"
+// The compound extensions like "d.ts" of the TypeScript declaration files are returned as a whole.
+// Files without extension and dotfiles like ".bashrc" have no extension.
+func FileExtension(filePath string) string {
+	fileName := strings.ToLower(filepath.Base(filePath))
+	for _, extension := range compoundExtensions {
+		if strings.HasSuffix(fileName, "."+extension) && len(fileName) > len(extension)+1 {
+			return extension
+		}
+	}
+	extension := filepath.Ext(fileName)
+	if extension == fileName {
+		return ""
+	}
+	return strings.TrimPrefix(extension, ".")
+}
+
+// withConfidence returns with the language and the confidence, or 0 if the language is unknown
+func withConfidence(lang string, confidence float64) (string, float64) {
+	if lang == "" {
+		return "", 0
 	}
+	return lang, confidence
 }
 
 // DetectLanguageFromFileName returns programming language based on files name
@@ -53,6 +96,45 @@ func (l *LanguageAnalyzer) DetectLanguageFromFileName(fileName string) string {
 	if val, ok := l.FileNameMap[fileName]; ok {
 		return val
 	}
+	// Variants like Dockerfile.dev
+	if strings.HasPrefix(fileName, "dockerfile.") {
+		return "Dockerfile"
+	}
+	return ""
+}
+
+// DetectLanguageFromShebang returns programming language based on the shebang line of the file
+// like "#!/usr/bin/env python3" or "#!/bin/bash"
+func (l *LanguageAnalyzer) DetectLanguageFromShebang(fileContents []byte) string {
+	if !bytes.HasPrefix(fileContents, []byte("#!")) {
+		return ""
+	}
+	firstLine := string(fileContents[2:])
+	if end := strings.IndexAny(firstLine, "\r\n"); end != -1 {
+		firstLine = firstLine[:end]
+	}
+
+	fields := strings.Fields(firstLine)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	// "#!/usr/bin/env -S python3 -u" runs the first argument which is not a flag
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+	// Remove the version like python3 or python3.8
+	interpreter = strings.TrimRight(interpreter, "0123456789.")
+
+	if val, ok := shebangInterpreterMap[interpreter]; ok {
+		return val
+	}
 	return ""
 }
 
@@ -69,23 +151,70 @@ func (l *LanguageAnalyzer) DetectLanguageFromExtension(extension string) string
 // DetectLanguageFromFile returns programming language based on file itself
 // It also needs filename to increase accuracy
 func (l *LanguageAnalyzer) DetectLanguageFromFile(filePath string, fileContents []byte) string {
-	lang, _ := enry.GetLanguageByContent(filePath, fileContents)
+	lang, _ := l.DetectLanguageFromFileWithConfidence(filePath, fileContents)
+	return lang
+}
+
+// DetectLanguageFromFileWithConfidence returns programming language based on file itself
+// and the confidence of the detection between 0 and 1
+func (l *LanguageAnalyzer) DetectLanguageFromFileWithConfidence(filePath string, fileContents []byte) (string, float64) {
+	extension := FileExtension(filePath)
+	switch extension {
+	case "h":
+		return detectHeaderLanguage(fileContents)
+	case "m":
+		return detectDotMLanguage(fileContents)
+	}
+
+	lang, safe := enry.GetLanguageByContent(filePath, fileContents)
 	// For some reason enry is too bad at detecting Perl files
 	// However it can successfully detect Prolog files
 	// So, if the extension is "pl" but enry couldn't detect the language, it is probably Perl
-	if filepath.Ext(filePath) == ".pl" && lang == "" {
-		return "Perl"
+	if extension == "pl" && lang == "" {
+		return "Perl", ConfidenceLow
 	}
-	return lang
+	if safe {
+		return withConfidence(lang, ConfidenceHigh)
+	}
+	return withConfidence(lang, ConfidenceLow)
+}
+
+// detectHeaderLanguage tells if a .h header belongs to C, C++ or Objective-C based on its content
+func detectHeaderLanguage(fileContents []byte) (string, float64) {
+	if objectiveCRegex.Match(fileContents) {
+		return "Objective-C", ConfidenceHigh
+	}
+	if cppHeaderRegex.Match(fileContents) {
+		return "C++", ConfidenceMedium
+	}
+	return "C", ConfidenceLow
+}
+
+// detectDotMLanguage tells if a .m file is Objective-C or MATLAB based on its content
+// MATLAB is the fallback when the content is inconclusive, as the extension is mapped to MATLAB.
+func detectDotMLanguage(fileContents []byte) (string, float64) {
+	if objectiveCRegex.Match(fileContents) {
+		return "Objective-C", ConfidenceHigh
+	}
+	if matlabRegex.Match(fileContents) {
+		return "MATLAB", ConfidenceMedium
+	}
+	return "MATLAB", ConfidenceLow
 }
 
 // ShouldUseFile determines if it is enough to use extension, or we should try to read the file
 // to determine the language
 func (l *LanguageAnalyzer) ShouldUseFile(extension string) bool {
-	_, ok := extensionsWithMultipleLanguages[extension]
+	_, ok := extensionsWithMultipleLanguages[strings.ToLower(extension)]
 	return ok
 }
 
+// ShouldExtractLibraries tells if the libraries of the files with the extension are extracted
+// The files like the TypeScript declarations only describe the types of other packages, so they only count in the stats.
+func (l *LanguageAnalyzer) ShouldExtractLibraries(extension string) bool {
+	return !extensionsWithoutLibraries[strings.ToLower(extension)]
+}
+
 func reverseLanguageMap(input map[string][]string) map[string]string {
 	extensionMap := map[string]string{}
 	for lang, extensions := range input {
@@ -105,16 +234,52 @@ var fileNameMap = map[string][]string{
 	"Ruby":       {"gemfile", "rakefile"},
 }
 
+var shebangInterpreterMap = map[string]string{
+	"bash":    "Shell",
+	"dash":    "Shell",
+	"groovy":  "Groovy",
+	"ksh":     "Shell",
+	"lua":     "Lua",
+	"luajit":  "Lua",
+	"node":    "JavaScript",
+	"nodejs":  "JavaScript",
+	"perl":    "Perl",
+	"php":     "PHP",
+	"python":  "Python",
+	"Rscript": "R",
+	"ruby":    "Ruby",
+	"sh":      "Shell",
+	"zsh":     "Shell",
+	"ts-node": "TypeScript",
+}
+
+var (
+	objectiveCRegex = regexp.MustCompile(`(?m)^[ \t]*(@interface|@implementation|@protocol|@class|#[ \t]*import)\b`)
+	cppHeaderRegex  = regexp.MustCompile(`(?m)^[ \t]*(class|namespace|template)\b`)
+	matlabRegex     = regexp.MustCompile(`(?m)^[ \t]*(function|classdef|import|%)`)
+)
+
 var extensionsWithMultipleLanguages = map[string]bool{
+	"h":   true, // C, C++, Objective-C
 	"m":   true, // Objective-C, Matlab
 	"pl":  true, // Perl, Prolog
 	"sql": true, // Dialects of SQL
 }
 
+// compoundExtensions are the extensions with more than one dot, they are checked before the last extension of the files
+var compoundExtensions = []string{"d.ts", "d.mts", "d.cts", "tar.gz", "tar.bz2", "tar.xz"}
+
+var extensionsWithoutLibraries = map[string]bool{
+	"d.ts":  true, // TypeScript declarations
+	"d.mts": true,
+	"d.cts": true,
+	"mlx":   true, // MATLAB live scripts, which are zip archives
+}
+
 var fileExtensionMap = map[string][]string{
 	"1C Enterprise":    {"bsl", "os"},
 	"Apex":             {"cls"},
-	"Assembly":         {"asm"},
+	"Assembly":         {"asm", "nasm", "s"},
 	"Ballerina":        {"bal"},
 	"Batchfile":        {"bat", "cmd", "btm"},
 	"Blazor":           {"razor"},
@@ -122,11 +287,13 @@ var fileExtensionMap = map[string][]string{
 	"C++":              {"cpp", "cxx", "hpp", "cc", "hh", "hxx"},
 	"C#":               {"cs"},
 	"CSS":              {"css"},
-	"Clojure":          {"clj"},
+	"Clojure":          {"clj", "cljs", "cljc"},
+	"CMake":            {"cmake"},
 	"COBOL":            {"cbl", "cob", "cpy"},
 	"CoffeeScript":     {"coffee"},
 	"Crystal":          {"cr"},
 	"Dart":             {"dart"},
+	"Dockerfile":       {"dockerfile"},
 	"Groovy":           {"groovy", "gvy", "gy", "gsh"},
 	"HTML+Razor":       {"cshtml"},
 	"Ebuild":           {"ebuild", "eclass"},
@@ -138,6 +305,7 @@ var fileExtensionMap = map[string][]string{
 	"Erlang":           {"erl", "hrl"},
 	"F#":               {"fs", "fsi", "fsx", "fsscript"},
 	"Fortran":          {"f90", "f95", "f03", "f08", "for"},
+	"GDScript":         {"gd"},
 	"Go":               {"go"},
 	"Haskell":          {"hs", "lhs"},
 	"HCL":              {"hcl", "tf", "tfvars"},
@@ -154,9 +322,12 @@ var fileExtensionMap = map[string][]string{
 	"Lex":              {"l"},
 	"Liquid":           {"liquid"},
 	"Lua":              {"lua"},
-	"MATLAB":           {"m"},
+	"Makefile":         {"mk", "mak"},
+	"MATLAB":           {"m", "mlx"},
+	"Nim":              {"nim", "nims"},
 	"Nix":              {"nix"},
 	"Objective-C":      {"mm"},
+	"OCaml":            {"ml", "mli"},
 	"OpenEdge ABL":     {"p", "ab", "w", "i", "x"},
 	"Perl":             {"pl", "pm", "t"},
 	"PHP":              {"php"},
@@ -173,12 +344,15 @@ var fileExtensionMap = map[string][]string{
 	"Scala":            {"scala"},
 	"SASS":             {"sass"},
 	"SCSS":             {"scss"},
-	"Shell":            {"sh"},
+	"Shell":            {"sh", "bash", "zsh", "ksh"},
+	"SQL":              {"sql"},
 	"Smalltalk":        {"st"},
+	"Solidity":         {"sol"},
 	"Stylus":           {"styl"},
 	"Svelte":           {"svelte"},
 	"Swift":            {"swift"},
-	"TypeScript":       {"ts", "tsx"},
+	"TypeScript":       {"ts", "tsx", "mts", "cts", "d.ts", "d.mts", "d.cts"},
+	"Visual Basic":     {"vb"},
 	"Vue":              {"vue"},
 	"Xtend":            {"xtend"},
 	"Xtext":            {"xtext"},
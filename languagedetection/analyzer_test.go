@@ -1,7 +1,10 @@
 package languagedetection
 
 import (
+	"io/ioutil"
+
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -19,6 +22,28 @@ var _ = Describe("Analyzer", func() {
 			Expect(l2).To(Equal("PHP"))
 			Expect(l3).To(Equal("Blazor"))
 		})
+
+		It("should detect Assembly ", func() {
+			// Act
+			l1 := a.Detect("/home/something/boot.asm", []byte{})
+			l2 := a.Detect("/home/something/memcpy.s", []byte{})
+			l3 := a.Detect("/home/something/start.S", []byte{})
+			l4 := a.Detect("/home/something/print.nasm", []byte{})
+
+			// Assert
+			Expect(l1).To(Equal("Assembly"))
+			Expect(l2).To(Equal("Assembly"))
+			Expect(l3).To(Equal("Assembly"))
+			Expect(l4).To(Equal("Assembly"))
+		})
+
+		It("should detect GDScript ", func() {
+			// Act
+			l1 := a.Detect("/home/something/player.gd", []byte{})
+
+			// Assert
+			Expect(l1).To(Equal("GDScript"))
+		})
 	})
 
 	Context("Detect language by file name", func() {
@@ -29,6 +54,10 @@ var _ = Describe("Analyzer", func() {
 			l3 := a.Detect("/home/something/Jenkinsfile", []byte{})
 			l4 := a.Detect("/home/something/Rakefile", []byte{})
 			l5 := a.Detect("/home/something/CMakeLists.txt", []byte{})
+			l6 := a.Detect("/home/something/build.Dockerfile", []byte{})
+			l7 := a.Detect("/home/something/Dockerfile.dev", []byte{})
+			l8 := a.Detect("/home/something/build/rules.mk", []byte{})
+			l9 := a.Detect("/home/something/cmake/FindFoo.cmake", []byte{})
 
 			// Assert
 			Expect(l1).To(Equal("Makefile"))
@@ -36,6 +65,82 @@ var _ = Describe("Analyzer", func() {
 			Expect(l3).To(Equal("Jenkins"))
 			Expect(l4).To(Equal("Ruby"))
 			Expect(l5).To(Equal("CMake"))
+			Expect(l6).To(Equal("Dockerfile"))
+			Expect(l7).To(Equal("Dockerfile"))
+			Expect(l8).To(Equal("Makefile"))
+			Expect(l9).To(Equal("CMake"))
+		})
+	})
+
+	Context("Detect language of headers", func() {
+		It("should tell C, C++ and Objective-C headers apart", func() {
+			// Arrange
+			cHeader, err := ioutil.ReadFile("./fixtures/c_header.fixture")
+			Expect(err).ToNot(HaveOccurred())
+			cppHeader, err := ioutil.ReadFile("./fixtures/cpp_header.fixture")
+			Expect(err).ToNot(HaveOccurred())
+			objectiveCHeader, err := ioutil.ReadFile("./fixtures/objc_header.fixture")
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			l1 := a.Detect("/home/something/list.h", cHeader)
+			l2 := a.Detect("/home/something/polygon.h", cppHeader)
+			l3 := a.Detect("/home/something/Account.h", objectiveCHeader)
+
+			// Assert
+			Expect(l1).To(Equal("C"))
+			Expect(l2).To(Equal("C++"))
+			Expect(l3).To(Equal("Objective-C"))
+		})
+	})
+
+	Context("Detect language of .m files", func() {
+		It("should tell Objective-C and MATLAB apart", func() {
+			// Arrange
+			objectiveC, err := ioutil.ReadFile("./fixtures/objc_implementation.fixture")
+			Expect(err).ToNot(HaveOccurred())
+			matlab, err := ioutil.ReadFile("./fixtures/matlab.fixture")
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			l1 := a.Detect("/home/something/Account.m", objectiveC)
+			l2 := a.Detect("/home/something/moving_average.m", matlab)
+			l3 := a.Detect("/home/something/empty.m", []byte{})
+
+			// Assert
+			Expect(l1).To(Equal("Objective-C"))
+			Expect(l2).To(Equal("MATLAB"))
+			Expect(l3).To(Equal("MATLAB"))
+		})
+	})
+
+	Context("Detect language by shebang", func() {
+		It("should detect scripts without extension", func() {
+			// Act
+			l1 := a.Detect("/home/something/build", []byte("#!/usr/bin/env python3\nimport os\n"))
+			l2 := a.Detect("/home/something/build", []byte("#!/bin/bash\nset -e\n"))
+			l3 := a.Detect("/home/something/build", []byte("#!/bin/sh\nset -e\n"))
+			l4 := a.Detect("/home/something/build", []byte("#!/usr/bin/ruby -w\nputs 1\n"))
+			l5 := a.Detect("/home/something/build", []byte("#!/usr/bin/perl\nuse strict;\n"))
+			l6 := a.Detect("/home/something/build", []byte("#!/usr/bin/env -S node --harmony\nconsole.log(1)\n"))
+			l7 := a.Detect("/home/something/build", []byte("#!/usr/local/bin/python2.7\r\nimport os\r\n"))
+			l8 := a.Detect("/home/something/LICENSE", []byte("MIT License\n"))
+			l9 := a.Detect("/home/something/analyse", []byte("#!/usr/bin/env Rscript\nlibrary(dplyr)\n"))
+			l10 := a.Detect("/home/something/serve", []byte("#!/usr/bin/env lua5.3\nrequire(\"socket\")\n"))
+			l11 := a.Detect("/home/something/deploy", []byte("#!/usr/bin/env groovy\n@Grab('org.yaml:snakeyaml:1.29')\n"))
+
+			// Assert
+			Expect(l1).To(Equal("Python"))
+			Expect(l2).To(Equal("Shell"))
+			Expect(l3).To(Equal("Shell"))
+			Expect(l4).To(Equal("Ruby"))
+			Expect(l5).To(Equal("Perl"))
+			Expect(l6).To(Equal("JavaScript"))
+			Expect(l7).To(Equal("Python"))
+			Expect(l8).To(Equal(""))
+			Expect(l9).To(Equal("R"))
+			Expect(l10).To(Equal("Lua"))
+			Expect(l11).To(Equal("Groovy"))
 		})
 	})
 
@@ -63,5 +168,151 @@ $$;`))
 			Expect(l1).To(Equal("SQL"))
 			Expect(l2).To(Equal("PLpgSQL"))
 		})
+
+		It("should detect the .sql files without a recognizable dialect", func() {
+			// Act
+			l1 := a.Detect("/home/something/empty.sql", []byte{})
+			l2 := a.DetectLanguageFromExtension("sql")
+
+			// Assert
+			Expect(l1).To(Equal("SQL"))
+			Expect(l2).To(Equal("SQL"))
+		})
+	})
+	Context("Detect language with confidence", func() {
+		It("should be certain about file names and unambiguous extensions", func() {
+			// Act
+			l1, c1 := a.DetectLanguageWithConfidence("/home/something/Makefile", []byte{})
+			l2, c2 := a.DetectLanguageWithConfidence("/home/something/main.go", []byte{})
+
+			// Assert
+			Expect(l1).To(Equal("Makefile"))
+			Expect(c1).To(Equal(ConfidenceCertain))
+			Expect(l2).To(Equal("Go"))
+			Expect(c2).To(Equal(ConfidenceCertain))
+		})
+
+		It("should be less confident about the fallback languages of ambiguous extensions", func() {
+			// Arrange
+			cHeader, err := ioutil.ReadFile("./fixtures/c_header.fixture")
+			Expect(err).ToNot(HaveOccurred())
+			objectiveCHeader, err := ioutil.ReadFile("./fixtures/objc_header.fixture")
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			l1, c1 := a.DetectLanguageWithConfidence("/home/something/list.h", cHeader)
+			l2, c2 := a.DetectLanguageWithConfidence("/home/something/Account.h", objectiveCHeader)
+			l3, c3 := a.DetectLanguageWithConfidence("/home/something/empty.m", []byte{})
+
+			// Assert
+			Expect(l1).To(Equal("C"))
+			Expect(c1).To(Equal(ConfidenceLow))
+			Expect(l2).To(Equal("Objective-C"))
+			Expect(c2).To(Equal(ConfidenceHigh))
+			Expect(l3).To(Equal("MATLAB"))
+			Expect(c3).To(Equal(ConfidenceLow))
+		})
+
+		It("should be more confident about the .m files which look like MATLAB", func() {
+			// Arrange
+			matlab, err := ioutil.ReadFile("./fixtures/matlab.fixture")
+			Expect(err).ToNot(HaveOccurred())
+
+			// Act
+			l1, c1 := a.DetectLanguageWithConfidence("/home/something/moving_average.m", matlab)
+			l2, c2 := a.DetectLanguageWithConfidence("/home/something/Report.mlx", []byte("PK\x03\x04"))
+
+			// Assert
+			Expect(l1).To(Equal("MATLAB"))
+			Expect(c1).To(Equal(ConfidenceMedium))
+			Expect(l2).To(Equal("MATLAB"))
+			Expect(c2).To(Equal(ConfidenceCertain))
+			Expect(a.ShouldExtractLibraries("mlx")).To(BeFalse())
+		})
+
+		It("should return with zero confidence if the language is unknown", func() {
+			// Act
+			l1, c1 := a.DetectLanguageWithConfidence("/home/something/LICENSE", []byte("MIT License\n"))
+
+			// Assert
+			Expect(l1).To(Equal(""))
+			Expect(c1).To(BeZero())
+		})
+	})
+
+	DescribeTable("Detect language by extension",
+		func(fileName, expectedLanguage string) {
+			Expect(a.Detect("/home/something/"+fileName, []byte{})).To(Equal(expectedLanguage))
+		},
+		Entry("Rust", "main.rs", "Rust"),
+		Entry("Scala", "App.scala", "Scala"),
+		Entry("Dart", "main.dart", "Dart"),
+		Entry("Elixir", "app.ex", "Elixir"),
+		Entry("Elixir script", "mix.exs", "Elixir"),
+		Entry("Haskell", "Main.hs", "Haskell"),
+		Entry("Lua", "init.lua", "Lua"),
+		Entry("R", "analysis.r", "R"),
+		Entry("R with uppercase extension", "analysis.R", "R"),
+		Entry("Julia", "model.jl", "Julia"),
+		Entry("Clojure", "core.clj", "Clojure"),
+		Entry("ClojureScript", "core.cljs", "Clojure"),
+		Entry("F#", "Program.fs", "F#"),
+		Entry("F# script", "build.fsx", "F#"),
+		Entry("Visual Basic", "Module1.vb", "Visual Basic"),
+		Entry("Erlang", "server.erl", "Erlang"),
+		Entry("OCaml", "main.ml", "OCaml"),
+		Entry("Nim", "main.nim", "Nim"),
+		Entry("Zig", "main.zig", "Zig"),
+		Entry("Solidity", "Token.sol", "Solidity"),
+		Entry("Shell", "install.sh", "Shell"),
+		Entry("Bash", "install.bash", "Shell"),
+	)
+
+	DescribeTable("Detect language by uppercase and compound extension",
+		func(fileName, expectedLanguage string) {
+			Expect(a.Detect("/home/something/"+fileName, []byte{})).To(Equal(expectedLanguage))
+		},
+		Entry("Python", "MAIN.PY", "Python"),
+		Entry("JavaScript", "App.JS", "JavaScript"),
+		Entry("C++", "engine.CPP", "C++"),
+		Entry("TypeScript declarations", "index.d.ts", "TypeScript"),
+		Entry("TypeScript declarations in uppercase", "INDEX.D.TS", "TypeScript"),
+		Entry("TypeScript test", "app.spec.ts", "TypeScript"),
+		Entry("archive", "release.tar.gz", ""),
+	)
+
+	It("should detect the language of ambiguous uppercase extensions by the content", func() {
+		Expect(a.Detect("/home/something/View.H", []byte("@interface View : NSObject\n@end\n"))).To(Equal("Objective-C"))
+	})
+
+	DescribeTable("FileExtension",
+		func(filePath, expectedExtension string) {
+			Expect(FileExtension(filePath)).To(Equal(expectedExtension))
+		},
+		Entry("simple extension", "/src/main.go", "go"),
+		Entry("uppercase extension", "/src/MAIN.PY", "py"),
+		Entry("TypeScript declarations", "/src/types/index.D.ts", "d.ts"),
+		Entry("file named like a compound extension", "/src/d.ts", "ts"),
+		Entry("archive", "/dist/release.tar.gz", "tar.gz"),
+		Entry("multiple dots", "/src/app.module.ts", "ts"),
+		Entry("no extension", "/src/Makefile", ""),
+		Entry("dotfile", "/home/.bashrc", ""),
+		Entry("dot in the directory", "/src/v1.2/README", ""),
+	)
+
+	It("should not extract the libraries of the TypeScript declarations", func() {
+		Expect(a.ShouldExtractLibraries("d.ts")).To(BeFalse())
+		Expect(a.ShouldExtractLibraries("D.TS")).To(BeFalse())
+		Expect(a.ShouldExtractLibraries("ts")).To(BeTrue())
+	})
+
+	It("should map every extension to a single language", func() {
+		languages := map[string]string{}
+		for language, extensions := range fileExtensionMap {
+			for _, extension := range extensions {
+				Expect(languages).ToNot(HaveKey(extension), "."+extension+" is mapped to "+language+" too")
+				languages[extension] = language
+			}
+		}
 	})
 })
@@ -2,6 +2,7 @@ package librarydetection
 
 import (
 	"fmt"
+	"path"
 )
 
 // Analyzer is an interface for extracting various features from files
@@ -29,3 +30,64 @@ func GetAnalyzer(language string) (Analyzer, error) {
 func AddAnalyzer(language string, analyzer Analyzer) {
 	analyzers[language] = analyzer
 }
+
+// ManifestAnalyzer is an interface for extracting dependencies from manifest files
+// like go.mod or package.json. Language specific implementations are at ./languages folder
+// The result is grouped by language, like "Go": ["github.com/pkg/errors@v0.9.1"]
+// The contents are never empty, the deleted manifests are not analysed.
+type ManifestAnalyzer interface {
+	ExtractDependencies(contents string) (map[string][]string, error)
+}
+
+// ManifestAnalyzers is the map for all manifest analyzers
+// like "go.mod" has "GoModAnalyzer" and so on.
+type ManifestAnalyzers map[string]ManifestAnalyzer
+
+var manifestAnalyzers = ManifestAnalyzers{}
+
+// GetManifestAnalyzer returns given manifest analyzer for that file name
+// The names are matched case-sensitively, like the tools read them, so a file called "description" is not
+// an R DESCRIPTION file.
+func GetManifestAnalyzer(fileName string) (ManifestAnalyzer, error) {
+	analyzer := manifestAnalyzers[fileName]
+	if analyzer == nil {
+		return nil, fmt.Errorf("no manifest analyzer for %s exists", fileName)
+	}
+	return analyzer, nil
+}
+
+// AddManifestAnalyzer allows users to add new manifest analyzers
+func AddManifestAnalyzer(fileName string, analyzer ManifestAnalyzer) {
+	manifestAnalyzers[fileName] = analyzer
+}
+
+// pathManifestAnalyzer is a manifest analyzer of the files matched by their path instead of their name
+type pathManifestAnalyzer struct {
+	pattern  string
+	analyzer ManifestAnalyzer
+}
+
+var pathManifestAnalyzers []pathManifestAnalyzer
+
+// GetPathManifestAnalyzer returns given manifest analyzer for the path of the file in the repository,
+// like ".github/workflows/ci.yml"
+func GetPathManifestAnalyzer(filePath string) (ManifestAnalyzer, error) {
+	for _, pathAnalyzer := range pathManifestAnalyzers {
+		if matched, _ := path.Match(pathAnalyzer.pattern, filePath); matched {
+			return pathAnalyzer.analyzer, nil
+		}
+	}
+	return nil, fmt.Errorf("no manifest analyzer for %s exists", filePath)
+}
+
+// AddPathManifestAnalyzer allows users to add new manifest analyzers of the files matched by the glob pattern
+// of their path in the repository, like ".github/workflows/*.yml"
+func AddPathManifestAnalyzer(pattern string, analyzer ManifestAnalyzer) {
+	for i, pathAnalyzer := range pathManifestAnalyzers {
+		if pathAnalyzer.pattern == pattern {
+			pathManifestAnalyzers[i].analyzer = analyzer
+			return
+		}
+	}
+	pathManifestAnalyzers = append(pathManifestAnalyzers, pathManifestAnalyzer{pattern: pattern, analyzer: analyzer})
+}
@@ -0,0 +1,61 @@
+package languages
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewAssemblyAnalyzer constructor
+func NewAssemblyAnalyzer() librarydetection.Analyzer {
+	return &assemblyAnalyzer{}
+}
+
+type assemblyAnalyzer struct{}
+
+// assemblyComments are the comments of NASM, MASM and of the GNU assembler files run through the C preprocessor
+// The "#" comments of the GNU assembler are not removed, as they would remove the #include directives too.
+var assemblyComments = commentSyntax{
+	lineComments:  []string{";", "//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"`},
+}
+
+// ExtractLibraries returns with the names of the included files, like "macros.inc" for %include "lib/macros.inc",
+// the system headers of the preprocessed files like "sys/syscall.h", and the libraries linked by MASM, like "kernel32"
+// for includelib kernel32.lib
+func (a *assemblyAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find the included files like %include "macros.inc" (NASM), .include "macros.s" (GNU) or
+	// include macros.inc (MASM)
+	includeRegex, err := regexp.Compile(`(?mi)^[ \t]*(?:%|\.)?include[ \t]+["']?([^"'\s;]+)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the headers included by the C preprocessor like #include <sys/syscall.h>
+	systemIncludeRegex, err := regexp.Compile(`(?m)^[ \t]*#[ \t]*include[ \t]*<([^>\n]+)>`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the libraries linked by MASM like includelib kernel32.lib
+	includeLibRegex, err := regexp.Compile(`(?mi)^[ \t]*includelib[ \t]+["']?([^"'\s;]+)`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = removeComments(contents, assemblyComments)
+
+	var res []string
+	for _, match := range includeRegex.FindAllStringSubmatch(contents, -1) {
+		res = append(res, path.Base(strings.Replace(match[1], "\\", "/", -1)))
+	}
+	for _, match := range systemIncludeRegex.FindAllStringSubmatch(contents, -1) {
+		res = append(res, strings.TrimSpace(match[1]))
+	}
+	for _, match := range includeLibRegex.FindAllStringSubmatch(contents, -1) {
+		library := path.Base(strings.Replace(match[1], "\\", "/", -1))
+		res = append(res, strings.TrimSuffix(strings.TrimSuffix(library, ".lib"), ".LIB"))
+	}
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("AssemblyLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/assembly.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"macros.inc",
+		"io.inc",
+		"startup.s",
+		"windows.inc",
+		"sys/syscall.h",
+		"kernel32",
+		"user32",
+	}
+
+	analyzer := languages.NewAssemblyAnalyzer()
+
+	Describe("Extract Assembly Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
@@ -2,22 +2,60 @@ package languages
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
 
+// CIncludeOptions configures which includes the C and C++ analyzers report
+type CIncludeOptions struct {
+	// LocalIncludes reports the quoted local headers like `#include "myheader.h"` too.
+	// By default only the angle-bracket includes like `#include <vector>` are reported as libraries.
+	LocalIncludes bool
+}
+
 // NewCAnalyzer constructor
 func NewCAnalyzer() librarydetection.Analyzer {
 	return &cAnalyzer{}
 }
 
-type cAnalyzer struct{}
+// NewCAnalyzerWithOptions constructor
+func NewCAnalyzerWithOptions(options CIncludeOptions) librarydetection.Analyzer {
+	return &cAnalyzer{options: options}
+}
+
+type cAnalyzer struct {
+	options CIncludeOptions
+}
 
 func (a *cAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	regex, err := regexp.Compile(`(?i)#include\s?[<"]([/a-zA-Z0-9.-]+)[">]`)
+	return extractCIncludes(contents, a.options)
+}
+
+// extractCIncludes is shared by the C and C++ analyzers.
+// Conditional includes inside #ifdef blocks are extracted as well.
+func extractCIncludes(contents string, options CIncludeOptions) ([]string, error) {
+	// regex to find system and external headers like #include <stdio.h>
+	systemRegex, err := regexp.Compile(`(?m)^[ \t]*#[ \t]*include[ \t]*<([^>\n]+)>`)
 	if err != nil {
 		return nil, err
 	}
+	regexes := []*regexp.Regexp{systemRegex}
+
+	if options.LocalIncludes {
+		// regex to find local headers like #include "myheader.h"
+		localRegex, err := regexp.Compile(`(?m)^[ \t]*#[ \t]*include[ \t]*"([^"\n]+)"`)
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, localRegex)
+	}
 
-	return executeRegexes(contents, []*regexp.Regexp{regex}), nil
+	contents = removeComments(contents, cStyleComments)
+
+	var res []string
+	for _, header := range executeRegexes(contents, regexes) {
+		res = append(res, strings.TrimSpace(header))
+	}
+	return res, nil
 }
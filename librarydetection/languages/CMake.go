@@ -0,0 +1,115 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewCMakeAnalyzer constructor
+func NewCMakeAnalyzer() librarydetection.Analyzer {
+	return &cmakeAnalyzer{}
+}
+
+type cmakeAnalyzer struct{}
+
+// cmakeLinkKeywords are the keywords of target_link_libraries, which are not libraries
+var cmakeLinkKeywords = map[string]bool{
+	"debug": true, "general": true, "interface": true, "link_interface_libraries": true, "link_private": true,
+	"link_public": true, "optimized": true, "private": true, "public": true,
+}
+
+// cmakePkgConfigKeywords are the keywords of pkg_check_modules and pkg_search_module, which are not modules
+var cmakePkgConfigKeywords = map[string]bool{
+	"global": true, "imported_target": true, "no_cmake_environment_path": true, "no_cmake_path": true, "quiet": true,
+	"required": true,
+}
+
+// ExtractLibraries returns with the packages found like "Boost" for find_package(Boost REQUIRED), the pkg-config
+// modules, the dependencies fetched at configure time, and the libraries linked to the targets like "fmt" for
+// target_link_libraries(app PRIVATE fmt::fmt). Every library is returned once.
+func (a *cmakeAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to remove the bracket comments like #[[ ... ]] and the line comments
+	commentRegex, err := regexp.Compile(`#\[\[[\s\S]*?\]\]|#.*`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the commands with the dependencies or the targets, the commands are case-insensitive
+	commandRegex, err := regexp.Compile(`(?i)\b(find_package|pkg_check_modules|pkg_search_module|fetchcontent_declare|externalproject_add|target_link_libraries|add_library|add_executable)\s*\(([^)]*)\)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to remove the version constraints of the pkg-config modules like glib-2.0>=2.56
+	versionRegex, err := regexp.Compile(`[<>=].*$`)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := commandRegex.FindAllStringSubmatch(commentRegex.ReplaceAllString(contents, ""), -1)
+
+	// the targets of the project are linked like the libraries, they can be defined after they are used
+	targets := map[string]bool{}
+	for _, match := range matches {
+		command := strings.ToLower(match[1])
+		if command != "add_library" && command != "add_executable" {
+			continue
+		}
+		// the aliases like add_library(MyLib::core ALIAS core) are targets too
+		if args := cmakeArguments(match[2]); len(args) > 0 {
+			targets[args[0]] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	res := []string{}
+	add := func(library string) {
+		// the variables like ${LIBS} and the generator expressions like $<TARGET_FILE:app> can not be resolved
+		if library == "" || strings.Contains(library, "$") || seen[library] {
+			return
+		}
+		seen[library] = true
+		res = append(res, library)
+	}
+	for _, match := range matches {
+		args := cmakeArguments(match[2])
+		if len(args) == 0 {
+			continue
+		}
+		switch strings.ToLower(match[1]) {
+		case "find_package", "fetchcontent_declare", "externalproject_add":
+			add(args[0])
+		case "pkg_check_modules", "pkg_search_module":
+			// the first argument is the prefix of the variables set
+			for _, module := range args[1:] {
+				if !cmakePkgConfigKeywords[strings.ToLower(module)] {
+					add(versionRegex.ReplaceAllString(module, ""))
+				}
+			}
+		case "target_link_libraries":
+			// the first argument is the target the libraries are linked to
+			for _, library := range args[1:] {
+				// the paths of the libraries and the linker flags other than -lname are left out
+				if cmakeLinkKeywords[strings.ToLower(library)] || targets[library] || strings.Contains(library, "/") ||
+					(strings.HasPrefix(library, "-") && !strings.HasPrefix(library, "-l")) {
+					continue
+				}
+				// the imported targets like Boost::filesystem belong to the package
+				if separator := strings.Index(library, "::"); separator != -1 {
+					library = library[:separator]
+				}
+				add(strings.TrimPrefix(library, "-l"))
+			}
+		}
+	}
+	return res, nil
+}
+
+// cmakeArguments splits the arguments of a command, the quotes are removed
+func cmakeArguments(arguments string) []string {
+	fields := strings.Fields(arguments)
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, `"`)
+	}
+	return fields
+}
@@ -0,0 +1,40 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("CMakeLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/cmake.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"Boost",
+		"OpenSSL",
+		"Threads",
+		"PkgConfig",
+		"glib-2.0",
+		"gio-2.0",
+		"fmt",
+		"sqlite3",
+		"z",
+	}
+
+	analyzer := languages.NewCMakeAnalyzer()
+
+	Describe("Extract CMake libraries", func() {
+		It("Should be able to extract the packages and the linked libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
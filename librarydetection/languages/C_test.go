@@ -16,20 +16,25 @@ var _ = Describe("CLibraryDetection", func() {
 
 	expectedLibraries := []string{
 		"assert.h",
-		"complex.h",
 		"ctype.h",
-		"stdio.h",
 		"float.h",
-		"string.h",
-		"hey/sup/iomanip.h",
 		"Hey/ssup/math.h",
-		"hello/how/stdlib.h",
 		"great/wchar.h",
-		"stdbool.h",
 		"stdint.h",
-		"hello12",
 		"WsSup34",
 		"heyYo3-lol",
+		"windows.h",
+		"unistd.h",
+	}
+
+	expectedLocalIncludes := []string{
+		"complex.h",
+		"stdio.h",
+		"string.h",
+		"hey/sup/iomanip.h",
+		"hello/how/stdlib.h",
+		"stdbool.h",
+		"hello12",
 	}
 
 	analyzer := languages.NewCAnalyzer()
@@ -42,5 +47,13 @@ var _ = Describe("CLibraryDetection", func() {
 			}
 			assertSameUnordered(libs, expectedLibraries)
 		})
+
+		It("Should extract the local includes with the option", func() {
+			libs, err := languages.NewCAnalyzerWithOptions(languages.CIncludeOptions{LocalIncludes: true}).ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, append(expectedLibraries, expectedLocalIncludes...))
+		})
 	})
 })
@@ -1,23 +1,23 @@
 package languages
 
 import (
-	"regexp"
-
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
 
-// NewCAnalyzer constructor
+// NewCppAnalyzer constructor
 func NewCppAnalyzer() librarydetection.Analyzer {
 	return &cppAnalyzer{}
 }
 
-type cppAnalyzer struct{}
+// NewCppAnalyzerWithOptions constructor
+func NewCppAnalyzerWithOptions(options CIncludeOptions) librarydetection.Analyzer {
+	return &cppAnalyzer{options: options}
+}
 
-func (a *cppAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	regex, err := regexp.Compile(`(?i)#include\s?[<"]([/a-zA-Z0-9.-]+)[">]`)
-	if err != nil {
-		return nil, err
-	}
+type cppAnalyzer struct {
+	options CIncludeOptions
+}
 
-	return executeRegexes(contents, []*regexp.Regexp{regex}), nil
+func (a *cppAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	return extractCIncludes(contents, a.options)
 }
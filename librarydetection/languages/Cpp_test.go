@@ -8,13 +8,18 @@ import (
 	"github.com/Techloopio/extractor_tool/librarydetection/languages"
 )
 
-var _ = Describe("CLibraryDetection", func() {
+var _ = Describe("CppLibraryDetection", func() {
 	fixture, err := ioutil.ReadFile("./fixtures/cpp.fixture")
 	if err != nil {
 		panic(err)
 	}
 
 	expectedLibraries := []string{
+		"vector",
+		"boost/asio.hpp",
+	}
+
+	expectedLocalIncludes := []string{
 		"common.h",
 		"Accident.h",
 		"Ped.h",
@@ -24,7 +29,7 @@ var _ = Describe("CLibraryDetection", func() {
 
 	analyzer := languages.NewCppAnalyzer()
 
-	Describe("Extract C Libraries", func() {
+	Describe("Extract C++ Libraries", func() {
 		It("Should be able to extract libraries", func() {
 			libs, err := analyzer.ExtractLibraries(string(fixture))
 			if err != nil {
@@ -32,5 +37,13 @@ var _ = Describe("CLibraryDetection", func() {
 			}
 			assertSameUnordered(libs, expectedLibraries)
 		})
+
+		It("Should extract the local includes with the option", func() {
+			libs, err := languages.NewCppAnalyzerWithOptions(languages.CIncludeOptions{LocalIncludes: true}).ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, append(expectedLibraries, expectedLocalIncludes...))
+		})
 	})
 })
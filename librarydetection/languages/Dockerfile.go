@@ -0,0 +1,72 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewDockerfileAnalyzer constructor
+func NewDockerfileAnalyzer() librarydetection.Analyzer {
+	return &dockerfileAnalyzer{}
+}
+
+type dockerfileAnalyzer struct{}
+
+func (a *dockerfileAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find build arguments like ARG BASE_IMAGE=golang:1.16
+	argRegex, err := regexp.Compile(`(?i)^ARG\s+([a-zA-Z0-9_]+)=["']?([^"'\s]*)["']?`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find base images like FROM --platform=linux/amd64 golang:1.16 AS build
+	fromRegex, err := regexp.Compile(`(?i)^FROM\s+(?:--\S+\s+)*(\S+)(?:\s+AS\s+(\S+))?`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find variables like $BASE_IMAGE or ${BASE_IMAGE}
+	variableRegex, err := regexp.Compile(`\$\{?([a-zA-Z0-9_]+)\}?`)
+	if err != nil {
+		return nil, err
+	}
+
+	args := map[string]string{}
+	stages := map[string]bool{}
+	images := []string{}
+
+	// Instructions can be continued in the next line with a backslash
+	contents = strings.Replace(contents, "\\\r\n", " ", -1)
+	contents = strings.Replace(contents, "\\\n", " ", -1)
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+
+		if match := argRegex.FindStringSubmatch(line); match != nil {
+			args[match[1]] = match[2]
+			continue
+		}
+
+		match := fromRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		image := variableRegex.ReplaceAllStringFunc(match[1], func(variable string) string {
+			name := variableRegex.FindStringSubmatch(variable)[1]
+			if value, ok := args[name]; ok {
+				return value
+			}
+			return variable
+		})
+		if match[2] != "" {
+			stages[strings.ToLower(match[2])] = true
+		}
+
+		// previous build stages and the empty image are not dependencies
+		if strings.ToLower(image) == "scratch" || (stages[strings.ToLower(image)] && !strings.EqualFold(image, match[2])) {
+			continue
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
@@ -0,0 +1,35 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("DockerfileLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/dockerfile.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"golang:1.16-alpine",
+		"node:14",
+		"alpine:3.13",
+		"nginx:1.19",
+	}
+
+	analyzer := languages.NewDockerfileAnalyzer()
+
+	Describe("Extract Dockerfile base images", func() {
+		It("Should be able to extract base images", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
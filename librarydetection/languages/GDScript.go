@@ -0,0 +1,63 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewGDScriptAnalyzer constructor
+func NewGDScriptAnalyzer() librarydetection.Analyzer {
+	return &gdScriptAnalyzer{}
+}
+
+type gdScriptAnalyzer struct{}
+
+// ExtractLibraries returns with the resources loaded by the script, like "scenes/player.tscn" for
+// preload("res://scenes/player.tscn"), and the base classes it extends, like "CharacterBody2D".
+// The resources of the addons are reported as the addon, like "addons/dialogic" for
+// load("res://addons/dialogic/Other/DialogicClass.gd").
+func (a *gdScriptAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find the resources like preload("res://player.tscn"), load('res://icon.png') or ResourceLoader.load(...)
+	loadRegex, err := regexp.Compile(`\b(?:pre)?load\s*\(\s*["']([^"'\n]+)["']`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the base classes like extends Node2D or extends "res://enemy.gd"
+	extendsRegex, err := regexp.Compile(`(?m)^[ \t]*extends[ \t]+(?:["']([^"'\n]+)["']|([A-Za-z_][\w.]*))`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = removeComments(contents, pythonComments)
+
+	var res []string
+	for _, match := range loadRegex.FindAllStringSubmatch(contents, -1) {
+		if resource := gdScriptResource(match[1]); resource != "" {
+			res = append(res, resource)
+		}
+	}
+	for _, match := range extendsRegex.FindAllStringSubmatch(contents, -1) {
+		if match[2] != "" {
+			res = append(res, match[2])
+		} else if resource := gdScriptResource(match[1]); resource != "" {
+			res = append(res, resource)
+		}
+	}
+	return res, nil
+}
+
+// gdScriptResource returns with the path of the resource in the project without the "res://" prefix
+// The files of the user like "user://save.dat" are not dependencies, an empty string is returned for them.
+func gdScriptResource(resourcePath string) string {
+	if strings.Contains(resourcePath, "://") && !strings.HasPrefix(resourcePath, "res://") {
+		return ""
+	}
+	resourcePath = strings.TrimPrefix(strings.TrimPrefix(resourcePath, "res://"), "./")
+	segments := strings.Split(resourcePath, "/")
+	if len(segments) > 2 && segments[0] == "addons" {
+		return "addons/" + segments[1]
+	}
+	return resourcePath
+}
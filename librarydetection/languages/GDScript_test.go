@@ -0,0 +1,37 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("GDScriptLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/gdscript.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"CharacterBody2D",
+		"scenes/bullet.tscn",
+		"inventory.gd",
+		"addons/dialogic",
+		"assets/icon.png",
+		"scripts/enemy.gd",
+	}
+
+	analyzer := languages.NewGDScriptAnalyzer()
+
+	Describe("Extract GDScript Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewGitHubActionsAnalyzer constructor
+// The dependencies of the workflows are returned under the "CI" key.
+func NewGitHubActionsAnalyzer() librarydetection.ManifestAnalyzer {
+	return &gitHubActionsAnalyzer{}
+}
+
+type gitHubActionsAnalyzer struct{}
+
+// yamlKey is a key of a YAML mapping with its indentation
+type yamlKey struct {
+	indent int
+	name   string
+}
+
+// ExtractDependencies returns with the actions and the reusable workflows used with their refs, like
+// "actions/checkout@v4", and the images of the job containers and the service containers, like "postgres:15"
+// The workflow is parsed line by line, the flow style mappings like container: { image: node:18 } are not supported.
+func (a *gitHubActionsAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find the keys like "uses: actions/checkout@v4", "- image: node:18" or "services:"
+	keyRegex, err := regexp.Compile(`^(\s*)((?:-\s+)?)([\w-]+|"[^"]*"|'[^']*')\s*:(?:\s+(.*))?$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to remove the comments at the end of the values like "actions/checkout@v4 # v4.1.1"
+	commentRegex, err := regexp.Compile(`\s+#.*$`)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	var parents []yamlKey
+	// the lines of the block scalars like "run: |" are skipped, they are more indented than their key
+	blockScalarIndent := -1
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockScalarIndent != -1 {
+			if trimmed == "" || indent > blockScalarIndent {
+				continue
+			}
+			blockScalarIndent = -1
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		match := keyRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		// the keys of the list items like "- uses: actions/checkout@v4" are indented by the dash
+		keyIndent := len(match[1]) + len(match[2])
+		name := strings.Trim(match[3], `"'`)
+		value := strings.Trim(commentRegex.ReplaceAllString(strings.TrimSpace(match[4]), ""), `"'`)
+
+		for len(parents) > 0 && parents[len(parents)-1].indent >= keyIndent {
+			parents = parents[:len(parents)-1]
+		}
+		if value == "" {
+			parents = append(parents, yamlKey{indent: keyIndent, name: name})
+			continue
+		}
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockScalarIndent = keyIndent
+			continue
+		}
+		// the expressions like ${{ matrix.image }} are only known when the workflow runs
+		if strings.Contains(value, "${{") {
+			continue
+		}
+
+		switch {
+		case name == "uses":
+			if dependency := actionDependency(value); dependency != "" {
+				deps = append(deps, dependency)
+			}
+		case name == "container" && isWorkflowJobKey(parents):
+			deps = append(deps, value)
+		case name == "image" && isWorkflowImageParent(parents):
+			deps = append(deps, value)
+		}
+	}
+	return map[string][]string{"CI": deps}, nil
+}
+
+// actionDependency returns with the action or the reusable workflow used, like "actions/checkout@v4" or
+// "octo-org/ci/.github/workflows/build.yml@main", or the image of a Docker action like "alpine:3.18" for
+// "docker://alpine:3.18". The local actions and workflows of the repository like "./.github/actions/setup" are left out.
+func actionDependency(uses string) string {
+	if strings.HasPrefix(uses, "docker://") {
+		return strings.TrimPrefix(uses, "docker://")
+	}
+	if strings.HasPrefix(uses, "./") || !strings.Contains(uses, "/") {
+		return ""
+	}
+	return uses
+}
+
+// isWorkflowJobKey tells if the key belongs to a job, like jobs.build.container
+func isWorkflowJobKey(parents []yamlKey) bool {
+	return len(parents) == 2 && parents[0].name == "jobs"
+}
+
+// isWorkflowImageParent tells if the image belongs to the container of a job, like jobs.build.container.image,
+// or to a service container, like jobs.build.services.postgres.image
+func isWorkflowImageParent(parents []yamlKey) bool {
+	if len(parents) == 3 {
+		return parents[0].name == "jobs" && parents[2].name == "container"
+	}
+	return len(parents) == 4 && parents[0].name == "jobs" && parents[2].name == "services"
+}
@@ -0,0 +1,43 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("GitHubActionsDependencyDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/githubworkflow.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedDependencies := []string{
+		"node:18-bullseye",
+		"postgres:15",
+		"redis:7-alpine",
+		"actions/checkout@v4",
+		"actions/setup-go@v5",
+		"golangci/golangci-lint:v1.55",
+		"codecov/codecov-action@v3",
+		"mcr.microsoft.com/playwright:v1.40.0",
+		"actions/checkout@v4",
+		"octo-org/shared-workflows/.github/workflows/deploy.yml@v2",
+	}
+
+	analyzer := languages.NewGitHubActionsAnalyzer()
+
+	Describe("Extract GitHub Actions dependencies", func() {
+		It("Should be able to extract the actions, the reusable workflows and the images with versions", func() {
+			deps, err := analyzer.ExtractDependencies(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			Expect(deps).To(HaveKey("CI"))
+			assertSameUnordered(deps["CI"], expectedDependencies)
+		})
+	})
+})
@@ -3,6 +3,7 @@ package languages
 import (
 	"github.com/Techloopio/extractor_tool/librarydetection"
 	"regexp"
+	"strings"
 )
 
 // NewGoAnalyzer constructor
@@ -10,10 +11,22 @@ func NewGoAnalyzer() librarydetection.Analyzer {
 	return &goAnalyzer{}
 }
 
+// NewGoAnalyzerWithOptions constructor
+func NewGoAnalyzerWithOptions(options GoOptions) librarydetection.Analyzer {
+	return &goAnalyzer{options: options}
+}
+
 type goAnalyzer struct {
+	options GoOptions
 }
 
 func (a *goAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// Files built for other platforms, like Windows-only files on Linux, are skipped
+	if a.options.isTargeted() && !a.options.matchesBuildConstraints(contents) {
+		return []string{}, nil
+	}
+	contents = removeComments(contents, cStyleComments)
+
 	// regex for multiline imports
 	regex1, err := regexp.Compile(`(?msi)import\s*\(\s*(.*?)\s*\)`)
 	if err != nil {
@@ -59,5 +72,22 @@ func (a *goAnalyzer) ExtractLibraries(contents string) ([]string, error) {
 
 	allLibs = append(allLibs, executeRegexes(contents, regexes)...)
 
+	if a.options.ExcludeStandardLibrary {
+		thirdPartyLibs := []string{}
+		for _, lib := range allLibs {
+			if !IsGoStandardLibrary(lib) {
+				thirdPartyLibs = append(thirdPartyLibs, lib)
+			}
+		}
+		return thirdPartyLibs, nil
+	}
 	return allLibs, nil
 }
+
+// IsGoStandardLibrary tells if the import path belongs to the standard library like "fmt" or "net/http"
+// The first path segment of the standard library packages has no dot, unlike the modules like "github.com/pkg/errors".
+// The pseudo package "C" of cgo is treated as part of the standard library.
+func IsGoStandardLibrary(importPath string) bool {
+	firstSegment := strings.SplitN(importPath, "/", 2)[0]
+	return !strings.Contains(firstSegment, ".")
+}
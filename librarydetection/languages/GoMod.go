@@ -0,0 +1,50 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewGoModAnalyzer constructor
+func NewGoModAnalyzer() librarydetection.ManifestAnalyzer {
+	return &goModAnalyzer{}
+}
+
+type goModAnalyzer struct{}
+
+func (a *goModAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex for multiline requires
+	regex1, err := regexp.Compile(`(?ms)^require\s*\(\s*(.*?)\s*^\)`)
+	if err != nil {
+		return nil, err
+	}
+
+	// regex for single line requires like this: require github.com/user/repo v1.0.0
+	regex2, err := regexp.Compile(`(?m)^require[\t ]+([^\s(]+[\t ]+[^\s]+)`)
+	if err != nil {
+		return nil, err
+	}
+
+	var requirements []string
+	for _, block := range executeRegexes(contents, []*regexp.Regexp{regex1}) {
+		requirements = append(requirements, strings.Split(block, "\n")...)
+	}
+	requirements = append(requirements, executeRegexes(contents, []*regexp.Regexp{regex2})...)
+
+	deps := []string{}
+	for _, requirement := range requirements {
+		// remove comments like "// indirect"
+		if commentStart := strings.Index(requirement, "//"); commentStart != -1 {
+			requirement = requirement[:commentStart]
+		}
+		fields := strings.Fields(requirement)
+		if len(fields) != 2 {
+			continue
+		}
+		deps = append(deps, fields[0]+"@"+fields[1])
+	}
+
+	return map[string][]string{"Go": deps}, nil
+}
@@ -0,0 +1,37 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("GoModDependencyDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/gomod.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedDependencies := []string{
+		"github.com/pkg/errors@v0.9.1",
+		"github.com/spf13/cobra@v1.1.3",
+		"golang.org/x/text@v0.3.3",
+		"gopkg.in/yaml.v2@v2.4.0",
+	}
+
+	analyzer := languages.NewGoModAnalyzer()
+
+	Describe("Extract go.mod dependencies", func() {
+		It("Should be able to extract dependencies with versions", func() {
+			deps, err := analyzer.ExtractDependencies(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			Expect(deps).To(HaveKey("Go"))
+			assertSameUnordered(deps["Go"], expectedDependencies)
+		})
+	})
+})
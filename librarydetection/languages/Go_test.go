@@ -2,6 +2,7 @@ package languages_test
 
 import (
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 	"io/ioutil"
 
 	"github.com/Techloopio/extractor_tool/librarydetection/languages"
@@ -46,4 +47,88 @@ var _ = Describe("GoLibraryDetection", func() {
 			assertSameUnordered(libs, expectedLibraries)
 		})
 	})
+	Describe("Honor build constraints", func() {
+		windowsOnly := "//go:build windows && amd64\n\npackage registry\n\nimport \"golang.org/x/sys/windows/registry\"\n"
+		legacyUnix := "// +build linux darwin\n// +build !cgo\n\npackage term\n\nimport \"golang.org/x/sys/unix\"\n"
+		notWindows := "//go:build !windows\n\npackage term\n\nimport \"golang.org/x/sys/unix\"\n"
+		integration := "//go:build integration\n\npackage store\n\nimport \"github.com/lib/pq\"\n"
+
+		It("Should extract the imports of every file by default", func() {
+			libs, err := analyzer.ExtractLibraries(windowsOnly)
+			Expect(err).To(BeNil())
+			Expect(libs).To(Equal([]string{"golang.org/x/sys/windows/registry"}))
+		})
+
+		It("Should skip the files built for other platforms", func() {
+			linuxAnalyzer := languages.NewGoAnalyzerWithOptions(languages.GoOptions{GOOS: "linux", GOARCH: "amd64"})
+
+			libs, err := linuxAnalyzer.ExtractLibraries(windowsOnly)
+			Expect(err).To(BeNil())
+			Expect(libs).To(BeEmpty())
+
+			libs, err = linuxAnalyzer.ExtractLibraries(legacyUnix)
+			Expect(err).To(BeNil())
+			Expect(libs).To(Equal([]string{"golang.org/x/sys/unix"}))
+
+			libs, err = linuxAnalyzer.ExtractLibraries(integration)
+			Expect(err).To(BeNil())
+			Expect(libs).To(BeEmpty())
+		})
+
+		It("Should match every operating system if only the architecture is set", func() {
+			arm64Analyzer := languages.NewGoAnalyzerWithOptions(languages.GoOptions{GOARCH: "arm64"})
+
+			libs, err := arm64Analyzer.ExtractLibraries(notWindows)
+			Expect(err).To(BeNil())
+			Expect(libs).To(Equal([]string{"golang.org/x/sys/unix"}))
+
+			libs, err = arm64Analyzer.ExtractLibraries(windowsOnly)
+			Expect(err).To(BeNil())
+			Expect(libs).To(BeEmpty())
+		})
+
+		It("Should evaluate the negated groups like the negated terms", func() {
+			arm64Analyzer := languages.NewGoAnalyzerWithOptions(languages.GoOptions{GOARCH: "arm64"})
+
+			libs, err := arm64Analyzer.ExtractLibraries("//go:build !(windows)\n\npackage term\n\nimport \"golang.org/x/sys/unix\"\n")
+			Expect(err).To(BeNil())
+			Expect(libs).To(Equal([]string{"golang.org/x/sys/unix"}))
+
+			libs, err = arm64Analyzer.ExtractLibraries("//go:build linux && !linux\n\npackage term\n\nimport \"golang.org/x/sys/unix\"\n")
+			Expect(err).To(BeNil())
+			Expect(libs).To(BeEmpty())
+		})
+
+		It("Should satisfy the custom build tags", func() {
+			tagAnalyzer := languages.NewGoAnalyzerWithOptions(languages.GoOptions{BuildTags: []string{"integration"}})
+
+			libs, err := tagAnalyzer.ExtractLibraries(integration)
+			Expect(err).To(BeNil())
+			Expect(libs).To(Equal([]string{"github.com/lib/pq"}))
+		})
+	})
+	Describe("Separate the standard library", func() {
+		contents := "package main\n\nimport (\n\t\"fmt\"\n\t\"net/http\"\n\n\t\"github.com/foo/bar\"\n)\n"
+
+		It("Should tell the standard library from the third-party modules", func() {
+			Expect(languages.IsGoStandardLibrary("fmt")).To(BeTrue())
+			Expect(languages.IsGoStandardLibrary("net/http")).To(BeTrue())
+			Expect(languages.IsGoStandardLibrary("github.com/foo/bar")).To(BeFalse())
+			Expect(languages.IsGoStandardLibrary("gopkg.in/yaml.v2")).To(BeFalse())
+		})
+
+		It("Should extract the standard library by default", func() {
+			libs, err := analyzer.ExtractLibraries(contents)
+			Expect(err).To(BeNil())
+			assertSameUnordered(libs, []string{"fmt", "net/http", "github.com/foo/bar"})
+		})
+
+		It("Should drop the standard library if it is excluded", func() {
+			thirdPartyAnalyzer := languages.NewGoAnalyzerWithOptions(languages.GoOptions{ExcludeStandardLibrary: true})
+
+			libs, err := thirdPartyAnalyzer.ExtractLibraries(contents)
+			Expect(err).To(BeNil())
+			Expect(libs).To(Equal([]string{"github.com/foo/bar"}))
+		})
+	})
 })
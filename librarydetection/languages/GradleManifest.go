@@ -0,0 +1,88 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewGradleAnalyzer constructor
+// It is used for both build.gradle (Groovy DSL) and build.gradle.kts (Kotlin DSL).
+func NewGradleAnalyzer() librarydetection.ManifestAnalyzer {
+	return &gradleAnalyzer{}
+}
+
+type gradleAnalyzer struct{}
+
+// gradleConfigurations are the dependency configurations whose dependencies are extracted
+const gradleConfigurations = `implementation|api|compileOnly|runtimeOnly|testImplementation|testCompileOnly|testRuntimeOnly|annotationProcessor|kapt|compile|testCompile|runtime`
+
+// ExtractDependencies returns with the dependencies like "com.squareup.okhttp3:okhttp:4.9.3"
+// under "Kotlin" if the project applies the Kotlin plugin, otherwise under "Java".
+// Versions like $okhttpVersion are resolved from the variables defined in the same file.
+func (a *gradleAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find dependencies like implementation 'group:artifact:version' or implementation("group:artifact:version")
+	stringNotationRegex, err := regexp.Compile(`(?m)^\s*(?:` + gradleConfigurations + `)\s*\(?\s*(?:platform\(\s*)?['"]([^'"\s:]+:[^'"\s:]+(?::[^'"\s]+)?)['"]`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find dependencies like implementation group: 'group', name: 'artifact', version: 'version'
+	// or implementation(group = "group", name = "artifact", version = "version")
+	mapNotationRegex, err := regexp.Compile(`(?m)^\s*(?:` + gradleConfigurations + `)\s*\(?\s*group\s*[:=]\s*['"]([^'"]+)['"]\s*,\s*name\s*[:=]\s*['"]([^'"]+)['"](?:\s*,\s*version\s*[:=]\s*['"]([^'"]+)['"])?`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find variables like def okhttpVersion = '4.9.3', val okhttpVersion = "4.9.3" or ext.okhttpVersion = '4.9.3'
+	variableRegex, err := regexp.Compile(`(?m)^\s*(?:def|val|var|ext\.|extra\[?)\s*["']?([A-Za-z_][A-Za-z0-9_.]*)["']?\]?\s*=\s*['"]([^'"$]+)['"]`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find variable references like $okhttpVersion or ${okhttpVersion}
+	referenceRegex, err := regexp.Compile(`\$\{?([A-Za-z_][A-Za-z0-9_.]*)\}?`)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]string{}
+	for _, match := range variableRegex.FindAllStringSubmatch(contents, -1) {
+		variables[match[1]] = match[2]
+	}
+	resolve := func(value string) string {
+		return referenceRegex.ReplaceAllStringFunc(value, func(reference string) string {
+			name := strings.Trim(reference, "${}")
+			if resolved, ok := variables[name]; ok {
+				return resolved
+			}
+			return reference
+		})
+	}
+
+	deps := []string{}
+	for _, match := range stringNotationRegex.FindAllStringSubmatch(contents, -1) {
+		deps = append(deps, resolve(match[1]))
+	}
+	for _, match := range mapNotationRegex.FindAllStringSubmatch(contents, -1) {
+		coordinates := match[1] + ":" + match[2]
+		if match[3] != "" {
+			coordinates += ":" + match[3]
+		}
+		deps = append(deps, resolve(coordinates))
+	}
+
+	language := "Java"
+	if isKotlinGradleProject(contents) {
+		language = "Kotlin"
+	}
+	return map[string][]string{language: deps}, nil
+}
+
+// isKotlinGradleProject tells if the build file applies the Kotlin plugin
+func isKotlinGradleProject(contents string) bool {
+	return strings.Contains(contents, "org.jetbrains.kotlin") ||
+		strings.Contains(contents, "kotlin(\"jvm\")") ||
+		strings.Contains(contents, "kotlin(\"android\")") ||
+		strings.Contains(contents, "kotlin(\"multiplatform\")") ||
+		strings.Contains(contents, "apply plugin: 'kotlin") ||
+		strings.Contains(contents, "id 'kotlin")
+}
@@ -0,0 +1,59 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("GradleDependencyDetection", func() {
+	groovyFixture, err := ioutil.ReadFile("./fixtures/buildgradle.fixture")
+	if err != nil {
+		panic(err)
+	}
+	kotlinFixture, err := ioutil.ReadFile("./fixtures/buildgradlekts.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	analyzer := languages.NewGradleAnalyzer()
+
+	Describe("Extract build.gradle dependencies", func() {
+		It("Should be able to extract the dependencies of the Groovy DSL under Java", func() {
+			deps, err := analyzer.ExtractDependencies(string(groovyFixture))
+			if err != nil {
+				panic(err)
+			}
+			Expect(deps).NotTo(HaveKey("Kotlin"))
+			assertSameUnordered(deps["Java"], []string{
+				"org.springframework.boot:spring-boot-starter-web",
+				"com.squareup.okhttp3:okhttp:4.9.3",
+				"com.google.guava:guava:31.0.1-jre",
+				"org.projectlombok:lombok:1.18.22",
+				"org.projectlombok:lombok:1.18.22",
+				"org.postgresql:postgresql:42.3.1",
+				"org.junit.jupiter:junit-jupiter:5.8.1",
+			})
+		})
+	})
+
+	Describe("Extract build.gradle.kts dependencies", func() {
+		It("Should be able to extract the dependencies of the Kotlin DSL under Kotlin", func() {
+			deps, err := analyzer.ExtractDependencies(string(kotlinFixture))
+			if err != nil {
+				panic(err)
+			}
+			Expect(deps).NotTo(HaveKey("Java"))
+			assertSameUnordered(deps["Kotlin"], []string{
+				"io.ktor:ktor-server-netty:1.6.7",
+				"org.jetbrains.kotlinx:kotlinx-coroutines-bom:1.5.2",
+				"com.squareup.moshi:moshi:1.13.0",
+				"org.slf4j:slf4j-api:1.7.32",
+				"io.mockk:mockk:1.12.1",
+			})
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// groovyComments are the comments of Groovy
+// The multi-line strings are removed too, as the scripts often embed other scripts in them.
+var groovyComments = commentSyntax{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"`, `'`},
+	docStrings:    []string{`"""`, `'''`},
+}
+
+// NewGroovyAnalyzer constructor
+// It is used for both the Groovy files and the Jenkinsfiles.
+func NewGroovyAnalyzer() librarydetection.Analyzer {
+	return &groovyAnalyzer{}
+}
+
+type groovyAnalyzer struct{}
+
+// ExtractLibraries returns with the packages of the imports like "org.yaml.snakeyaml" for import org.yaml.snakeyaml.Yaml,
+// and the coordinates of the Grape dependencies like "org.apache.commons:commons-lang3:3.12.0"
+// for @Grab('org.apache.commons:commons-lang3:3.12.0'). The packages of the JDK and Groovy itself are not reported.
+func (a *groovyAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find imports like `import foo.bar.Baz`, `import foo.bar.Baz as Qux` or `import foo.bar.*`
+	importRegex, err := regexp.Compile(`(?m)^[ \t]*import[ \t]+([a-zA-Z0-9_.]+(?:\.\*)?)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find static imports like `import static foo.bar.Baz.qux`
+	staticImportRegex, err := regexp.Compile(`(?m)^[ \t]*import[ \t]+static[ \t]+([a-zA-Z0-9_.]+(?:\.\*)?)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the short notation of the Grape dependencies like @Grab('group:module:version')
+	// or @Grab(value = "group:module:version")
+	grabRegex, err := regexp.Compile(`@(?:groovy\.lang\.)?Grab\s*\(\s*(?:value\s*=\s*)?['"]([^'"\s]+)['"]`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the named parameters of the Grape dependencies like @Grab(group='g', module='m', version='v')
+	grabParametersRegex, err := regexp.Compile(`@(?:groovy\.lang\.)?Grab\s*\(([^)]*\b(?:group|module)\s*=[^)]*)\)`)
+	if err != nil {
+		return nil, err
+	}
+	parameterRegex, err := regexp.Compile(`\b(group|module|version)\s*=\s*['"]([^'"]+)['"]`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = removeComments(contents, groovyComments)
+
+	var res []string
+	for _, importPath := range executeRegexes(contents, []*regexp.Regexp{importRegex}) {
+		if importPath == "static" {
+			continue
+		}
+		res = appendGroovyPackage(res, importPath)
+	}
+	for _, importPath := range executeRegexes(contents, []*regexp.Regexp{staticImportRegex}) {
+		// static imports end with a member (or *) of a class, the package is the one of the class
+		memberStart := strings.LastIndex(importPath, ".")
+		if memberStart == -1 {
+			continue
+		}
+		res = appendGroovyPackage(res, importPath[:memberStart])
+	}
+
+	res = append(res, executeRegexes(contents, []*regexp.Regexp{grabRegex})...)
+	for _, parameters := range executeRegexes(contents, []*regexp.Regexp{grabParametersRegex}) {
+		values := map[string]string{}
+		for _, match := range parameterRegex.FindAllStringSubmatch(parameters, -1) {
+			values[match[1]] = match[2]
+		}
+		if values["group"] == "" || values["module"] == "" {
+			continue
+		}
+		coordinates := values["group"] + ":" + values["module"]
+		if values["version"] != "" {
+			coordinates += ":" + values["version"]
+		}
+		res = append(res, coordinates)
+	}
+
+	return res, nil
+}
+
+// appendGroovyPackage appends the package of the import, unless it is part of the JDK or Groovy
+func appendGroovyPackage(libraries []string, importPath string) []string {
+	for _, prefix := range []string{"java.", "javax.", "groovy.", "org.codehaus.groovy."} {
+		if strings.HasPrefix(importPath, prefix) {
+			return libraries
+		}
+	}
+	if pkg := jvmImportPackage(importPath); pkg != "" {
+		libraries = append(libraries, pkg)
+	}
+	return libraries
+}
@@ -0,0 +1,40 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("GroovyLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/groovy.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"org.apache.commons.lang3",
+		"com.squareup.okhttp3",
+		"org.yaml.snakeyaml",
+		"io.github.httpbuilderng",
+		"org.junit",
+		"org.apache.commons:commons-lang3:3.12.0",
+		"org.yaml:snakeyaml:1.29",
+		"com.squareup.okhttp3:okhttp:4.9.3",
+		"io.github.http-builder-ng:http-builder-ng-core",
+	}
+
+	analyzer := languages.NewGroovyAnalyzer()
+
+	Describe("Extract Groovy Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
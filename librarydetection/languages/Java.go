@@ -2,6 +2,7 @@ package languages
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
@@ -14,26 +15,42 @@ func NewJavaAnalyzer() librarydetection.Analyzer {
 type javaAnalyzer struct{}
 
 func (a *javaAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	// regex to find imports like org.springframework (excluding standart java libraries)
-	regex1, err := regexp.Compile(`import ([^java][a-zA-Z0-9]*\.[a-zA-Z0-9]*)`)
+	// regex to find imports like `import org.springframework.boot.SpringApplication;` or `import org.junit.*;`
+	importRegex, err := regexp.Compile(`(?m)^[ \t]*import[ \t]+([a-zA-Z0-9_.]+(?:\.\*)?)[ \t]*;`)
 	if err != nil {
 		return nil, err
 	}
-	// regex to find imports like org.springframework.boot
-	regex2, err := regexp.Compile(`import ([^java][a-zA-Z0-9]*\.[a-zA-Z0-9]*\.[a-zA-Z0-9]*)`)
+	// regex to find static imports like `import static org.junit.Assert.assertEquals;`
+	staticImportRegex, err := regexp.Compile(`(?m)^[ \t]*import[ \t]+static[ \t]+([a-zA-Z0-9_.]+(?:\.\*)?)[ \t]*;`)
 	if err != nil {
 		return nil, err
 	}
-	// regex to find static imports like org.springframework (excluding standart java libraries)
-	regex3, err := regexp.Compile(`import static ([^java][a-zA-Z0-9]*\.[a-zA-Z0-9]*)`)
-	if err != nil {
-		return nil, err
+
+	contents = removeComments(contents, cStyleComments)
+
+	var res []string
+	for _, importPath := range executeRegexes(contents, []*regexp.Regexp{importRegex}) {
+		res = appendJavaPackage(res, importPath)
 	}
-	// regex to find static imports like org.springframework.boot
-	regex4, err := regexp.Compile(`import static ([^java][a-zA-Z0-9]*\.[a-zA-Z0-9]*\.[a-zA-Z0-9]*)`)
-	if err != nil {
-		return nil, err
+	for _, importPath := range executeRegexes(contents, []*regexp.Regexp{staticImportRegex}) {
+		// static imports end with a member (or *) of a class, the package is the one of the class
+		memberStart := strings.LastIndex(importPath, ".")
+		if memberStart == -1 {
+			continue
+		}
+		res = appendJavaPackage(res, importPath[:memberStart])
 	}
 
-	return executeRegexes(contents, []*regexp.Regexp{regex1, regex2, regex3, regex4}), nil
+	return res, nil
+}
+
+// appendJavaPackage appends the package of the import, unless it is part of the standard java libraries
+func appendJavaPackage(libraries []string, importPath string) []string {
+	if strings.HasPrefix(importPath, "java.") || strings.HasPrefix(importPath, "javax.") {
+		return libraries
+	}
+	if pkg := jvmImportPackage(importPath); pkg != "" {
+		libraries = append(libraries, pkg)
+	}
+	return libraries
 }
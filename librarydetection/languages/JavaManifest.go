@@ -0,0 +1,104 @@
+package languages
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewPomXMLAnalyzer constructor
+func NewPomXMLAnalyzer() librarydetection.ManifestAnalyzer {
+	return &pomXMLAnalyzer{}
+}
+
+type pomXMLAnalyzer struct{}
+
+type pomXML struct {
+	GroupID string `xml:"groupId"`
+	Version string `xml:"version"`
+	Parent  struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []pomProperty `xml:",any"`
+	} `xml:"properties"`
+	Dependencies         []pomDependency `xml:"dependencies>dependency"`
+	DependencyManagement struct {
+		Dependencies []pomDependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+}
+
+type pomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+// ExtractDependencies returns with the dependencies like "org.slf4j:slf4j-api:1.7.32" under "Java"
+// Versions like ${slf4j.version} are resolved from the properties of the pom. Dependencies without version,
+// whose version is not managed in the same pom either, are returned without version like "org.slf4j:slf4j-api".
+// Every pom.xml of multi-module projects is parsed separately, the properties of the parent pom are not resolved.
+func (a *pomXMLAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	var pom pomXML
+	err := xml.Unmarshal([]byte(contents), &pom)
+	if err != nil {
+		return nil, err
+	}
+
+	// regex to find property references like ${slf4j.version}
+	propertyRegex, err := regexp.Compile(`\$\{([^}]+)\}`)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{
+		"project.groupId": pom.GroupID,
+		"project.version": pom.Version,
+	}
+	if properties["project.groupId"] == "" {
+		properties["project.groupId"] = pom.Parent.GroupID
+	}
+	if properties["project.version"] == "" {
+		properties["project.version"] = pom.Parent.Version
+	}
+	for _, property := range pom.Properties.Entries {
+		properties[property.XMLName.Local] = strings.TrimSpace(property.Value)
+	}
+	resolve := func(value string) string {
+		return propertyRegex.ReplaceAllStringFunc(strings.TrimSpace(value), func(reference string) string {
+			if resolved, ok := properties[reference[2:len(reference)-1]]; ok && resolved != "" {
+				return resolved
+			}
+			return reference
+		})
+	}
+
+	managedVersions := map[string]string{}
+	for _, dependency := range pom.DependencyManagement.Dependencies {
+		managedVersions[resolve(dependency.GroupID)+":"+resolve(dependency.ArtifactID)] = resolve(dependency.Version)
+	}
+
+	deps := []string{}
+	for _, dependency := range pom.Dependencies {
+		coordinates := resolve(dependency.GroupID) + ":" + resolve(dependency.ArtifactID)
+		version := resolve(dependency.Version)
+		if version == "" {
+			version = managedVersions[coordinates]
+		}
+		if version == "" {
+			deps = append(deps, coordinates)
+			continue
+		}
+		deps = append(deps, coordinates+":"+version)
+	}
+
+	return map[string][]string{"Java": deps}, nil
+}
@@ -0,0 +1,40 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("JavaManifestDependencyDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/pomxml.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	analyzer := languages.NewPomXMLAnalyzer()
+
+	Describe("Extract pom.xml dependencies", func() {
+		It("Should be able to extract the dependencies with the resolved versions", func() {
+			deps, err := analyzer.ExtractDependencies(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Java"], []string{
+				"org.slf4j:slf4j-api:1.7.32",
+				"com.google.guava:guava:31.0.1-jre",
+				"com.example:common:1.2.0",
+				"org.apache.commons:commons-lang3:${commons.version}",
+				"org.junit.jupiter:junit-jupiter:5.8.1",
+			})
+		})
+
+		It("Should return an error for malformed files", func() {
+			_, err := analyzer.ExtractDependencies(`<project><dependencies>`)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -2,6 +2,7 @@ package languages
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
@@ -14,16 +15,56 @@ func NewJavaScriptAnalyzer() librarydetection.Analyzer {
 type javaScriptAnalyzer struct{}
 
 func (a *javaScriptAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	return extractJavaScriptLibraries(contents)
+}
+
+// extractJavaScriptLibraries finds the packages imported or re-exported by JavaScript and TypeScript files.
+// Relative imports are excluded and the paths are collapsed to the package name.
+func extractJavaScriptLibraries(contents string) ([]string, error) {
+	// matches require('lib') and require("lib")
+	require, err := regexp.Compile("\\brequire\\(\\s*[\"'`]([^\"'`\\n]+)[\"'`]\\s*\\)")
+	if err != nil {
+		return nil, err
+	}
+
+	// matches dynamic imports like import('lib')
+	dynamicImport, err := regexp.Compile("\\bimport\\(\\s*[\"'`]([^\"'`\\n]+)[\"'`]\\s*\\)")
+	if err != nil {
+		return nil, err
+	}
 
-	require, err := regexp.Compile(`require\(["\'](.+)["\']\);?\s`)
+	// matches import 'lib', import x from 'lib' and multi-line imports like import {\n x,\n y\n} from 'lib'
+	importRegex, err := regexp.Compile(`\bimport\s+(?:[^'"();]+?\s+from\s+)?["']([^"'\n]+)["']`)
 	if err != nil {
 		return nil, err
 	}
 
-	importRegex, err := regexp.Compile(`import\s*(?:.+ from)?\s?\(?[\'"](.+)[\'"]\)?;?\s`)
+	// matches the re-exports of the barrel files like export * from 'lib', export * as x from 'lib',
+	// export { x, default as y } from 'lib' and export x from 'lib'
+	reExport, err := regexp.Compile(`\bexport\s+(?:type\s+)?(?:\*(?:\s+as\s+\w+)?|\{[^}]*\}|\w+)\s*from\s*["']([^"'\n]+)["']`)
 	if err != nil {
 		return nil, err
 	}
 
-	return executeRegexes(contents, []*regexp.Regexp{require, importRegex}), nil
+	contents = removeComments(contents, cStyleComments)
+
+	var res []string
+	for _, module := range executeRegexes(contents, []*regexp.Regexp{require, dynamicImport, importRegex, reExport}) {
+		// relative imports like require('./util') are not libraries
+		if strings.HasPrefix(module, ".") || strings.HasPrefix(module, "/") {
+			continue
+		}
+		res = append(res, getJavaScriptPackageName(module))
+	}
+	return res, nil
+}
+
+// getJavaScriptPackageName returns with the package name of an imported path
+// e.g. "lodash" for "lodash/fp" and "@angular/core" for "@angular/core/testing"
+func getJavaScriptPackageName(module string) string {
+	parts := strings.Split(module, "/")
+	if strings.HasPrefix(module, "@") && len(parts) > 1 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
 }
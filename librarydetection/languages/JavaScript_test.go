@@ -19,6 +19,16 @@ var _ = Describe("JavaScriptLibraryDetection", func() {
 		"lib2",
 		"lib3",
 		"lib4",
+		"express",
+		"@angular/core",
+		"lodash",
+		"@scope/pkg",
+		"after-url",
+		"rxjs",
+		"@scope/utils",
+		"react-select",
+		"@mui/material",
+		"lodash-es",
 	}
 
 	analyzer := languages.NewJavaScriptAnalyzer()
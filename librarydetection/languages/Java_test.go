@@ -15,10 +15,10 @@ var _ = Describe("JavaLibraryDetection", func() {
 	}
 
 	expectedLibraries := []string{
-		"org.bytedeco",
-		"org.bytedeco.javacv",
-		"org.bytedeco",
-		"org.bytedeco.opencv",
+		"com.google.common.collect",
+		"org.bytedeco.opencv.opencv_core",
+		"org.junit",
+		"org.bytedeco.opencv.global",
 	}
 
 	analyzer := languages.NewJavaAnalyzer()
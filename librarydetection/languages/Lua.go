@@ -0,0 +1,71 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewLuaAnalyzer constructor
+func NewLuaAnalyzer() librarydetection.Analyzer {
+	return &luaAnalyzer{}
+}
+
+type luaAnalyzer struct{}
+
+// ExtractLibraries returns with the top-level modules of the require calls, like "socket" for require("socket.http")
+func (a *luaAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find modules like require("socket"), require 'socket', require "socket" or require [[socket]]
+	requireRegex, err := regexp.Compile(`\brequire\s*\(?\s*(?:["']([^"'\n]+)["']|\[\[([^\]\n]+)\]\])`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = removeLuaComments(contents)
+
+	var res []string
+	for _, match := range requireRegex.FindAllStringSubmatch(contents, -1) {
+		module := match[1] + match[2]
+		// relative requires like require("./util") or require("../lib/util") are not libraries
+		if strings.HasPrefix(module, ".") || strings.HasPrefix(module, "/") {
+			continue
+		}
+		res = append(res, strings.Split(strings.Split(module, ".")[0], "/")[0])
+	}
+	return res, nil
+}
+
+// removeLuaComments removes the comments like -- require("old") and the long comments like --[==[ require("old") ]==]
+// The long comments are closed by the brackets with the same number of equal signs.
+func removeLuaComments(contents string) string {
+	var res strings.Builder
+	for {
+		start := strings.Index(contents, "--")
+		if start == -1 {
+			res.WriteString(contents)
+			return res.String()
+		}
+		res.WriteString(contents[:start])
+		contents = contents[start+2:]
+
+		if strings.HasPrefix(contents, "[") {
+			level := len(contents[1:]) - len(strings.TrimLeft(contents[1:], "="))
+			if strings.HasPrefix(contents[1+level:], "[") {
+				closing := "]" + strings.Repeat("=", level) + "]"
+				end := strings.Index(contents, closing)
+				if end == -1 {
+					return res.String()
+				}
+				contents = contents[end+len(closing):]
+				continue
+			}
+		}
+
+		end := strings.Index(contents, "\n")
+		if end == -1 {
+			return res.String()
+		}
+		contents = contents[end:]
+	}
+}
@@ -0,0 +1,38 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("LuaLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/lua.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"socket",
+		"socket",
+		"cjson",
+		"lpeg",
+		"lfs",
+		"inspect",
+		"pl",
+	}
+
+	analyzer := languages.NewLuaAnalyzer()
+
+	Describe("Extract Lua Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
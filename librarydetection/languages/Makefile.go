@@ -0,0 +1,109 @@
+package languages
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// DefaultMakefileTools are the command line tools which are detected in the recipes of the Makefiles
+// Besides the DefaultShellCommands they are the build tools and the package managers, the compilers are usually
+// run through variables like $(CC).
+var DefaultMakefileTools = append([]string{
+	"bazel", "cargo", "cmake", "dotnet", "go", "gradle", "mvn", "ninja", "npm", "npx", "pip", "pnpm", "poetry",
+	"protoc", "yarn",
+}, DefaultShellCommands...)
+
+// NewMakefileAnalyzer constructor
+// It detects the DefaultMakefileTools.
+func NewMakefileAnalyzer() librarydetection.Analyzer {
+	return &makefileAnalyzer{recipes: NewShellAnalyzerWithOptions(ShellOptions{Commands: DefaultMakefileTools})}
+}
+
+type makefileAnalyzer struct {
+	// recipes finds the tools in the recipes, which are shell commands
+	recipes librarydetection.Analyzer
+}
+
+// ExtractLibraries returns with the names of the included makefiles like "common.mk" for include build/common.mk,
+// and the tools run by the recipes. Every library is returned once.
+func (a *makefileAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to remove the comments, but not the escaped \# or the $# of the shell
+	commentRegex, err := regexp.Compile(`(?m)(^|[^\\$])#.*$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the included makefiles like include a.mk b.mk, -include a.mk or sinclude a.mk
+	includeRegex, err := regexp.Compile(`^[ \t]*[-s]?include[ \t]+(.+)$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the variables like DOCKER ?= docker, export GO := go or CC = gcc
+	variableRegex, err := regexp.Compile(`^(?:export[ \t]+)?([a-zA-Z_][a-zA-Z0-9_]*)[ \t]*(?:::|[:?+!])?=[ \t]*(.*)$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the references of the variables like $(DOCKER) or ${DOCKER}
+	referenceRegex, err := regexp.Compile(`\$[({]([a-zA-Z_][a-zA-Z0-9_]*)[)}]`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lines can be continued in the next line with a backslash
+	contents = strings.Replace(contents, "\\\r\n", " ", -1)
+	contents = strings.Replace(contents, "\\\n", " ", -1)
+	contents = commentRegex.ReplaceAllString(contents, "$1")
+
+	variables := map[string]string{}
+	var res []string
+	var recipes []string
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "\t") {
+			recipes = append(recipes, line)
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if match := includeRegex.FindStringSubmatch(line); match != nil {
+			for _, includedFile := range strings.Fields(match[1]) {
+				// the makefiles named by variables can not be resolved
+				if !strings.Contains(includedFile, "$") {
+					res = append(res, path.Base(includedFile))
+				}
+			}
+			continue
+		}
+		if match := variableRegex.FindStringSubmatch(line); match != nil {
+			variables[match[1]] = strings.TrimSpace(match[2])
+		}
+	}
+
+	for i, recipe := range recipes {
+		recipe = referenceRegex.ReplaceAllStringFunc(recipe, func(reference string) string {
+			if value, ok := variables[referenceRegex.FindStringSubmatch(reference)[1]]; ok {
+				return value
+			}
+			return reference
+		})
+		// @ silences the command, - ignores its errors and + runs it even with make -n
+		recipe = strings.TrimLeft(strings.TrimSpace(recipe), "@-+")
+		// $$ is the $ of the shell
+		recipes[i] = strings.Replace(recipe, "$$", "$", -1)
+	}
+	tools, err := a.recipes.ExtractLibraries(strings.Join(recipes, "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	libraries := []string{}
+	for _, library := range append(res, tools...) {
+		if !seen[library] {
+			seen[library] = true
+			libraries = append(libraries, library)
+		}
+	}
+	return libraries, nil
+}
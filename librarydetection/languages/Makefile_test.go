@@ -0,0 +1,39 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("MakefileLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/makefile.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"common.mk",
+		"config.mk",
+		"go",
+		"docker",
+		"protoc",
+		"npm",
+		"helm",
+		"kubectl",
+	}
+
+	analyzer := languages.NewMakefileAnalyzer()
+
+	Describe("Extract Makefile libraries", func() {
+		It("Should be able to extract the included makefiles and the tools of the recipes", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package languages
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewMatlabAnalyzer constructor
+func NewMatlabAnalyzer() librarydetection.Analyzer {
+	return &matlabAnalyzer{}
+}
+
+type matlabAnalyzer struct{}
+
+// matlabToolboxFunctions are the commonly used functions of the toolboxes, the toolboxes are reported as libraries
+// The list is short on purpose: the functions of MATLAB itself and the ones shared by several toolboxes are left out.
+var matlabToolboxFunctions = map[string]string{
+	"butter":              "Signal Processing Toolbox",
+	"designfilt":          "Signal Processing Toolbox",
+	"filtfilt":            "Signal Processing Toolbox",
+	"pwelch":              "Signal Processing Toolbox",
+	"spectrogram":         "Signal Processing Toolbox",
+	"bwlabel":             "Image Processing Toolbox",
+	"imbinarize":          "Image Processing Toolbox",
+	"imfilter":            "Image Processing Toolbox",
+	"imresize":            "Image Processing Toolbox",
+	"regionprops":         "Image Processing Toolbox",
+	"fitcsvm":             "Statistics and Machine Learning Toolbox",
+	"fitctree":            "Statistics and Machine Learning Toolbox",
+	"fitlm":               "Statistics and Machine Learning Toolbox",
+	"kmeans":              "Statistics and Machine Learning Toolbox",
+	"normrnd":             "Statistics and Machine Learning Toolbox",
+	"ttest":               "Statistics and Machine Learning Toolbox",
+	"fmincon":             "Optimization Toolbox",
+	"fminunc":             "Optimization Toolbox",
+	"linprog":             "Optimization Toolbox",
+	"lsqnonlin":           "Optimization Toolbox",
+	"quadprog":            "Optimization Toolbox",
+	"bode":                "Control System Toolbox",
+	"lqr":                 "Control System Toolbox",
+	"nyquist":             "Control System Toolbox",
+	"rlocus":              "Control System Toolbox",
+	"dsolve":              "Symbolic Math Toolbox",
+	"syms":                "Symbolic Math Toolbox",
+	"vpasolve":            "Symbolic Math Toolbox",
+	"fullyConnectedLayer": "Deep Learning Toolbox",
+	"trainNetwork":        "Deep Learning Toolbox",
+	"trainingOptions":     "Deep Learning Toolbox",
+	"gpuArray":            "Parallel Computing Toolbox",
+	"parfor":              "Parallel Computing Toolbox",
+	"parpool":             "Parallel Computing Toolbox",
+	"spmd":                "Parallel Computing Toolbox",
+}
+
+// ExtractLibraries returns with the packages of the import statements, like "mypkg" for import mypkg.MyClass,
+// and the toolboxes of the functions called
+func (a *matlabAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find imports like import pkg.Class, import pkg.* or import pkg1.* pkg2.fn and the function syntax
+	// like import('pkg.*')
+	importRegex, err := regexp.Compile(`(?m)^[ \t]*import(?:[ \t]+([\w. \t*]+)|\s*\(\s*'([\w.*]+)'\s*\))`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the identifiers which are not fields or methods like obj.butter
+	identifierRegex, err := regexp.Compile(`(?:^|[^.\w])([A-Za-z]\w*)`)
+	if err != nil {
+		return nil, err
+	}
+
+	code := removeMatlabComments(contents, false)
+
+	var res []string
+	for _, match := range importRegex.FindAllStringSubmatch(code, -1) {
+		for _, importPath := range strings.Fields(match[1] + match[2]) {
+			// the packages of MATLAB itself like matlab.unittest are not libraries
+			if !strings.Contains(importPath, ".") || strings.HasPrefix(importPath, "matlab.") {
+				continue
+			}
+			res = append(res, jvmImportPackage(importPath))
+		}
+	}
+
+	// the toolboxes are reported once per file, the functions are often called many times
+	toolboxes := map[string]bool{}
+	for _, match := range identifierRegex.FindAllStringSubmatch(removeMatlabComments(contents, true), -1) {
+		if toolbox, ok := matlabToolboxFunctions[match[1]]; ok {
+			toolboxes[toolbox] = true
+		}
+	}
+	var usedToolboxes []string
+	for toolbox := range toolboxes {
+		usedToolboxes = append(usedToolboxes, toolbox)
+	}
+	sort.Strings(usedToolboxes)
+	return append(res, usedToolboxes...), nil
+}
+
+// removeMatlabComments removes the % comments and the %{ ... %} block comments, which are on their own lines
+// The string literals are removed too if removeStrings is set. A quote after an identifier, a closing bracket, a dot or
+// another quote is the transpose operator, like in x' or a.', otherwise it starts a string literal.
+func removeMatlabComments(contents string, removeStrings bool) string {
+	lines := strings.Split(contents, "\n")
+	depth := 0
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case "%{":
+			depth++
+			lines[i] = ""
+			continue
+		case "%}":
+			if depth > 0 {
+				depth--
+				lines[i] = ""
+				continue
+			}
+		}
+		if depth > 0 {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = removeMatlabLineComment(line, removeStrings)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// removeMatlabLineComment removes the comment at the end of the line
+func removeMatlabLineComment(line string, removeStrings bool) string {
+	var res strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '%':
+			return res.String()
+		case c == '"' || (c == '\'' && !isMatlabTranspose(line[:i])):
+			// the quotes are escaped by doubling them, like 'it''s'
+			end := i + 1
+			for end < len(line) && (line[end] != c || (end+1 < len(line) && line[end+1] == c)) {
+				if line[end] == c {
+					end++
+				}
+				end++
+			}
+			if end == len(line) {
+				end--
+			}
+			if !removeStrings {
+				res.WriteString(line[i : end+1])
+			}
+			i = end
+		default:
+			res.WriteByte(c)
+		}
+	}
+	return res.String()
+}
+
+// isMatlabTranspose tells if a quote following the text is the transpose operator
+func isMatlabTranspose(before string) bool {
+	if before == "" {
+		return false
+	}
+	c := before[len(before)-1]
+	return c == '_' || c == '.' || c == '\'' || c == ')' || c == ']' || c == '}' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,38 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("MatlabLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/matlab.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"mylib.filters",
+		"mylib.io",
+		"geometry",
+		"utils",
+		"plotting",
+		"Signal Processing Toolbox",
+		"Parallel Computing Toolbox",
+	}
+
+	analyzer := languages.NewMatlabAnalyzer()
+
+	Describe("Extract MATLAB Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package languages
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewComposerJSONAnalyzer constructor
+func NewComposerJSONAnalyzer() librarydetection.ManifestAnalyzer {
+	return &composerJSONAnalyzer{}
+}
+
+// NewComposerLockAnalyzer constructor
+func NewComposerLockAnalyzer() librarydetection.ManifestAnalyzer {
+	return &composerLockAnalyzer{}
+}
+
+type composerJSONAnalyzer struct{}
+
+type composerLockAnalyzer struct{}
+
+type composerJSON struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+}
+
+type composerLock struct {
+	Packages    []composerLockPackage `json:"packages"`
+	PackagesDev []composerLockPackage `json:"packages-dev"`
+}
+
+type composerLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ExtractDependencies returns with the required packages under "PHP"
+// and the dev packages under "PHP-dev" like "monolog/monolog@^2.0"
+func (a *composerJSONAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	var manifest composerJSON
+	err := json.Unmarshal([]byte(contents), &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]string{
+		"PHP":     formatDependencies(withoutComposerPlatformPackages(manifest.Require), "@"),
+		"PHP-dev": formatDependencies(withoutComposerPlatformPackages(manifest.RequireDev), "@"),
+	}, nil
+}
+
+// ExtractDependencies returns with the installed packages under "PHP"
+// and the dev packages under "PHP-dev" with their exact versions like "monolog/monolog@2.3.5"
+func (a *composerLockAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	var lock composerLock
+	err := json.Unmarshal([]byte(contents), &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]string{
+		"PHP":     formatComposerLockPackages(lock.Packages),
+		"PHP-dev": formatComposerLockPackages(lock.PackagesDev),
+	}, nil
+}
+
+func formatComposerLockPackages(packages []composerLockPackage) []string {
+	res := make([]string, 0, len(packages))
+	for _, p := range packages {
+		if isComposerPlatformPackage(p.Name) {
+			continue
+		}
+		res = append(res, p.Name+"@"+p.Version)
+	}
+	return res
+}
+
+func withoutComposerPlatformPackages(dependencies map[string]string) map[string]string {
+	res := make(map[string]string, len(dependencies))
+	for name, version := range dependencies {
+		if !isComposerPlatformPackage(name) {
+			res[name] = version
+		}
+	}
+	return res
+}
+
+// isComposerPlatformPackage tells if the requirement is a platform requirement like "php", "ext-json" or "lib-curl"
+// Real packages are always named like "vendor/package".
+func isComposerPlatformPackage(name string) bool {
+	return !strings.Contains(name, "/")
+}
@@ -0,0 +1,52 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("PHPManifestDependencyDetection", func() {
+	composerJSONFixture, err := ioutil.ReadFile("./fixtures/composerjson.fixture")
+	if err != nil {
+		panic(err)
+	}
+	composerLockFixture, err := ioutil.ReadFile("./fixtures/composerlock.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Extract composer.json dependencies", func() {
+		analyzer := languages.NewComposerJSONAnalyzer()
+
+		It("Should be able to extract the packages and the dev packages without the platform requirements", func() {
+			deps, err := analyzer.ExtractDependencies(string(composerJSONFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["PHP"], []string{"monolog/monolog@^2.0", "symfony/console@~5.4"})
+			assertSameUnordered(deps["PHP-dev"], []string{"phpunit/phpunit@^9.5"})
+		})
+
+		It("Should return an error for malformed files", func() {
+			_, err := analyzer.ExtractDependencies(`{"require": {`)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Extract composer.lock dependencies", func() {
+		analyzer := languages.NewComposerLockAnalyzer()
+
+		It("Should be able to extract the packages and the dev packages with exact versions", func() {
+			deps, err := analyzer.ExtractDependencies(string(composerLockFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["PHP"], []string{"monolog/monolog@2.3.5", "symfony/console@v5.4.2"})
+			assertSameUnordered(deps["PHP-dev"], []string{"phpunit/phpunit@9.5.10"})
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package languages
+
+import (
+	"encoding/json"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewPackageJSONAnalyzer constructor
+func NewPackageJSONAnalyzer() librarydetection.ManifestAnalyzer {
+	return &packageJSONAnalyzer{}
+}
+
+type packageJSONAnalyzer struct{}
+
+type packageJSON struct {
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+// ExtractDependencies returns with the dependencies under "JavaScript"
+// and the dev dependencies under "JavaScript-dev" like "react@^17.0.2"
+func (a *packageJSONAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	var manifest packageJSON
+	err := json.Unmarshal([]byte(contents), &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]string{
+		"JavaScript":     formatDependencies(manifest.Dependencies, "@"),
+		"JavaScript-dev": formatDependencies(manifest.DevDependencies, "@"),
+	}, nil
+}
+
+// formatDependencies joins the names and versions of the dependencies with the separator
+func formatDependencies(dependencies map[string]string, separator string) []string {
+	res := make([]string, 0, len(dependencies))
+	for name, version := range dependencies {
+		res = append(res, name+separator+version)
+	}
+	return res
+}
@@ -0,0 +1,35 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("PackageJSONDependencyDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/packagejson.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	analyzer := languages.NewPackageJSONAnalyzer()
+
+	Describe("Extract package.json dependencies", func() {
+		It("Should be able to extract dependencies and dev dependencies with versions", func() {
+			deps, err := analyzer.ExtractDependencies(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["JavaScript"], []string{"react@^17.0.2", "@angular/core@~11.2.0"})
+			assertSameUnordered(deps["JavaScript-dev"], []string{"jest@26.6.3"})
+		})
+
+		It("Should return an error for malformed files", func() {
+			_, err := analyzer.ExtractDependencies(`{"dependencies": {`)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
@@ -6,18 +6,71 @@ import (
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
 
+// DefaultPerlPragmas are the pragmas of the Perl core which are not reported as libraries, like strict or warnings
+var DefaultPerlPragmas = []string{
+	"attributes", "autodie", "autouse", "base", "bigint", "bignum", "bigrat", "blib", "bytes", "charnames",
+	"constant", "diagnostics", "encoding", "experimental", "feature", "fields", "filetest", "if", "integer", "less",
+	"lib", "locale", "mro", "open", "ops", "overload", "overloading", "parent", "re", "sigtrap", "sort", "strict",
+	"subs", "threads", "threads::shared", "utf8", "vars", "version", "vmsish", "warnings", "warnings::register",
+}
+
+// perlComments are the comments and the POD documentation of Perl
+// The POD starts with a command like "=pod" or "=head1" and ends with "=cut", both at the beginning of a line.
+var perlComments = commentSyntax{
+	lineComments: []string{"#"},
+	lineBlockComments: [][2]string{
+		{"=pod", "\n=cut"}, {"=head", "\n=cut"}, {"=over", "\n=cut"}, {"=item", "\n=cut"}, {"=begin", "\n=cut"},
+		{"=for", "\n=cut"}, {"=encoding", "\n=cut"},
+	},
+	quotes: []string{`"`, `'`},
+}
+
+// PerlOptions configures the Perl analyzer
+type PerlOptions struct {
+	// Pragmas are the modules which are not reported as libraries
+	Pragmas []string
+}
+
 // NewPerlAnalyzer constructor
+// It excludes the DefaultPerlPragmas.
 func NewPerlAnalyzer() librarydetection.Analyzer {
-	return &perlAnalyzer{}
+	return NewPerlAnalyzerWithOptions(PerlOptions{Pragmas: DefaultPerlPragmas})
+}
+
+// NewPerlAnalyzerWithOptions constructor
+func NewPerlAnalyzerWithOptions(options PerlOptions) librarydetection.Analyzer {
+	pragmas := map[string]bool{}
+	for _, pragma := range options.Pragmas {
+		pragmas[pragma] = true
+	}
+	return &perlAnalyzer{pragmas: pragmas}
 }
 
-type perlAnalyzer struct{}
+type perlAnalyzer struct {
+	pragmas map[string]bool
+}
 
+// ExtractLibraries returns with the modules of the use and require statements, like "Foo::Bar" for use Foo::Bar 1.23;
+// The version numbers, the required Perl versions like use v5.10; and the pragmas are not reported.
 func (a *perlAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	regex, err := regexp.Compile(`(?:use|require)[^\S\n]+(?:if.*,\s+)?[\"']?([a-zA-Z][a-zA-Z0-9:]*)[\"']?(?:\s+.*)?;`)
+	// regex to find modules like use Foo::Bar;, use Foo::Bar 1.23 qw(baz);, require Foo::Bar; or use if $cond, "Foo";
+	regex, err := regexp.Compile(`\b(?:use|require)[^\S\n]+(?:if.*,\s+)?[\"']?([a-zA-Z][a-zA-Z0-9_:]*)[\"']?(?:\s+.*)?;`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the required Perl versions like v5 in use v5;
+	versionRegex, err := regexp.Compile(`^v[0-9]+$`)
 	if err != nil {
 		return nil, err
 	}
 
-	return executeRegexes(contents, []*regexp.Regexp{regex}), nil
+	contents = removeComments(contents, perlComments)
+	var res []string
+	for _, module := range executeRegexes(contents, []*regexp.Regexp{regex}) {
+		if a.pragmas[module] || versionRegex.MatchString(module) {
+			continue
+		}
+		res = append(res, module)
+	}
+	return res, nil
 }
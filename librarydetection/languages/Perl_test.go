@@ -15,15 +15,13 @@ var _ = Describe("PerlLibraryDetection", func() {
 	}
 
 	expectedLibraries := []string{
-		"strict",
 		"Benchmark",
 		"Carp",
-		"sigtrap",
 		"Sub::Module",
 		"Import::This",
-		"utf8",
-		"warnings",
+		"Versioned::Module",
 		"Foo::Bar",
+		"Versioned::Required",
 		"Module",
 	}
 
@@ -37,5 +35,25 @@ var _ = Describe("PerlLibraryDetection", func() {
 			}
 			assertSameUnordered(libs, expectedLibraries)
 		})
+
+		It("Should only exclude the configured pragmas", func() {
+			analyzer := languages.NewPerlAnalyzerWithOptions(languages.PerlOptions{Pragmas: []string{"strict", "warnings", "Carp"}})
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{
+				"Benchmark",
+				"sigtrap",
+				"Sub::Module",
+				"Import::This",
+				"Versioned::Module",
+				"parent",
+				"utf8",
+				"Foo::Bar",
+				"Versioned::Required",
+				"Module",
+			})
+		})
 	})
 })
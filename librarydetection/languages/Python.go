@@ -2,26 +2,125 @@ package languages
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
 
+// The granularities of the Python libraries
+const (
+	// PythonGranularityPackage reports the top-level packages, like "django" for from django.db import models
+	PythonGranularityPackage = "package"
+	// PythonGranularityModule reports the full dotted paths of the modules, like "django.db" for from django.db import models
+	PythonGranularityModule = "module"
+)
+
+// PythonOptions configure the library detection of the Python files
+type PythonOptions struct {
+	// Granularity is either PythonGranularityPackage (default) or PythonGranularityModule
+	Granularity string
+	// ExcludeStandardLibrary drops the imports of the standard library like "os" or "json",
+	// so only the third-party packages are reported
+	ExcludeStandardLibrary bool
+	// Version is the major version of Python, 2 or 3, whose standard library is excluded.
+	// If it is 0, the modules of the standard library of both versions are excluded.
+	Version int
+}
+
 // NewPythonScriptAnalyzer constructor
 func NewPythonScriptAnalyzer() librarydetection.Analyzer {
 	return &pythonScriptAnalyzer{}
 }
 
-type pythonScriptAnalyzer struct{}
+// NewPythonScriptAnalyzerWithOptions constructor
+func NewPythonScriptAnalyzerWithOptions(options PythonOptions) librarydetection.Analyzer {
+	return &pythonScriptAnalyzer{options: options}
+}
+
+type pythonScriptAnalyzer struct {
+	options PythonOptions
+}
 
 func (a *pythonScriptAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	fromRegex, err := regexp.Compile(`from (.+) import`)
+	// regex to find parenthesized import blocks like `from lib import (a,\n b)` or `import (\n os,\n sys\n)`
+	parenthesizedRegex, err := regexp.Compile(`(?s)\bimport[ \t]*\(([^)]*)\)`)
+	if err != nil {
+		return nil, err
+	}
+	fromRegex, err := regexp.Compile(`(?m)^[ \t]*from[ \t]+([a-zA-Z0-9_.]+)[ \t]+import\b`)
 	if err != nil {
 		return nil, err
 	}
-	importRegex, err := regexp.Compile(`import ([a-zA-Z0-9_-]+)(?:\s| as)`)
+	importRegex, err := regexp.Compile(`(?m)^[ \t]*import[ \t]+([^#;\n]+)`)
 	if err != nil {
 		return nil, err
 	}
 
-	return executeRegexes(contents, []*regexp.Regexp{fromRegex, importRegex}), nil
+	contents = removeComments(contents, pythonComments)
+	contents = normalizePythonImports(contents, parenthesizedRegex)
+
+	var res []string
+	for _, module := range executeRegexes(contents, []*regexp.Regexp{fromRegex}) {
+		// relative imports like `from .models import User` are not libraries
+		if strings.HasPrefix(module, ".") {
+			continue
+		}
+		if a.isExcluded(module) {
+			continue
+		}
+		res = append(res, a.libraryOfModule(module))
+	}
+	for _, modules := range executeRegexes(contents, []*regexp.Regexp{importRegex}) {
+		// `import a.b as c, d` imports multiple modules at once
+		for _, module := range strings.Split(modules, ",") {
+			// the aliases like `as c` are not libraries
+			fields := strings.Fields(module)
+			if len(fields) == 0 || a.isExcluded(fields[0]) {
+				continue
+			}
+			res = append(res, a.libraryOfModule(fields[0]))
+		}
+	}
+
+	return res, nil
+}
+
+// normalizePythonImports joins backslash continuations and parenthesized import blocks,
+// so every import statement fits on a single line.
+func normalizePythonImports(contents string, parenthesizedRegex *regexp.Regexp) string {
+	contents = strings.Replace(contents, "\\\r\n", " ", -1)
+	contents = strings.Replace(contents, "\\\n", " ", -1)
+
+	return parenthesizedRegex.ReplaceAllStringFunc(contents, func(block string) string {
+		lines := strings.Split(block, "\n")
+		for i, line := range lines {
+			// comments would swallow the rest of the joined line
+			if commentStart := strings.Index(line, "#"); commentStart != -1 {
+				lines[i] = line[:commentStart]
+			}
+		}
+		block = strings.Join(lines, " ")
+		block = strings.Replace(block, "\r", " ", -1)
+		block = strings.Replace(block, "(", " ", 1)
+		return strings.TrimSuffix(block, ")")
+	})
+}
+
+// isExcluded tells if the module belongs to the excluded standard library
+func (a *pythonScriptAnalyzer) isExcluded(module string) bool {
+	return a.options.ExcludeStandardLibrary && IsPythonStandardLibrary(module, a.options.Version)
+}
+
+// libraryOfModule returns with the library of the imported module by the granularity
+func (a *pythonScriptAnalyzer) libraryOfModule(module string) string {
+	if a.options.Granularity == PythonGranularityModule {
+		return module
+	}
+	return pythonTopLevelModule(module)
+}
+
+// pythonTopLevelModule returns with the top-level package of a dotted module path
+// e.g. "django" for "django.db"
+func pythonTopLevelModule(module string) string {
+	return strings.Split(module, ".")[0]
 }
@@ -0,0 +1,183 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewRequirementsTxtAnalyzer constructor
+func NewRequirementsTxtAnalyzer() librarydetection.ManifestAnalyzer {
+	return &requirementsTxtAnalyzer{}
+}
+
+// NewPipfileAnalyzer constructor
+func NewPipfileAnalyzer() librarydetection.ManifestAnalyzer {
+	return &pipfileAnalyzer{}
+}
+
+// NewPyprojectAnalyzer constructor
+func NewPyprojectAnalyzer() librarydetection.ManifestAnalyzer {
+	return &pyprojectAnalyzer{}
+}
+
+type requirementsTxtAnalyzer struct{}
+
+type pipfileAnalyzer struct{}
+
+type pyprojectAnalyzer struct{}
+
+// ExtractDependencies returns with the requirements like "requests@>=2.0,<3.0" under "Python"
+func (a *requirementsTxtAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	requirementRegex, err := compilePythonRequirementRegex()
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	for _, line := range strings.Split(contents, "\n") {
+		// remove comments
+		if commentStart := strings.Index(line, " #"); commentStart != -1 {
+			line = line[:commentStart]
+		}
+		line = strings.TrimSpace(line)
+		// options like "-r other.txt" or "--index-url ..." are not requirements
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if dep := parsePythonRequirement(line, requirementRegex); dep != "" {
+			deps = append(deps, dep)
+		}
+	}
+
+	return map[string][]string{"Python": deps}, nil
+}
+
+// ExtractDependencies returns with the packages under "Python" and the dev packages under "Python-dev"
+func (a *pipfileAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find packages like requests = "*", django = ">=3.0" or flask = {version = "==1.1", extras = [...]}
+	packageRegex, err := regexp.Compile(`^["']?([A-Za-z0-9][A-Za-z0-9._-]*)["']?\s*=\s*(.*)$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the version inside inline tables
+	versionRegex, err := regexp.Compile(`version\s*=\s*["']([^"']*)["']`)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := map[string][]string{
+		"Python":     {},
+		"Python-dev": {},
+	}
+	section := ""
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			section = line
+			continue
+		}
+		key := ""
+		switch section {
+		case "[packages]":
+			key = "Python"
+		case "[dev-packages]":
+			key = "Python-dev"
+		default:
+			continue
+		}
+
+		match := packageRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		version := strings.Trim(match[2], `"'`)
+		if strings.HasPrefix(match[2], "{") {
+			version = ""
+			if versionMatch := versionRegex.FindStringSubmatch(match[2]); versionMatch != nil {
+				version = versionMatch[1]
+			}
+		}
+		if version == "" || version == "*" {
+			deps[key] = append(deps[key], match[1])
+		} else {
+			deps[key] = append(deps[key], match[1]+"@"+version)
+		}
+	}
+
+	return deps, nil
+}
+
+// ExtractDependencies returns with the dependencies of the [project] table under "Python"
+func (a *pyprojectAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find table headers like [project] or [tool.poetry]
+	tableRegex, err := regexp.Compile(`^\[\[?([^\]]+)\]\]?$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the dependencies array which can span multiple lines
+	dependenciesRegex, err := regexp.Compile(`(?ms)^dependencies\s*=\s*\[(.*?)\]`)
+	if err != nil {
+		return nil, err
+	}
+	stringRegex, err := regexp.Compile(`"([^"]*)"|'([^']*)'`)
+	if err != nil {
+		return nil, err
+	}
+	requirementRegex, err := compilePythonRequirementRegex()
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the lines of the project table
+	projectTable := []string{}
+	table := ""
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if match := tableRegex.FindStringSubmatch(line); match != nil {
+			table = strings.TrimSpace(match[1])
+			continue
+		}
+		if table == "project" {
+			projectTable = append(projectTable, line)
+		}
+	}
+
+	deps := []string{}
+	for _, dependencies := range executeRegexes(strings.Join(projectTable, "\n"), []*regexp.Regexp{dependenciesRegex}) {
+		for _, requirement := range stringRegex.FindAllStringSubmatch(dependencies, -1) {
+			if dep := parsePythonRequirement(requirement[1]+requirement[2], requirementRegex); dep != "" {
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	return map[string][]string{"Python": deps}, nil
+}
+
+// compilePythonRequirementRegex compiles the regex for requirement specifiers like "requests[security]>=2.0,<3.0"
+func compilePythonRequirementRegex() (*regexp.Regexp, error) {
+	return regexp.Compile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+}
+
+// parsePythonRequirement returns with the package name and the version specifier like "requests@>=2.0,<3.0"
+// Environment markers are removed, packages without specifier are returned without version.
+func parsePythonRequirement(requirement string, requirementRegex *regexp.Regexp) string {
+	// remove environment markers like `; python_version < "3.8"`
+	if markerStart := strings.Index(requirement, ";"); markerStart != -1 {
+		requirement = requirement[:markerStart]
+	}
+	match := requirementRegex.FindStringSubmatch(strings.TrimSpace(requirement))
+	if match == nil {
+		return ""
+	}
+
+	specifier := strings.Replace(match[2], " ", "", -1)
+	specifier = strings.Trim(specifier, "()")
+	// direct references like "pip @ https://..." don't have version
+	if specifier == "" || strings.HasPrefix(specifier, "@") {
+		return match[1]
+	}
+	return match[1] + "@" + specifier
+}
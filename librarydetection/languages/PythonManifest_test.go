@@ -0,0 +1,72 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("PythonManifestDependencyDetection", func() {
+	requirementsFixture, err := ioutil.ReadFile("./fixtures/requirements.fixture")
+	if err != nil {
+		panic(err)
+	}
+	pipfileFixture, err := ioutil.ReadFile("./fixtures/pipfile.fixture")
+	if err != nil {
+		panic(err)
+	}
+	pyprojectFixture, err := ioutil.ReadFile("./fixtures/pyproject.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Extract requirements.txt dependencies", func() {
+		It("Should be able to extract requirements with versions", func() {
+			deps, err := languages.NewRequirementsTxtAnalyzer().ExtractDependencies(string(requirementsFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Python"], []string{
+				"requests@>=2.0,<3.0",
+				"Django@==3.1.7",
+				"Pillow",
+				"numpy",
+				"uvicorn@>=0.13",
+				"pip",
+			})
+		})
+	})
+
+	Describe("Extract Pipfile dependencies", func() {
+		It("Should be able to extract packages and dev packages with versions", func() {
+			deps, err := languages.NewPipfileAnalyzer().ExtractDependencies(string(pipfileFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Python"], []string{
+				"requests",
+				"django@>=3.0",
+				"flask@==1.1.2",
+			})
+			assertSameUnordered(deps["Python-dev"], []string{
+				"pytest@==6.2.2",
+			})
+		})
+	})
+
+	Describe("Extract pyproject.toml dependencies", func() {
+		It("Should be able to extract project dependencies with versions", func() {
+			deps, err := languages.NewPyprojectAnalyzer().ExtractDependencies(string(pyprojectFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Python"], []string{
+				"requests@>=2.0,<3.0",
+				"importlib-metadata",
+				"click",
+			})
+		})
+	})
+})
@@ -13,13 +13,27 @@ var _ = Describe("PythonLibraryDetection", func() {
 	if err != nil {
 		panic(err)
 	}
+	multilineFixture, err := ioutil.ReadFile("./fixtures/python_multiline.fixture")
+	if err != nil {
+		panic(err)
+	}
 
 	expectedLibraries := []string{
-		"lib1.lib2",
-		"lib3",
+		"lib1",
 		"lib4",
 	}
 
+	expectedMultilineLibraries := []string{
+		"django",
+		"lib1",
+		"os",
+		"sys",
+		"json",
+		"collections",
+		"requests",
+		"numpy",
+	}
+
 	analyzer := languages.NewPythonScriptAnalyzer()
 
 	Describe("Extract Python Libraries", func() {
@@ -30,5 +44,58 @@ var _ = Describe("PythonLibraryDetection", func() {
 			}
 			assertSameUnordered(libs, expectedLibraries)
 		})
+
+		It("Should be able to extract libraries from multi-line imports", func() {
+			libs, err := analyzer.ExtractLibraries(string(multilineFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedMultilineLibraries)
+		})
+
+		It("Should be able to extract the full paths of the modules", func() {
+			moduleAnalyzer := languages.NewPythonScriptAnalyzerWithOptions(languages.PythonOptions{Granularity: languages.PythonGranularityModule})
+			libs, err := moduleAnalyzer.ExtractLibraries(string(multilineFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{
+				"django.db",
+				"lib1.lib2",
+				"os",
+				"sys",
+				"json",
+				"collections.abc",
+				"requests.adapters",
+				"numpy",
+			})
+		})
+
+		It("Should be able to exclude the standard library", func() {
+			stdlibAnalyzer := languages.NewPythonScriptAnalyzerWithOptions(languages.PythonOptions{ExcludeStandardLibrary: true})
+			libs, err := stdlibAnalyzer.ExtractLibraries(string(multilineFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"django", "lib1", "requests", "numpy"})
+		})
+
+		It("Should exclude the standard library of the major version", func() {
+			contents := "import urllib2\nimport asyncio\nfrom os.path import join\nimport requests\n"
+
+			python2Analyzer := languages.NewPythonScriptAnalyzerWithOptions(languages.PythonOptions{ExcludeStandardLibrary: true, Version: 2})
+			libs, err := python2Analyzer.ExtractLibraries(contents)
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"asyncio", "requests"})
+
+			python3Analyzer := languages.NewPythonScriptAnalyzerWithOptions(languages.PythonOptions{ExcludeStandardLibrary: true, Version: 3})
+			libs, err = python3Analyzer.ExtractLibraries(contents)
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"urllib2", "requests"})
+		})
 	})
 })
@@ -0,0 +1,61 @@
+package languages
+
+import (
+	"regexp"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewRAnalyzer constructor
+func NewRAnalyzer() librarydetection.Analyzer {
+	return &rAnalyzer{}
+}
+
+type rAnalyzer struct{}
+
+// ExtractLibraries returns with the packages loaded with library(), require() or requireNamespace(),
+// and the packages whose functions are called with the namespace like dplyr::filter()
+// Every package is returned once, even if it is used in multiple forms.
+func (a *rAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to remove the comments, which often contain example code
+	commentRegex, err := regexp.Compile(`(?m)#.*$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find packages like library(dplyr), require("ggplot2") or requireNamespace('data.table', quietly = TRUE)
+	// The name of the package is a variable with character.only = TRUE, so those calls are skipped.
+	loadRegex, err := regexp.Compile(`\b(?:library|require|requireNamespace)\s*\(\s*["']?([a-zA-Z][a-zA-Z0-9.]*)["']?\s*(,[^)]*)?\)`)
+	if err != nil {
+		return nil, err
+	}
+	characterOnlyRegex, err := regexp.Compile(`character\.only\s*=\s*(?:TRUE|T)\b`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find namespace calls like dplyr::filter() or stats:::print.lm
+	namespaceRegex, err := regexp.Compile(`(?:^|[^a-zA-Z0-9._])([a-zA-Z][a-zA-Z0-9.]*):::?[a-zA-Z._]`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = commentRegex.ReplaceAllString(contents, "")
+
+	seen := map[string]bool{}
+	var res []string
+	add := func(pkg string) {
+		if !seen[pkg] {
+			seen[pkg] = true
+			res = append(res, pkg)
+		}
+	}
+	for _, match := range loadRegex.FindAllStringSubmatch(contents, -1) {
+		if characterOnlyRegex.MatchString(match[2]) {
+			continue
+		}
+		add(match[1])
+	}
+	for _, match := range namespaceRegex.FindAllStringSubmatch(contents, -1) {
+		add(match[1])
+	}
+	return res, nil
+}
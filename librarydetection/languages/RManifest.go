@@ -0,0 +1,107 @@
+package languages
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewRenvLockAnalyzer constructor
+func NewRenvLockAnalyzer() librarydetection.ManifestAnalyzer {
+	return &renvLockAnalyzer{}
+}
+
+// NewRDescriptionAnalyzer constructor
+// It analyses the DESCRIPTION file of R packages.
+func NewRDescriptionAnalyzer() librarydetection.ManifestAnalyzer {
+	return &rDescriptionAnalyzer{}
+}
+
+type renvLockAnalyzer struct{}
+
+type rDescriptionAnalyzer struct{}
+
+type renvLock struct {
+	Packages map[string]struct {
+		Package string `json:"Package"`
+		Version string `json:"Version"`
+	} `json:"Packages"`
+}
+
+// ExtractDependencies returns with the installed packages with their exact versions like "dplyr@1.0.7" under "R"
+func (a *renvLockAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	var lock renvLock
+	err := json.Unmarshal([]byte(contents), &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]string, 0, len(lock.Packages))
+	for name, p := range lock.Packages {
+		if p.Package != "" {
+			name = p.Package
+		}
+		if p.Version == "" {
+			deps = append(deps, name)
+			continue
+		}
+		deps = append(deps, name+"@"+p.Version)
+	}
+	return map[string][]string{"R": deps}, nil
+}
+
+// ExtractDependencies returns with the packages of the Depends, Imports and LinkingTo fields
+// like "dplyr@>=1.0.0" under "R". The dependency on R itself is not returned.
+func (a *rDescriptionAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find the packages like "dplyr (>= 1.0.0)"
+	packageRegex, err := regexp.Compile(`^([a-zA-Z][a-zA-Z0-9.]*)\s*(?:\(\s*([^)]*)\))?$`)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	for field, value := range parseDebianControlFields(contents) {
+		if field != "Depends" && field != "Imports" && field != "LinkingTo" {
+			continue
+		}
+		for _, requirement := range strings.Split(value, ",") {
+			match := packageRegex.FindStringSubmatch(strings.TrimSpace(requirement))
+			if match == nil || match[1] == "R" {
+				continue
+			}
+			if match[2] == "" {
+				deps = append(deps, match[1])
+				continue
+			}
+			deps = append(deps, match[1]+"@"+strings.Replace(match[2], " ", "", -1))
+		}
+	}
+	return map[string][]string{"R": deps}, nil
+}
+
+// parseDebianControlFields returns with the fields of the Debian control format used by the DESCRIPTION files
+// The values can be continued in the next lines, which start with whitespace.
+func parseDebianControlFields(contents string) map[string]string {
+	fields := map[string]string{}
+	field := ""
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && field != "" {
+			fields[field] += " " + strings.TrimSpace(line)
+			continue
+		}
+		keyValue := strings.SplitN(line, ":", 2)
+		if len(keyValue) != 2 {
+			field = ""
+			continue
+		}
+		field = strings.TrimSpace(keyValue[0])
+		fields[field] = strings.TrimSpace(keyValue[1])
+	}
+	return fields
+}
@@ -0,0 +1,42 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("RManifestDependencyDetection", func() {
+	renvLockFixture, err := ioutil.ReadFile("./fixtures/renvlock.fixture")
+	if err != nil {
+		panic(err)
+	}
+	descriptionFixture, err := ioutil.ReadFile("./fixtures/rdescription.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Extract renv.lock dependencies", func() {
+		It("Should be able to extract the packages with exact versions", func() {
+			analyzer := languages.NewRenvLockAnalyzer()
+			deps, err := analyzer.ExtractDependencies(string(renvLockFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["R"], []string{"dplyr@1.0.7", "renv@0.14.0"})
+		})
+	})
+
+	Describe("Extract DESCRIPTION dependencies", func() {
+		It("Should be able to extract the imported packages with their requirements", func() {
+			analyzer := languages.NewRDescriptionAnalyzer()
+			deps, err := analyzer.ExtractDependencies(string(descriptionFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["R"], []string{"dplyr@>=1.0.0", "ggplot2", "rlang@>=0.4.11", "Rcpp"})
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("RLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/r.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"dplyr",
+		"ggplot2",
+		"tidyr",
+		"data.table",
+		"readr",
+		"jsonlite",
+		"stats",
+		"janitor",
+	}
+
+	analyzer := languages.NewRAnalyzer()
+
+	Describe("Extract R Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
@@ -20,5 +20,7 @@ func (a *RubyScriptAnalyzer) ExtractLibraries(contents string) ([]string, error)
 		return nil, err
 	}
 
+	contents = removeComments(contents, rubyComments)
+
 	return executeRegexes(contents, []*regexp.Regexp{requireRegex}), nil
 }
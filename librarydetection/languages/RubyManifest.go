@@ -0,0 +1,84 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewGemfileLockAnalyzer constructor
+func NewGemfileLockAnalyzer() librarydetection.ManifestAnalyzer {
+	return &gemfileLockAnalyzer{}
+}
+
+// NewGemfileAnalyzer constructor
+func NewGemfileAnalyzer() librarydetection.ManifestAnalyzer {
+	return &gemfileAnalyzer{}
+}
+
+type gemfileLockAnalyzer struct{}
+
+type gemfileAnalyzer struct{}
+
+// ExtractDependencies returns with the installed gems with their exact versions like "rails@6.1.4" under "Ruby"
+func (a *gemfileLockAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find the gems of the specs like "    rails (6.1.4)", their own dependencies are indented deeper
+	specRegex, err := regexp.Compile(`^    ([^\s(]+) \(([^)]+)\)$`)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	section := ""
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// Sections like GEM, GIT or PLATFORMS start at the beginning of the line
+		if line != "" && !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+		if section != "GEM" {
+			continue
+		}
+		if match := specRegex.FindStringSubmatch(line); match != nil {
+			deps = append(deps, match[1]+"@"+match[2])
+		}
+	}
+
+	return map[string][]string{"Ruby": deps}, nil
+}
+
+// ExtractDependencies returns with the gems like "rails@~>6.1.4" under "Ruby"
+// Multiple requirements are joined with a comma like "pg@>=0.18,<2.0".
+func (a *gemfileAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find gems like gem 'rails', '~> 6.1.4' with optional requirements and options
+	gemRegex, err := regexp.Compile(`^gem\s*\(?\s*['"]([^'"]+)['"]((?:\s*,\s*['"][^'"]*['"])*)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the requirements after the name of the gem
+	requirementRegex, err := regexp.Compile(`['"]([^'"]*)['"]`)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	for _, line := range strings.Split(contents, "\n") {
+		match := gemRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		var requirements []string
+		for _, requirement := range requirementRegex.FindAllStringSubmatch(match[2], -1) {
+			requirements = append(requirements, strings.Replace(requirement[1], " ", "", -1))
+		}
+		if len(requirements) == 0 {
+			deps = append(deps, match[1])
+			continue
+		}
+		deps = append(deps, match[1]+"@"+strings.Join(requirements, ","))
+	}
+
+	return map[string][]string{"Ruby": deps}, nil
+}
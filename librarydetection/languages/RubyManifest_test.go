@@ -0,0 +1,42 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("RubyManifestDependencyDetection", func() {
+	gemfileLockFixture, err := ioutil.ReadFile("./fixtures/gemfilelock.fixture")
+	if err != nil {
+		panic(err)
+	}
+	gemfileFixture, err := ioutil.ReadFile("./fixtures/gemfile.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Extract Gemfile.lock dependencies", func() {
+		It("Should be able to extract the gems of the specs with exact versions", func() {
+			analyzer := languages.NewGemfileLockAnalyzer()
+			deps, err := analyzer.ExtractDependencies(string(gemfileLockFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Ruby"], []string{"actionpack@6.1.4", "nokogiri@1.12.5-x86_64-linux", "rack@2.2.3", "rails@6.1.4"})
+		})
+	})
+
+	Describe("Extract Gemfile dependencies", func() {
+		It("Should be able to extract the gems with their requirements", func() {
+			analyzer := languages.NewGemfileAnalyzer()
+			deps, err := analyzer.ExtractDependencies(string(gemfileFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Ruby"], []string{"rails@~>6.1.4", "pg@>=0.18,<2.0", "bootsnap", "devise", "rspec-rails@~>5.0"})
+		})
+	})
+})
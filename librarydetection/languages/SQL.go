@@ -0,0 +1,94 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewSQLAnalyzer constructor
+func NewSQLAnalyzer() librarydetection.Analyzer {
+	return &sqlAnalyzer{}
+}
+
+type sqlAnalyzer struct{}
+
+// sqlComments are the comments of the SQL dialects, the double quotes and the brackets delimit identifiers
+var sqlComments = commentSyntax{
+	lineComments:  []string{"--"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`'`},
+}
+
+// sqlSystemSchemas are the default schemas and the system catalogs, which are not reported
+var sqlSystemSchemas = map[string]bool{
+	"dbo":                true,
+	"information_schema": true,
+	"pg_catalog":         true,
+	"public":             true,
+	"sys":                true,
+}
+
+// sqlIdentifier matches the plain and the quoted identifiers like users, "Users", [Users] or `users`
+const sqlIdentifier = "(?:\\[[^\\]\\n]+\\]|\"[^\"\\n]+\"|`[^`\\n]+`|[A-Za-z_][\\w$]*)"
+
+// ExtractLibraries returns with the databases selected by USE, the schemas of the qualified tables like "sales" for
+// FROM sales.orders, the databases of the cross-database references like "archive" for JOIN archive.dbo.orders,
+// and the extensions created like "postgis" for CREATE EXTENSION postgis
+func (a *sqlAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find the databases like USE sales or USE [sales]
+	useRegex, err := regexp.Compile(`(?im)^[ \t]*USE[ \t]+(` + sqlIdentifier + `)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the extensions of Postgres like CREATE EXTENSION IF NOT EXISTS "uuid-ossp"
+	extensionRegex, err := regexp.Compile(`(?i)\bCREATE\s+EXTENSION\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + sqlIdentifier + `)`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the qualified tables like FROM sales.orders or INSERT INTO [archive].[dbo].[orders]
+	referenceRegex, err := regexp.Compile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE|REFERENCES|TABLE|VIEW)\s+(?:IF\s+(?:NOT\s+)?EXISTS\s+)?` +
+		`(` + sqlIdentifier + `(?:\s*\.\s*` + sqlIdentifier + `){1,2})`)
+	if err != nil {
+		return nil, err
+	}
+	identifierRegex, err := regexp.Compile(sqlIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	// regex to find the string literals like 'it''s', the quotes are escaped by doubling them
+	stringRegex, err := regexp.Compile(`'(?:[^']|'')*'`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = removeComments(contents, sqlComments)
+	// The text of the strings, like the default values, is not a reference
+	contents = stringRegex.ReplaceAllString(contents, "''")
+
+	var res []string
+	for _, match := range useRegex.FindAllStringSubmatch(contents, -1) {
+		res = append(res, unquoteSQLIdentifier(match[1]))
+	}
+	for _, match := range extensionRegex.FindAllStringSubmatch(contents, -1) {
+		res = append(res, unquoteSQLIdentifier(match[1]))
+	}
+	for _, match := range referenceRegex.FindAllStringSubmatch(contents, -1) {
+		// The database of the three-part names and the schema of the two-part names
+		name := unquoteSQLIdentifier(identifierRegex.FindString(match[1]))
+		if sqlSystemSchemas[strings.ToLower(name)] {
+			continue
+		}
+		res = append(res, name)
+	}
+	return res, nil
+}
+
+// unquoteSQLIdentifier removes the quotes or the brackets of the identifier
+func unquoteSQLIdentifier(identifier string) string {
+	if len(identifier) > 1 && strings.ContainsAny(identifier[:1], "[\"`") {
+		return identifier[1 : len(identifier)-1]
+	}
+	return identifier
+}
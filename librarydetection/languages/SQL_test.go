@@ -0,0 +1,39 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("SQLLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/sql.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"reporting",
+		"uuid-ossp",
+		"postgis",
+		"sales",
+		"crm",
+		"archive",
+		"sales",
+		"Billing",
+	}
+
+	analyzer := languages.NewSQLAnalyzer()
+
+	Describe("Extract SQL Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
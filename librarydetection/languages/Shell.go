@@ -0,0 +1,99 @@
+package languages
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// DefaultShellCommands are the command line tools which are detected in the shell scripts by default
+// The list is kept short, as common words like "make" or "test" would be false positives.
+var DefaultShellCommands = []string{"aws", "az", "docker", "docker-compose", "gcloud", "helm", "kubectl", "terraform"}
+
+// ShellOptions configures the shell analyzer
+type ShellOptions struct {
+	// Commands are the command line tools reported as libraries if the script runs them, like "docker" or "kubectl"
+	Commands []string
+}
+
+// NewShellAnalyzer constructor
+// It detects the DefaultShellCommands.
+func NewShellAnalyzer() librarydetection.Analyzer {
+	return NewShellAnalyzerWithOptions(ShellOptions{Commands: DefaultShellCommands})
+}
+
+// NewShellAnalyzerWithOptions constructor
+func NewShellAnalyzerWithOptions(options ShellOptions) librarydetection.Analyzer {
+	commands := map[string]bool{}
+	for _, command := range options.Commands {
+		commands[command] = true
+	}
+	return &shellAnalyzer{commands: commands}
+}
+
+type shellAnalyzer struct {
+	commands map[string]bool
+}
+
+// shellCommandPrefixes are the words which run the next word as a command, like "sudo docker ps"
+var shellCommandPrefixes = map[string]bool{
+	"!": true, "command": true, "do": true, "elif": true, "else": true, "exec": true, "if": true,
+	"nohup": true, "sudo": true, "then": true, "time": true, "until": true, "while": true, "xargs": true,
+}
+
+// ExtractLibraries returns with the names of the sourced files like "common.sh" for source "$DIR/lib/common.sh",
+// and the selected command line tools run by the script. Every library is returned once.
+func (a *shellAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to remove the comments, but not the # of variables like $# or ${#array[@]}
+	commentRegex, err := regexp.Compile(`(?m)(^|[ \t;])#.*$`)
+	if err != nil {
+		return nil, err
+	}
+	// regex to split the lines to commands at the pipes, the lists and the command substitutions
+	separatorRegex, err := regexp.Compile("\\|\\||&&|[|;&`]|\\$\\(|[()]")
+	if err != nil {
+		return nil, err
+	}
+	// regex to find environment variables set for the command like AWS_PROFILE=dev aws s3 ls
+	assignmentRegex, err := regexp.Compile(`^[a-zA-Z_][a-zA-Z0-9_]*=`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = commentRegex.ReplaceAllString(contents, "$1")
+	// Commands can be continued in the next line with a backslash
+	contents = strings.Replace(contents, "\\\r\n", " ", -1)
+	contents = strings.Replace(contents, "\\\n", " ", -1)
+
+	seen := map[string]bool{}
+	var res []string
+	add := func(library string) {
+		if library != "" && !seen[library] {
+			seen[library] = true
+			res = append(res, library)
+		}
+	}
+	for _, line := range strings.Split(contents, "\n") {
+		for _, command := range separatorRegex.Split(line, -1) {
+			fields := strings.Fields(command)
+			for len(fields) > 0 && (shellCommandPrefixes[fields[0]] || assignmentRegex.MatchString(fields[0])) {
+				fields = fields[1:]
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			name := path.Base(strings.Trim(fields[0], `"'`))
+			if (name == "source" || name == ".") && len(fields) > 1 {
+				add(path.Base(strings.Trim(fields[1], `"'`)))
+				continue
+			}
+			if a.commands[name] {
+				add(name)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("ShellLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/shell.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Extract Shell Libraries", func() {
+		It("Should be able to extract the sourced files and the default commands", func() {
+			analyzer := languages.NewShellAnalyzer()
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"common.sh", "env.sh", "docker", "aws", "terraform", "kubectl", "helm"})
+		})
+
+		It("Should only detect the configured commands", func() {
+			analyzer := languages.NewShellAnalyzerWithOptions(languages.ShellOptions{Commands: []string{"make"}})
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"common.sh", "env.sh", "make"})
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewSolidityAnalyzer constructor
+// The versions of the packages are detected by the package.json analyzer, as the contracts use npm packages.
+func NewSolidityAnalyzer() librarydetection.Analyzer {
+	return &solidityAnalyzer{}
+}
+
+type solidityAnalyzer struct{}
+
+// ExtractLibraries returns with the packages of the imports, like "@openzeppelin/contracts"
+// for import "@openzeppelin/contracts/token/ERC20/ERC20.sol"; The relative imports are not reported.
+func (a *solidityAnalyzer) ExtractLibraries(contents string) ([]string, error) {
+	// regex to find imports like import "lib/A.sol";, import "lib/A.sol" as A;, import * as A from "lib/A.sol";
+	// and import {A, B as C} from "lib/A.sol";
+	importRegex, err := regexp.Compile(`\bimport\s+(?:[^'";]+?\s+from\s+)?["']([^"'\n]+)["']`)
+	if err != nil {
+		return nil, err
+	}
+
+	contents = removeComments(contents, cStyleComments)
+
+	var res []string
+	for _, path := range executeRegexes(contents, []*regexp.Regexp{importRegex}) {
+		// relative imports like import "./Foo.sol"; are the contracts of the repository
+		if strings.HasPrefix(path, ".") || strings.HasPrefix(path, "/") {
+			continue
+		}
+		// The paths are resolved like the npm packages, the remappings of Foundry follow the same convention,
+		// like "forge-std" for import "forge-std/Test.sol";
+		res = append(res, getJavaScriptPackageName(path))
+	}
+	return res, nil
+}
@@ -0,0 +1,37 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("SolidityLibraryDetection", func() {
+	fixture, err := ioutil.ReadFile("./fixtures/solidity.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	expectedLibraries := []string{
+		"@openzeppelin/contracts",
+		"@openzeppelin/contracts",
+		"@openzeppelin/contracts-upgradeable",
+		"solmate",
+		"hardhat",
+		"@chainlink/contracts",
+	}
+
+	analyzer := languages.NewSolidityAnalyzer()
+
+	Describe("Extract Solidity Libraries", func() {
+		It("Should be able to extract libraries", func() {
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, expectedLibraries)
+		})
+	})
+})
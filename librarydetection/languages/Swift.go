@@ -27,5 +27,7 @@ func (a *swiftAnalyzer) ExtractLibraries(contents string) ([]string, error) {
 		return nil, err
 	}
 
+	contents = removeComments(contents, cStyleComments)
+
 	return executeRegexes(contents, []*regexp.Regexp{regexImport, regexDeclarations}), nil
 }
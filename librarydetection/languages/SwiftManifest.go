@@ -0,0 +1,100 @@
+package languages
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/librarydetection"
+)
+
+// NewPackageResolvedAnalyzer constructor
+func NewPackageResolvedAnalyzer() librarydetection.ManifestAnalyzer {
+	return &packageResolvedAnalyzer{}
+}
+
+// NewPackageSwiftAnalyzer constructor
+func NewPackageSwiftAnalyzer() librarydetection.ManifestAnalyzer {
+	return &packageSwiftAnalyzer{}
+}
+
+type packageResolvedAnalyzer struct{}
+
+type packageSwiftAnalyzer struct{}
+
+// packageResolved covers both formats of Package.resolved.
+// Version 1 has the pins under "object" with the package name, version 2 and later have them at the top level with the identity.
+type packageResolved struct {
+	Object struct {
+		Pins []packageResolvedPin `json:"pins"`
+	} `json:"object"`
+	Pins []packageResolvedPin `json:"pins"`
+}
+
+type packageResolvedPin struct {
+	Package       string `json:"package"`
+	Identity      string `json:"identity"`
+	RepositoryURL string `json:"repositoryURL"`
+	Location      string `json:"location"`
+	State         struct {
+		Version  string `json:"version"`
+		Branch   string `json:"branch"`
+		Revision string `json:"revision"`
+	} `json:"state"`
+}
+
+// ExtractDependencies returns with the pinned packages like "swift-nio@2.40.0" under "Swift"
+// Packages pinned to a branch or a revision are returned with the branch or the revision instead of the version.
+func (a *packageResolvedAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	var manifest packageResolved
+	err := json.Unmarshal([]byte(contents), &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	for _, pin := range append(manifest.Object.Pins, manifest.Pins...) {
+		name := pin.Identity
+		if name == "" {
+			name = pin.Package
+		}
+		if name == "" {
+			name = getSwiftPackageName(pin.RepositoryURL + pin.Location)
+		}
+		version := pin.State.Version
+		if version == "" {
+			version = pin.State.Branch
+		}
+		if version == "" {
+			version = pin.State.Revision
+		}
+		deps = append(deps, name+"@"+version)
+	}
+
+	return map[string][]string{"Swift": deps}, nil
+}
+
+// ExtractDependencies returns with the remote packages like "swift-nio@2.0.0" under "Swift"
+// The version is the first one in the requirement, like the lower bound of from: or of a range.
+func (a *packageSwiftAnalyzer) ExtractDependencies(contents string) (map[string][]string, error) {
+	// regex to find dependencies like .package(url: "https://github.com/apple/swift-nio.git", from: "2.0.0")
+	// or .package(name: "Nimble", url: "...", .upToNextMajor(from: "9.0.0")), local packages have no url
+	packageRegex, err := regexp.Compile(`\.package\(\s*(?:name:\s*"[^"]*"\s*,\s*)?url:\s*"([^"]+)"\s*,[^"]*"([^"]+)"`)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := []string{}
+	for _, match := range packageRegex.FindAllStringSubmatch(contents, -1) {
+		deps = append(deps, getSwiftPackageName(match[1])+"@"+match[2])
+	}
+
+	return map[string][]string{"Swift": deps}, nil
+}
+
+// getSwiftPackageName returns with the identity of the package in the url,
+// which is the lowercase last path segment without the .git extension
+func getSwiftPackageName(url string) string {
+	return strings.ToLower(strings.TrimSuffix(path.Base(strings.TrimSuffix(url, "/")), ".git"))
+}
@@ -0,0 +1,62 @@
+package languages_test
+
+import (
+	"io/ioutil"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/librarydetection/languages"
+)
+
+var _ = Describe("SwiftManifestDependencyDetection", func() {
+	fixtureV1, err := ioutil.ReadFile("./fixtures/packageresolved_v1.fixture")
+	if err != nil {
+		panic(err)
+	}
+	fixtureV2, err := ioutil.ReadFile("./fixtures/packageresolved_v2.fixture")
+	if err != nil {
+		panic(err)
+	}
+	packageSwiftFixture, err := ioutil.ReadFile("./fixtures/packageswift.fixture")
+	if err != nil {
+		panic(err)
+	}
+
+	Describe("Extract Package.resolved dependencies", func() {
+		analyzer := languages.NewPackageResolvedAnalyzer()
+
+		It("Should be able to extract the pinned packages of the version 1 format", func() {
+			deps, err := analyzer.ExtractDependencies(string(fixtureV1))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Swift"], []string{"SwiftNIO@2.40.0", "Alamofire@master"})
+		})
+
+		It("Should be able to extract the pinned packages of the version 2 format", func() {
+			deps, err := analyzer.ExtractDependencies(string(fixtureV2))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Swift"], []string{"swift-nio@2.40.0", "kingfisher@59eb199a2b6f7d2e6c5e4e7fd3a0f0e6a8b3c1d2"})
+		})
+
+		It("Should return an error for malformed files", func() {
+			_, err := analyzer.ExtractDependencies(`{"pins": [`)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Describe("Extract Package.swift dependencies", func() {
+		analyzer := languages.NewPackageSwiftAnalyzer()
+
+		It("Should be able to extract the remote packages with versions", func() {
+			deps, err := analyzer.ExtractDependencies(string(packageSwiftFixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(deps["Swift"], []string{"swift-nio@2.0.0", "nimble@9.0.0", "alamofire@master", "swift-log@1.0.0"})
+		})
+	})
+})
@@ -2,26 +2,68 @@ package languages
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
 
+// TypeScriptOptions configure the library detection of the TypeScript files
+type TypeScriptOptions struct {
+	// ExcludeTypeImports drops the type-only imports, like import type { Foo } from 'bar' or import { type Foo } from 'bar',
+	// which are erased by the compiler, so they are not runtime dependencies
+	ExcludeTypeImports bool
+}
+
 // NewTypeScriptAnalyzer constructor
 func NewTypeScriptAnalyzer() librarydetection.Analyzer {
 	return &typeScriptAnalyzer{}
 }
 
-type typeScriptAnalyzer struct{}
+// NewTypeScriptAnalyzerWithOptions constructor
+func NewTypeScriptAnalyzerWithOptions(options TypeScriptOptions) librarydetection.Analyzer {
+	return &typeScriptAnalyzer{options: options}
+}
+
+type typeScriptAnalyzer struct {
+	options TypeScriptOptions
+}
 
 func (a *typeScriptAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	require, err := regexp.Compile(`require\(["\'](.+)["\']\);?\s`)
+	if a.options.ExcludeTypeImports {
+		var err error
+		contents, err = removeTypeScriptTypeImports(contents)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return extractJavaScriptLibraries(contents)
+}
+
+// removeTypeScriptTypeImports removes the imports and the re-exports which only import types
+// An import with a value besides the types, like import { type Config, defineConfig } from 'vite', is kept.
+func removeTypeScriptTypeImports(contents string) (string, error) {
+	// matches import type Foo from 'bar', import type { Foo } from 'bar', import type * as Foo from 'bar'
+	// and export type { Foo } from 'bar'. Only the braces can span lines, so a type alias like export type A = B
+	// doesn't match until the next import.
+	typeImport, err := regexp.Compile(`\b(?:import|export)\s+type\s+(?:\{[^}]*\}|[^'"();=\n{]+?)\s*from\s+["'][^"'\n]+["']`)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	importRegex, err := regexp.Compile(`import\s*(?:.+ from)?\s?\(?[\'"](.+)[\'"]\)?;?\s`)
+	// matches the imports and the re-exports of named specifiers like import { type Foo, Bar } from 'bar'
+	namedImport, err := regexp.Compile(`\b(?:import|export)\s*\{([^}]*)\}\s*from\s*["'][^"'\n]+["']`)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return executeRegexes(contents, []*regexp.Regexp{require, importRegex}), nil
+	contents = typeImport.ReplaceAllString(contents, "")
+	return namedImport.ReplaceAllStringFunc(contents, func(statement string) string {
+		specifiers := namedImport.FindStringSubmatch(statement)[1]
+		for _, specifier := range strings.Split(specifiers, ",") {
+			specifier = strings.TrimSpace(specifier)
+			if specifier != "" && !strings.HasPrefix(specifier, "type ") {
+				return statement
+			}
+		}
+		return ""
+	}), nil
 }
@@ -19,6 +19,17 @@ var _ = Describe("TypeScriptLibraryDetection", func() {
 		"lib2",
 		"lib3",
 		"lib4",
+		"express",
+		"@angular/core",
+		"lodash",
+		"@scope/pkg",
+		"express-serve-static-core",
+		"koa",
+		"mongoose",
+		"vite",
+		"zod",
+		"redux",
+		"redux-thunk",
 	}
 
 	analyzer := languages.NewTypeScriptAnalyzer()
@@ -31,5 +42,23 @@ var _ = Describe("TypeScriptLibraryDetection", func() {
 			}
 			assertSameUnordered(libs, expectedLibraries)
 		})
+
+		It("Should be able to exclude the type-only imports", func() {
+			analyzer := languages.NewTypeScriptAnalyzerWithOptions(languages.TypeScriptOptions{ExcludeTypeImports: true})
+			libs, err := analyzer.ExtractLibraries(string(fixture))
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"lib1", "lib2", "lib3", "lib4", "express", "@angular/core", "lodash", "@scope/pkg", "vite", "zod"})
+		})
+
+		It("Should keep the imports after the type aliases", func() {
+			analyzer := languages.NewTypeScriptAnalyzerWithOptions(languages.TypeScriptOptions{ExcludeTypeImports: true})
+			libs, err := analyzer.ExtractLibraries("export type A = B\nimport x from 'react'\nimport type {\n  C,\n} from 'types'\n")
+			if err != nil {
+				panic(err)
+			}
+			assertSameUnordered(libs, []string{"react"})
+		})
 	})
 })
@@ -29,5 +29,7 @@ func (a *cSparpAnalyzer) ExtractLibraries(contents string) ([]string, error) {
 		regex2,
 	}
 
+	contents = removeComments(contents, cStyleComments)
+
 	return executeRegexes(contents, regexes), nil
 }
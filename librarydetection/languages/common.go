@@ -1,6 +1,10 @@
 package languages
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
 
 // executeRegexes is a helper function that executes a number of regexes and assumes each of them returns 1 group only
 func executeRegexes(contents string, regexes []*regexp.Regexp) []string {
@@ -16,3 +20,162 @@ func executeRegexes(contents string, regexes []*regexp.Regexp) []string {
 	}
 	return res
 }
+
+// jvmImportPackage returns with the package of a Java or Kotlin import, like "com.foo" for "com.foo.Bar".
+// Wildcard imports like "com.foo.*" import the package itself. Otherwise the imported class or function is removed,
+// together with the outer classes of nested classes, which start with an uppercase letter by convention.
+func jvmImportPackage(importPath string) string {
+	segments := strings.Split(importPath, ".")
+	if segments[len(segments)-1] == "*" || len(segments) > 1 {
+		segments = segments[:len(segments)-1]
+	}
+	for len(segments) > 1 && strings.IndexFunc(segments[len(segments)-1], unicode.IsUpper) == 0 {
+		segments = segments[:len(segments)-1]
+	}
+	return strings.Join(segments, ".")
+}
+
+// commentSyntax describes the comments and the string literals of a language for removeComments
+// The delimiters are matched in the order of the lists, so the longer ones like `"""` must precede `"`.
+type commentSyntax struct {
+	// lineComments start comments which end at the end of the line, like "//" or "#"
+	lineComments []string
+	// blockComments are the opening and closing delimiters of the block comments, like "/*" and "*/"
+	blockComments [][2]string
+	// lineBlockComments are the block comments which are only opened at the beginning of a line,
+	// like the POD of Perl from "=pod" to "=cut"
+	lineBlockComments [][2]string
+	// quotes are the delimiters of the string literals, the comment delimiters inside them are kept, like "//" in
+	// "http://example.com". The strings delimited by a single character don't continue in the next line.
+	quotes []string
+	// docStrings are the delimiters of the string literals which are removed with their content,
+	// like the docstrings of Python which often contain example imports
+	docStrings []string
+}
+
+// cStyleComments are the comments of C, C++, C#, Go, Java, JavaScript, Kotlin, PHP, Swift and TypeScript
+var cStyleComments = commentSyntax{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"""`, `"`, `'`, "`"},
+}
+
+// phpComments are the comments of PHP, which has shell-style comments too
+var phpComments = commentSyntax{
+	lineComments:  []string{"//", "#"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        []string{`"`, `'`},
+}
+
+// pythonComments are the comments and the docstrings of Python
+var pythonComments = commentSyntax{
+	lineComments: []string{"#"},
+	quotes:       []string{`"`, `'`},
+	docStrings:   []string{`"""`, `'''`},
+}
+
+// rubyComments are the line comments of Ruby
+var rubyComments = commentSyntax{
+	lineComments: []string{"#"},
+	quotes:       []string{`"`, `'`},
+}
+
+// removeComments removes the comments of the source code, so the commented out imports are not extracted
+// The line comments are removed until the end of the line and the block comments are replaced by their line breaks,
+// so the statements stay on their lines for the regexes matching the beginning of the lines.
+func removeComments(contents string, syntax commentSyntax) string {
+	var res strings.Builder
+	res.Grow(len(contents))
+	for i := 0; i < len(contents); {
+		rest := contents[i:]
+		if delimiter := findPrefix(rest, syntax.lineComments); delimiter != "" {
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				break
+			}
+			i += end
+			continue
+		}
+		delimiters, ok := findBlockComment(rest, syntax.blockComments)
+		if !ok && (i == 0 || contents[i-1] == '\n') {
+			delimiters, ok = findBlockComment(rest, syntax.lineBlockComments)
+		}
+		if ok {
+			end := strings.Index(rest[len(delimiters[0]):], delimiters[1])
+			if end == -1 {
+				break
+			}
+			comment := rest[:len(delimiters[0])+end+len(delimiters[1])]
+			res.WriteString(blankComment(comment))
+			i += len(comment)
+			continue
+		}
+		if delimiter := findPrefix(rest, syntax.docStrings); delimiter != "" {
+			end := strings.Index(rest[len(delimiter):], delimiter)
+			if end == -1 {
+				break
+			}
+			docString := rest[:len(delimiter)+end+len(delimiter)]
+			res.WriteString(blankComment(docString))
+			i += len(docString)
+			continue
+		}
+		if delimiter := findPrefix(rest, syntax.quotes); delimiter != "" {
+			literal := stringLiteral(rest, delimiter)
+			res.WriteString(literal)
+			i += len(literal)
+			continue
+		}
+		res.WriteByte(contents[i])
+		i++
+	}
+	return res.String()
+}
+
+// findPrefix returns with the first delimiter which the text starts with, or "" if none
+func findPrefix(text string, delimiters []string) string {
+	for _, delimiter := range delimiters {
+		if strings.HasPrefix(text, delimiter) {
+			return delimiter
+		}
+	}
+	return ""
+}
+
+// findBlockComment returns with the delimiters of the block comment which the text starts with
+func findBlockComment(text string, blockComments [][2]string) ([2]string, bool) {
+	for _, delimiters := range blockComments {
+		if strings.HasPrefix(text, delimiters[0]) {
+			return delimiters, true
+		}
+	}
+	return [2]string{}, false
+}
+
+// blankComment returns with the line breaks of the comment, or a space if it has none, so the code around
+// a comment like import /* old */ foo is not joined
+func blankComment(comment string) string {
+	lineBreaks := strings.Count(comment, "\n")
+	if lineBreaks == 0 {
+		return " "
+	}
+	return strings.Repeat("\n", lineBreaks)
+}
+
+// stringLiteral returns with the string literal which the text starts with, including its delimiters
+// The escaped delimiters are skipped. Unterminated literals end at the end of the line, or at the end of the text
+// if the delimiter is longer than one character.
+func stringLiteral(text, delimiter string) string {
+	multiline := len(delimiter) > 1 || delimiter == "`"
+	for i := len(delimiter); i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case text[i] == '\n' && !multiline:
+			return text[:i]
+		case strings.HasPrefix(text[i:], delimiter):
+			return text[:i+len(delimiter)]
+		}
+	}
+	return text
+}
@@ -0,0 +1,238 @@
+package languages
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GoOptions configures the Go analyzer
+type GoOptions struct {
+	// GOOS and GOARCH are the target platform, like "linux" and "amd64".
+	// If any of the options is set, the files whose //go:build or // +build constraints don't match the target
+	// are skipped. An empty value matches every operating system or architecture. By default every file is analysed.
+	GOOS   string
+	GOARCH string
+	// BuildTags are the custom build tags satisfied by the target, like "integration"
+	BuildTags []string
+	// ExcludeStandardLibrary drops the imports of the standard library like "fmt" or "net/http",
+	// so only the third-party packages are reported
+	ExcludeStandardLibrary bool
+}
+
+var knownGOOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true, "illumos": true,
+	"ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true, "openbsd": true, "plan9": true,
+	"solaris": true, "wasip1": true, "windows": true, "zos": true,
+}
+
+var unixGOOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true, "illumos": true,
+	"ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+var knownGOARCH = map[string]bool{
+	"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true, "mips": true, "mipsle": true,
+	"mips64": true, "mips64le": true, "ppc64": true, "ppc64le": true, "riscv64": true, "s390x": true, "wasm": true,
+}
+
+// goBuildTokenRegex splits //go:build expressions into parentheses, operators and tags
+var goBuildTokenRegex = regexp.MustCompile(`\(|\)|!|&&|\|\||[A-Za-z0-9_.]+`)
+
+// isTargeted tells if the options select a target platform
+func (o GoOptions) isTargeted() bool {
+	return o.GOOS != "" || o.GOARCH != "" || len(o.BuildTags) > 0
+}
+
+// isSatisfied tells if the term of a build constraint, like "linux" or "!cgo", is satisfied by the target
+func (o GoOptions) isSatisfied(tag string, negated bool) bool {
+	return o.hasTag(tag) != negated
+}
+
+// targets returns with the platforms matched by the options
+// The target matches every operating system or architecture it doesn't set, so the file is built for the target if
+// any of them satisfies the constraints. This way "!windows" and "!(windows)" are evaluated the same way.
+func (o GoOptions) targets() []GoOptions {
+	goos := []string{o.GOOS}
+	if o.GOOS == "" {
+		goos = goos[:0]
+		for targetOS := range knownGOOS {
+			goos = append(goos, targetOS)
+		}
+	}
+	goarch := []string{o.GOARCH}
+	if o.GOARCH == "" {
+		goarch = goarch[:0]
+		for targetArch := range knownGOARCH {
+			goarch = append(goarch, targetArch)
+		}
+	}
+
+	targets := make([]GoOptions, 0, len(goos)*len(goarch))
+	for _, targetOS := range goos {
+		for _, targetArch := range goarch {
+			target := o
+			target.GOOS = targetOS
+			target.GOARCH = targetArch
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// hasTag tells if the build tag is satisfied by the target
+func (o GoOptions) hasTag(tag string) bool {
+	switch {
+	case knownGOOS[tag]:
+		// Android is also Linux, illumos is also Solaris and iOS is also Darwin
+		return o.GOOS == tag ||
+			(tag == "linux" && o.GOOS == "android") ||
+			(tag == "solaris" && o.GOOS == "illumos") ||
+			(tag == "darwin" && o.GOOS == "ios")
+	case tag == "unix":
+		return unixGOOS[o.GOOS]
+	case knownGOARCH[tag]:
+		return o.GOARCH == tag
+	case tag == "gc" || strings.HasPrefix(tag, "go1."):
+		// The default compiler and every release tag
+		return true
+	}
+	for _, buildTag := range o.BuildTags {
+		if buildTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesBuildConstraints tells if the Go file is built for the target
+// The constraints are read from the //go:build line, or from the // +build lines of older files, before the package clause.
+// The _GOOS_GOARCH.go suffixes of the file names are not checked, as the analyzers only get the content of the files.
+func (o GoOptions) matchesBuildConstraints(contents string) bool {
+	var goBuildLine string
+	var plusBuildLines []string
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		if strings.HasPrefix(line, "//go:build ") {
+			// The //go:build line takes precedence over the // +build lines
+			goBuildLine = strings.TrimPrefix(line, "//go:build ")
+			break
+		}
+		if strings.HasPrefix(line, "// +build ") {
+			plusBuildLines = append(plusBuildLines, strings.TrimPrefix(line, "// +build "))
+		}
+	}
+	if goBuildLine == "" && len(plusBuildLines) == 0 {
+		return true
+	}
+
+	for _, target := range o.targets() {
+		if target.satisfiesBuildConstraints(goBuildLine, plusBuildLines) {
+			return true
+		}
+	}
+	return false
+}
+
+// satisfiesBuildConstraints tells if the platform of the options satisfies the //go:build line if it is set,
+// otherwise every // +build line
+func (o GoOptions) satisfiesBuildConstraints(goBuildLine string, plusBuildLines []string) bool {
+	if goBuildLine != "" {
+		return o.evalGoBuildExpression(goBuildLine)
+	}
+	for _, line := range plusBuildLines {
+		if !o.evalPlusBuildLine(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// evalPlusBuildLine evaluates a // +build line, where the space separated options are ORed
+// and the comma separated terms of an option are ANDed, like "linux,386 darwin,!cgo"
+func (o GoOptions) evalPlusBuildLine(line string) bool {
+	for _, option := range strings.Fields(line) {
+		satisfied := true
+		for _, term := range strings.Split(option, ",") {
+			if !o.isSatisfied(strings.TrimPrefix(term, "!"), strings.HasPrefix(term, "!")) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// evalGoBuildExpression evaluates a //go:build expression like "(linux || darwin) && !cgo"
+func (o GoOptions) evalGoBuildExpression(expression string) bool {
+	parser := &goBuildExpressionParser{
+		tokens:  goBuildTokenRegex.FindAllString(expression, -1),
+		options: o,
+	}
+	return parser.or()
+}
+
+// goBuildExpressionParser is a recursive descent parser of //go:build expressions
+// Malformed expressions are evaluated as far as they can be parsed.
+type goBuildExpressionParser struct {
+	tokens   []string
+	position int
+	options  GoOptions
+}
+
+func (p *goBuildExpressionParser) peek() string {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position]
+	}
+	return ""
+}
+
+func (p *goBuildExpressionParser) next() string {
+	token := p.peek()
+	p.position++
+	return token
+}
+
+func (p *goBuildExpressionParser) or() bool {
+	result := p.and()
+	for p.peek() == "||" {
+		p.next()
+		right := p.and()
+		result = result || right
+	}
+	return result
+}
+
+func (p *goBuildExpressionParser) and() bool {
+	result := p.not()
+	for p.peek() == "&&" {
+		p.next()
+		right := p.not()
+		result = result && right
+	}
+	return result
+}
+
+func (p *goBuildExpressionParser) not() bool {
+	switch p.peek() {
+	case "!":
+		p.next()
+		if p.peek() != "!" && p.peek() != "(" {
+			return p.options.isSatisfied(p.next(), true)
+		}
+		return !p.not()
+	case "(":
+		p.next()
+		result := p.or()
+		if p.peek() == ")" {
+			p.next()
+		}
+		return result
+	}
+	return p.options.isSatisfied(p.next(), false)
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/Techloopio/extractor_tool/librarydetection"
 )
 
-// NewCAnalyzer constructor
+// NewKotlinAnalyzer constructor
 func NewKotlinAnalyzer() librarydetection.Analyzer {
 	return &kotlinAnalyzer{}
 }
@@ -15,12 +15,14 @@ func NewKotlinAnalyzer() librarydetection.Analyzer {
 type kotlinAnalyzer struct{}
 
 func (a *kotlinAnalyzer) ExtractLibraries(contents string) ([]string, error) {
-	// regex to find imports like org.example (exclude standard java kotlin libraries)
-	regex1, err := regexp.Compile(`(?i)import ([a-zA-Z0-9.]*[^.*\n])`)
+	// regex to find imports like `import org.example.Foo`, `import org.example.Foo as Bar` or `import org.example.*`
+	regex1, err := regexp.Compile(`(?m)^[ \t]*import[ \t]+([a-zA-Z0-9_.]+(?:\.\*)?)`)
 	if err != nil {
 		return nil, err
 	}
 
+	contents = removeComments(contents, cStyleComments)
+
 	ret := executeRegexes(contents, []*regexp.Regexp{regex1})
 	var res = []string{}
 	for _, v := range ret {
@@ -29,7 +31,9 @@ func (a *kotlinAnalyzer) ExtractLibraries(contents string) ([]string, error) {
 			continue
 		}
 
-		res = append(res, v)
+		if pkg := jvmImportPackage(v); pkg != "" {
+			res = append(res, pkg)
+		}
 	}
 
 	return res, nil
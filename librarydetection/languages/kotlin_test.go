@@ -8,20 +8,23 @@ import (
 	"github.com/Techloopio/extractor_tool/librarydetection/languages"
 )
 
-var _ = Describe("CLibraryDetection", func() {
+var _ = Describe("KotlinLibraryDetection", func() {
 	fixture, err := ioutil.ReadFile("./fixtures/kotlin.fixture")
 	if err != nil {
 		panic(err)
 	}
 
 	expectedLibraries := []string{
-		"syntax.lexer.Token",
+		"syntax.lexer",
 		"syntax.tree",
+		"org.jetbrains.exposed.sql",
+		"io.ktor.server.application",
+		"com.squareup.moshi",
 	}
 
 	analyzer := languages.NewKotlinAnalyzer()
 
-	Describe("Extract C Libraries", func() {
+	Describe("Extract Kotlin Libraries", func() {
 		It("Should be able to extract libraries", func() {
 			libs, err := analyzer.ExtractLibraries(string(fixture))
 			if err != nil {
@@ -39,6 +39,8 @@ func (a *phpAnalyzer) ExtractLibraries(contents string) ([]string, error) {
 		return nil, err
 	}
 
+	contents = removeComments(contents, phpComments)
+
 	ret := executeRegexes(contents, []*regexp.Regexp{regex1, regex2})
 
 	var res []string
@@ -0,0 +1,68 @@
+package languages
+
+// pythonCommonStandardLibrary are the top-level modules of the standard library of both Python 2.7 and Python 3
+var pythonCommonStandardLibrary = []string{
+	"__future__", "__main__", "abc", "aifc", "argparse", "array", "ast", "asynchat", "asyncore", "atexit", "audioop",
+	"base64", "bdb", "binascii", "binhex", "bisect", "bz2", "cProfile", "calendar", "cgi", "cgitb", "chunk", "cmath",
+	"cmd", "code", "codecs", "codeop", "collections", "colorsys", "compileall", "contextlib", "copy", "crypt", "csv",
+	"ctypes", "curses", "datetime", "dbm", "decimal", "difflib", "dis", "distutils", "doctest", "dummy_threading",
+	"email", "encodings", "ensurepip", "errno", "fcntl", "filecmp", "fileinput", "fnmatch", "fractions", "ftplib",
+	"functools", "gc", "getopt", "getpass", "gettext", "glob", "grp", "gzip", "hashlib", "heapq", "hmac", "imaplib",
+	"imghdr", "imp", "importlib", "inspect", "io", "itertools", "json", "keyword", "lib2to3", "linecache", "locale",
+	"logging", "mailbox", "mailcap", "marshal", "math", "mimetypes", "mmap", "modulefinder", "msilib", "msvcrt",
+	"multiprocessing", "netrc", "nis", "nntplib", "numbers", "operator", "optparse", "os", "ossaudiodev", "pdb",
+	"pickle", "pickletools", "pipes", "pkgutil", "platform", "plistlib", "poplib", "posix", "pprint", "profile",
+	"pstats", "pty", "pwd", "py_compile", "pyclbr", "pydoc", "pyexpat", "quopri", "random", "re", "readline",
+	"resource", "rlcompleter", "runpy", "sched", "select", "shelve", "shlex", "shutil", "signal", "site", "smtpd",
+	"smtplib", "sndhdr", "socket", "spwd", "sqlite3", "ssl", "stat", "string", "stringprep", "struct", "subprocess",
+	"sunau", "symbol", "symtable", "sys", "sysconfig", "syslog", "tabnanny", "tarfile", "telnetlib", "tempfile",
+	"termios", "textwrap", "threading", "time", "timeit", "token", "tokenize", "trace", "traceback", "tty",
+	"turtle", "types", "unicodedata", "unittest", "urllib", "uu", "uuid", "warnings", "wave", "weakref", "webbrowser",
+	"wsgiref", "xdrlib", "xml", "zipfile", "zipimport", "zlib",
+}
+
+// python2StandardLibrary are the top-level modules of the standard library of Python 2.7 only,
+// most of them were renamed or removed by Python 3
+var python2StandardLibrary = []string{
+	"BaseHTTPServer", "Bastion", "CGIHTTPServer", "ConfigParser", "Cookie", "DocXMLRPCServer", "HTMLParser",
+	"MimeWriter", "Queue", "SimpleHTTPServer", "SimpleXMLRPCServer", "SocketServer", "StringIO", "Tkinter", "UserDict",
+	"UserList", "UserString", "__builtin__", "_winreg", "anydbm", "cPickle", "cStringIO", "commands", "cookielib",
+	"copy_reg", "dbhash", "dircache", "dummy_thread", "exceptions", "fpformat", "future_builtins", "hotshot",
+	"htmlentitydefs", "httplib", "ihooks", "imputil", "md5", "mhlib", "mimetools", "mimify", "multifile", "mutex", "new",
+	"popen2", "posixfile", "repr", "rexec", "rfc822", "robotparser", "sets", "sgmllib", "sha", "statvfs", "thread",
+	"urllib2", "urlparse", "user", "whichdb", "xmlrpclib",
+}
+
+// python3StandardLibrary are the top-level modules of the standard library of Python 3 only
+var python3StandardLibrary = []string{
+	"_thread", "asyncio", "builtins", "concurrent", "configparser", "contextvars", "copyreg", "dataclasses", "enum",
+	"faulthandler", "graphlib", "html", "http", "ipaddress", "lzma", "pathlib", "queue", "reprlib", "secrets",
+	"selectors", "socketserver", "statistics", "tkinter", "tomllib", "tracemalloc", "typing", "venv", "winreg",
+	"xmlrpc", "zipapp", "zoneinfo",
+}
+
+// pythonStandardLibrary are the top-level modules of the standard library by the major versions of Python
+var pythonStandardLibrary = map[int]map[string]bool{
+	2: newPythonModuleSet(pythonCommonStandardLibrary, python2StandardLibrary),
+	3: newPythonModuleSet(pythonCommonStandardLibrary, python3StandardLibrary),
+}
+
+func newPythonModuleSet(moduleLists ...[]string) map[string]bool {
+	modules := map[string]bool{}
+	for _, moduleList := range moduleLists {
+		for _, module := range moduleList {
+			modules[module] = true
+		}
+	}
+	return modules
+}
+
+// IsPythonStandardLibrary tells if the module belongs to the standard library of the major version of Python,
+// like "os" or "os.path". If the version is 0, the modules of both Python 2 and Python 3 belong to the standard library.
+func IsPythonStandardLibrary(module string, version int) bool {
+	topLevelModule := pythonTopLevelModule(module)
+	if version == 0 {
+		return pythonStandardLibrary[2][topLevelModule] || pythonStandardLibrary[3][topLevelModule]
+	}
+	return pythonStandardLibrary[version][topLevelModule]
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// FormatText writes the events in human-readable lines (default)
+	FormatText = "text"
+	// FormatJSON writes one JSON object per line for every event
+	FormatJSON = "json"
+)
+
+const (
+	// LevelInfo is the level of the informational events, like the steps of the extraction
+	LevelInfo = "info"
+	// LevelWarning is the level of the events which don't stop the extraction, but the result can be partial
+	LevelWarning = "warning"
+	// LevelError is the level of the errors
+	LevelError = "error"
+)
+
+// Fields are the additional data of an event, like the number of commits or the path of the export
+type Fields map[string]interface{}
+
+// Logger writes the events of the extraction in the selected format.
+// In quiet mode the informational events are discarded, the warnings and errors are still written.
+// A nil logger discards every event.
+type Logger struct {
+	format string
+	writer io.Writer
+	quiet  bool
+	now    func() time.Time
+	mutex  sync.Mutex
+}
+
+// jsonEvent is one line of the JSON log
+type jsonEvent struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Fields  Fields `json:"fields,omitempty"`
+}
+
+// NewLogger constructor. Empty format means FormatText.
+func NewLogger(format string, writer io.Writer, quiet bool) (*Logger, error) {
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+
+	return &Logger{
+		format: format,
+		writer: writer,
+		quiet:  quiet,
+		now:    time.Now,
+	}, nil
+}
+
+// IsJSON returns true if the events are written in JSON
+func (l *Logger) IsJSON() bool {
+	return l != nil && l.format == FormatJSON
+}
+
+// Info writes an informational event
+func (l *Logger) Info(message string, fields Fields) {
+	if l == nil || l.quiet {
+		return
+	}
+	l.write(LevelInfo, message, fields)
+}
+
+// Warning writes an event which doesn't stop the extraction
+func (l *Logger) Warning(message string, fields Fields) {
+	if l == nil {
+		return
+	}
+	l.write(LevelWarning, message, fields)
+}
+
+// Error writes an error event
+func (l *Logger) Error(message string, fields Fields) {
+	if l == nil {
+		return
+	}
+	l.write(LevelError, message, fields)
+}
+
+func (l *Logger) write(level, message string, fields Fields) {
+	var line string
+	if l.format == FormatJSON {
+		data, err := json.Marshal(jsonEvent{
+			Time:    l.now().UTC().Format(time.RFC3339),
+			Level:   level,
+			Message: message,
+			Fields:  fields,
+		})
+		if err != nil {
+			return
+		}
+		line = string(data)
+	} else {
+		line = formatText(message, fields)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	fmt.Fprintln(l.writer, line)
+}
+
+// formatText returns with the message followed by the fields in key=value format, ordered by the keys
+func formatText(message string, fields Fields) string {
+	if len(fields) == 0 {
+		return message
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	builder.WriteString(message)
+	for _, key := range keys {
+		fmt.Fprintf(&builder, " %s=%v", key, fields[key])
+	}
+	return builder.String()
+}
@@ -0,0 +1,59 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/Techloopio/extractor_tool/logger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger", func() {
+	It("should write the message and the fields in text format", func() {
+		var output bytes.Buffer
+		log, err := logger.NewLogger(logger.FormatText, &output, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		log.Info("Exported!", logger.Fields{"path": "export/repo_techloop.json", "commits": 3})
+		log.Error("Cannot create pipe", nil)
+
+		Expect(output.String()).To(Equal("Exported! commits=3 path=export/repo_techloop.json\nCannot create pipe\n"))
+	})
+
+	It("should write one JSON object per event in JSON format", func() {
+		var output bytes.Buffer
+		log, err := logger.NewLogger(logger.FormatJSON, &output, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		log.Warning("Error extracting libraries", logger.Fields{"language": "Go"})
+
+		var event map[string]interface{}
+		Expect(json.Unmarshal(output.Bytes(), &event)).To(Succeed())
+		Expect(event["level"]).To(Equal(logger.LevelWarning))
+		Expect(event["message"]).To(Equal("Error extracting libraries"))
+		Expect(event["fields"]).To(Equal(map[string]interface{}{"language": "Go"}))
+		Expect(event["time"]).ToNot(BeEmpty())
+	})
+
+	It("should discard only the informational events in quiet mode", func() {
+		var output bytes.Buffer
+		log, err := logger.NewLogger(logger.FormatText, &output, true)
+		Expect(err).ToNot(HaveOccurred())
+
+		log.Info("Analysing commits", nil)
+		log.Error("Cannot create pipe", nil)
+
+		Expect(output.String()).To(Equal("Cannot create pipe\n"))
+	})
+
+	It("should discard every event with a nil logger", func() {
+		var log *logger.Logger
+		Expect(func() { log.Error("Cannot create pipe", nil) }).ToNot(Panic())
+	})
+
+	It("should return with error if the format is unknown", func() {
+		_, err := logger.NewLogger("xml", &bytes.Buffer{}, false)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,13 @@
+package obfuscation_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestObfuscation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Obfuscation Suite")
+}
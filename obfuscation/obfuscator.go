@@ -2,20 +2,146 @@ package obfuscation
 
 import (
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"hash"
+	"sync"
 
 	"github.com/Techloopio/extractor_tool/commit"
 )
 
-// Obfuscate private info, like filename, username and emails
-func Obfuscate(c *commit.OptimizedCommitForExport) {
+const (
+	// AlgorithmMD5 is the default hash algorithm, kept for backward compatibility
+	AlgorithmMD5 = "md5"
+	// AlgorithmSHA1 hashes with SHA-1
+	AlgorithmSHA1 = "sha1"
+	// AlgorithmSHA256 hashes with SHA-256
+	AlgorithmSHA256 = "sha256"
+)
+
+// Obfuscator hashes private info with the selected algorithm and salt.
+// The same text is always hashed to the same value with the same algorithm and salt,
+// so the exports of different runs can be matched.
+type Obfuscator struct {
+	algorithm string
+	newHash   func() hash.Hash
+	salt      string
+	// hashes are the hashes by the hashed texts, if they are recorded
+	mutex  sync.Mutex
+	hashes map[string]string
+}
+
+// NewObfuscator constructor. Empty algorithm means md5.
+func NewObfuscator(algorithm, salt string) (*Obfuscator, error) {
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "", AlgorithmMD5:
+		algorithm = AlgorithmMD5
+		newHash = md5.New
+	case AlgorithmSHA1:
+		newHash = sha1.New
+	case AlgorithmSHA256:
+		newHash = sha256.New
+	default:
+		return nil, fmt.Errorf("unknown hash algorithm: %s", algorithm)
+	}
+
+	return &Obfuscator{
+		algorithm: algorithm,
+		newHash:   newHash,
+		salt:      salt,
+	}, nil
+}
+
+// ObfuscateAuthorEmails obfuscates the emails of the authors of an aggregated commit
+func (o *Obfuscator) ObfuscateAuthorEmails(c *commit.OptimizedCommitForExport) {
 	for index, email := range c.AuthorEmails {
-		c.AuthorEmails[index] = toMD5(email)
+		c.AuthorEmails[index] = o.Hash(email)
+	}
+}
+
+// ObfuscateLibraries obfuscates the names of the libraries, like the internal packages of a company
+// The languages of the libraries are kept in clear text.
+func (o *Obfuscator) ObfuscateLibraries(libraries map[string][]string) {
+	for _, languageLibraries := range libraries {
+		for index, library := range languageLibraries {
+			languageLibraries[index] = o.Hash(library)
+		}
+	}
+}
+
+// ObfuscateLibraryCounts obfuscates the names of the libraries of the counts, the counts stay readable
+func (o *Obfuscator) ObfuscateLibraryCounts(libraryCounts map[string]map[string]int) {
+	for language, counts := range libraryCounts {
+		hashedCounts := make(map[string]int, len(counts))
+		for library, count := range counts {
+			hashedCounts[o.Hash(library)] += count
+		}
+		libraryCounts[language] = hashedCounts
+	}
+}
+
+// ObfuscateRaw obfuscates the authors of a non-aggregated commit
+func (o *Obfuscator) ObfuscateRaw(c *commit.RawCommitForExport) {
+	c.AuthorName = o.Hash(c.AuthorName)
+	o.ObfuscateRawEmails(c)
+}
+
+// ObfuscateRawEmails obfuscates the emails of the authors of a non-aggregated commit
+func (o *Obfuscator) ObfuscateRawEmails(c *commit.RawCommitForExport) {
+	c.AuthorEmail = o.Hash(c.AuthorEmail)
+	for index, email := range c.CoAuthorEmails {
+		c.CoAuthorEmails[index] = o.Hash(email)
+	}
+}
+
+// Hash returns with the hex encoded hash of the salted text
+func (o *Obfuscator) Hash(text string) string {
+	hashed := o.hash(text)
+	o.mutex.Lock()
+	if o.hashes != nil {
+		o.hashes[text] = hashed
 	}
+	o.mutex.Unlock()
+	return hashed
 }
 
-func toMD5(text string) string {
-	algorithm := md5.New()
+func (o *Obfuscator) hash(text string) string {
+	algorithm := o.newHash()
+	algorithm.Write([]byte(o.salt))
 	algorithm.Write([]byte(text))
 	return hex.EncodeToString(algorithm.Sum(nil))
 }
+
+// Algorithm returns with the name of the hash algorithm
+func (o *Obfuscator) Algorithm() string {
+	return o.algorithm
+}
+
+// Fingerprint returns with the hash of the empty text, which tells if two obfuscators hash the same way without
+// revealing the salt
+func (o *Obfuscator) Fingerprint() string {
+	return o.hash("")
+}
+
+// RecordHashes makes the obfuscator remember the hashed texts, so the hashes can be reversed with the Hashes
+func (o *Obfuscator) RecordHashes() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.hashes == nil {
+		o.hashes = map[string]string{}
+	}
+}
+
+// Hashes returns with the hashes of the texts hashed since RecordHashes was called, by the original texts
+func (o *Obfuscator) Hashes() map[string]string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	hashes := make(map[string]string, len(o.hashes))
+	for text, hashed := range o.hashes {
+		hashes[text] = hashed
+	}
+	return hashes
+}
@@ -0,0 +1,73 @@
+package obfuscation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/commit"
+	"github.com/Techloopio/extractor_tool/obfuscation"
+)
+
+var _ = Describe("Obfuscator", func() {
+	It("should hash with md5 without salt by default", func() {
+		o, err := obfuscation.NewObfuscator("", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(o.Hash("abc")).To(Equal("900150983cd24fb0d6963f7d28e17f72"))
+	})
+
+	It("should hash with the selected algorithm", func() {
+		sha1, err := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA1, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sha1.Hash("abc")).To(Equal("a9993e364706816aba3e25717850c26c9cd0d89d"))
+
+		sha256, err := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sha256.Hash("abc")).To(Equal("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"))
+
+		_, err = obfuscation.NewObfuscator("crc32", "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should hash deterministically with the same salt", func() {
+		o1, _ := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "org-salt")
+		o2, _ := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "org-salt")
+		other, _ := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "other-salt")
+		unsalted, _ := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA256, "")
+
+		Expect(o1.Hash("developer@example.com")).To(Equal(o2.Hash("developer@example.com")))
+		Expect(o1.Hash("developer@example.com")).ToNot(Equal(other.Hash("developer@example.com")))
+		Expect(o1.Hash("developer@example.com")).ToNot(Equal(unsalted.Hash("developer@example.com")))
+	})
+
+	It("should obfuscate the author emails", func() {
+		o, _ := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA1, "")
+		c := commit.OptimizedCommitForExport{AuthorEmails: []string{"abc"}}
+		o.ObfuscateAuthorEmails(&c)
+		Expect(c.AuthorEmails).To(Equal([]string{"a9993e364706816aba3e25717850c26c9cd0d89d"}))
+	})
+
+	It("should record the hashes of the texts", func() {
+		o, _ := obfuscation.NewObfuscator("", "salt")
+		o.Hash("before@example.com")
+		o.RecordHashes()
+		hashed := o.Hash("developer@example.com")
+
+		Expect(o.Algorithm()).To(Equal(obfuscation.AlgorithmMD5))
+		Expect(o.Hashes()).To(Equal(map[string]string{"developer@example.com": hashed}))
+	})
+
+	It("should only obfuscate the names of the libraries", func() {
+		o, _ := obfuscation.NewObfuscator(obfuscation.AlgorithmSHA1, "")
+		c := commit.OptimizedCommitForExport{
+			AuthorEmails: []string{"developer@example.com"},
+			Languages:    []string{"Go"},
+			Insertions:   10,
+			Libraries:    map[string][]string{"Go": {"abc"}},
+		}
+		o.ObfuscateLibraries(c.Libraries)
+		Expect(c.Libraries).To(Equal(map[string][]string{"Go": {"a9993e364706816aba3e25717850c26c9cd0d89d"}}))
+		Expect(c.AuthorEmails).To(Equal([]string{"developer@example.com"}))
+		Expect(c.Languages).To(Equal([]string{"Go"}))
+		Expect(c.Insertions).To(Equal(10))
+	})
+})
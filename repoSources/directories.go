@@ -0,0 +1,116 @@
+package repoSource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Techloopio/extractor_tool/entities"
+)
+
+type directories struct {
+	repos []*entities.Repository
+	// paths are the directory paths of the repositories by their full names
+	paths map[string]string
+}
+
+// NewDirectoryPaths creates a RepoSource of several local repositories.
+// The repositories are named after their directories, like the repository of NewDirectoryPath without a name.
+func NewDirectoryPaths(paths []string) RepoSource {
+	source := &directories{paths: map[string]string{}}
+	for _, path := range paths {
+		path = strings.TrimRight(path, string(os.PathSeparator))
+		source.add(filepath.Base(path), path)
+	}
+	return source
+}
+
+// NewReposDirectory creates a RepoSource of every repository found under the directory recursively.
+// The repositories are named after their paths relative to the directory, like "team/api",
+// so the repositories with the same directory name in different subdirectories get different exports.
+func NewReposDirectory(dir string) (RepoSource, error) {
+	paths, err := findRepositories(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no git repository found in %s", dir)
+	}
+
+	source := &directories{paths: map[string]string{}}
+	for _, path := range paths {
+		name, err := filepath.Rel(dir, path)
+		if err != nil || name == "." {
+			name = filepath.Base(path)
+		}
+		source.add(filepath.ToSlash(name), path)
+	}
+	return source, nil
+}
+
+// add adds the repository, a number is appended to the names which are already taken
+// The bare repositories are named without their ".git" suffix.
+func (d *directories) add(name, path string) {
+	name = strings.TrimSuffix(name, ".git")
+	fullName := name
+	for i := 2; d.paths[fullName] != ""; i++ {
+		fullName = fmt.Sprintf("%s_%d", name, i)
+	}
+	d.paths[fullName] = path
+	d.repos = append(d.repos, &entities.Repository{
+		FullName: fullName,
+		Name:     filepath.Base(path),
+	})
+}
+
+// findRepositories returns with the paths of the repositories in the directory and its subdirectories
+// A directory with a .git directory, or a .git file of a worktree or submodule, is a repository, and so is a bare
+// repository. The repositories are not searched for nested repositories.
+func findRepositories(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil || isBareRepository(path) {
+			paths = append(paths, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot search for repositories in %s: %s", dir, err.Error())
+	}
+	return paths, nil
+}
+
+// isBareRepository tells if the directory is a bare repository, which has the HEAD, the objects and the refs of git
+// without a working tree
+func isBareRepository(path string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRepos returns with the repositories in the order they were given or found
+func (d *directories) GetRepos() []*entities.Repository {
+	return d.repos
+}
+
+// Clone does nothing in this case because we already work with local copies
+func (d *directories) Clone(repository *entities.Repository) (string, error) {
+	return d.paths[repository.FullName], nil
+}
+
+// CleanUp does not have to clean up anything.
+func (d *directories) CleanUp() {}
@@ -0,0 +1,89 @@
+package repoSource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Techloopio/extractor_tool/entities"
+)
+
+var _ = Describe("Directories", func() {
+	Describe("NewDirectoryPaths", func() {
+		It("should name the repos by their directories", func() {
+			// Arrange
+			source := NewDirectoryPaths([]string{"/path/to/api/", "/path/to/web", "/other/api"})
+
+			// Act
+			repos := source.GetRepos()
+
+			// Assert
+			Expect(len(repos)).To(Equal(3))
+			Expect(repos[0].FullName).To(Equal("api"))
+			Expect(repos[1].FullName).To(Equal("web"))
+			Expect(repos[2].FullName).To(Equal("api_2"))
+			Expect(source.Clone(repos[2])).To(Equal("/other/api"))
+		})
+	})
+
+	Describe("NewReposDirectory", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "repos_dir_")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("should find the repos recursively", func() {
+			// Arrange
+			Expect(os.MkdirAll(filepath.Join(dir, "api", ".git"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "api", "vendor", "lib", ".git"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "team", "web", ".git"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "team", "worktree"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "team", "worktree", ".git"), []byte("gitdir: ../web/.git\n"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "docs"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "mirrors", "cli.git", "objects"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "mirrors", "cli.git", "refs"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "mirrors", "cli.git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)).To(Succeed())
+
+			// Act
+			source, err := NewReposDirectory(dir)
+
+			// Assert
+			Expect(err).ToNot(HaveOccurred())
+			repos := source.GetRepos()
+			Expect(len(repos)).To(Equal(4))
+			Expect(repos[0].FullName).To(Equal("api"))
+			Expect(repos[1].FullName).To(Equal("mirrors/cli"))
+			Expect(repos[2].FullName).To(Equal("team/web"))
+			Expect(repos[2].GetSafeFullName()).To(Equal("team_web"))
+			Expect(repos[3].FullName).To(Equal("team/worktree"))
+			Expect(source.Clone(repos[1])).To(Equal(filepath.Join(dir, "mirrors", "cli.git")))
+			Expect(source.Clone(repos[2])).To(Equal(filepath.Join(dir, "team", "web")))
+		})
+
+		It("should return an error if there is no repo", func() {
+			// Act
+			_, err := NewReposDirectory(dir)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("repoStateFile", func() {
+		It("should add the repo name to the state file", func() {
+			repo := &entities.Repository{FullName: "team/api"}
+			Expect(repoStateFile("/tmp/state.json", repo)).To(Equal("/tmp/state_team_api.json"))
+			Expect(repoStateFile("", repo)).To(Equal(""))
+		})
+	})
+})
@@ -1,20 +1,82 @@
 package repoSource
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/Techloopio/extractor_tool/entities"
 	"github.com/Techloopio/extractor_tool/extractor"
+	"github.com/Techloopio/extractor_tool/logger"
 )
 
 type ExtractConfig struct {
-	OutputPath    string
-	GitPath       string
-	HashImportant bool
-	UserEmails    []string
-	Seeds         []string
-	SkipLibraries bool
+	OutputPath            string
+	GitPath               string
+	HashImportant         bool
+	UserEmails            []string
+	Seeds                 []string
+	SkipLibraries         bool
+	OutputFormat          string
+	Since                 time.Time
+	Until                 time.Time
+	Aggregation           string
+	UseAuthorTimezone     bool
+	Workers               int
+	ExcludePaths          []string
+	RespectGitignore      bool
+	GeneratedPaths        []string
+	CountGenerated        bool
+	Raw                   bool
+	ToolVersion           string
+	LegacyFormat          bool
+	Pretty                bool
+	HashAlgorithm         string
+	HashSalt              string
+	ObfuscateEmails       bool
+	HashLibraries         bool
+	HashMapOut            string
+	IncludeMessages       bool
+	IncludeLibraryCounts  bool
+	IncludeActivity       bool
+	Refs                  []string
+	RevRange              string
+	IncludeMerges         bool
+	MaxCommits            int
+	TimeLimit             time.Duration
+	StateFile             string
+	ForceRefresh          bool
+	DryRun                bool
+	Quiet                 bool
+	Compress              bool
+	UploadURL             string
+	UploadToken           string
+	UploadTimeout         time.Duration
+	UploadRetries         int
+	Uploaders             []extractor.Uploader
+	EmailPattern          *regexp.Regexp
+	Identities            []string
+	NormalizeGitHubEmails bool
+	MinLanguageConfidence float64
+	MaxFileSize           int64
+	GoOS                  string
+	GoArch                string
+	GoBuildTags           []string
+	ExcludeGoStdlib       bool
+	ExcludeTSTypes        bool
+	PythonGranularity     string
+	ExcludePythonStdlib   bool
+	PythonVersion         int
+	ShellCommands         []string
+	PerlPragmas           []string
+	SkipLanguages         []string
+	OnlyLanguages         []string
+	Logger                *logger.Logger
 }
 
 // RepoSource describes the interface that each provider has to implement
@@ -29,8 +91,24 @@ type RepoSource interface {
 	CleanUp()
 }
 
+// ExtractFromSource extracts every repository of the source
+// The failed extractions and uploads don't stop the extraction of the other repositories,
+// but an error is returned at the end.
+// The repositories are extracted one after the other, so they share the workers instead of oversubscribing the CPUs.
 func ExtractFromSource(source RepoSource, config ExtractConfig) error {
 	repos := source.GetRepos()
+	failedUploads := 0
+	var uploadErr *extractor.UploadError
+	var extractErr error
+	var failedRepos []string
+
+	if len(repos) > 1 && config.OutputPath == extractor.StdoutOutputPath {
+		return fmt.Errorf("the exports of %d repositories cannot be written to the standard output, set a directory with --output_path", len(repos))
+	}
+
+	if config.Logger == nil {
+		config.Logger, _ = logger.NewLogger(logger.FormatText, os.Stderr, config.Quiet)
+	}
 
 	if config.OutputPath == "" {
 		outputDir, err := ioutil.TempDir("", "clone_dir_")
@@ -43,27 +121,124 @@ func ExtractFromSource(source RepoSource, config ExtractConfig) error {
 	for _, repo := range repos {
 		path, err := source.Clone(repo)
 		if err != nil {
-			fmt.Println("Couldn't clone repository. Error:", err.Error())
+			config.Logger.Error("Couldn't clone repository", logger.Fields{"error": err.Error()})
+		}
+
+		stateFile := config.StateFile
+		if len(repos) > 1 {
+			stateFile = repoStateFile(config.StateFile, repo)
+		}
+
+		outputPath := config.OutputPath + "/" + repo.GetSafeFullName()
+		if config.OutputPath == extractor.StdoutOutputPath {
+			outputPath = extractor.StdoutOutputPath
 		}
 
 		repoExtractor := extractor.RepoExtractor{
-			RepoPath:      path,
-			OutputPath:    config.OutputPath + "/" + repo.GetSafeFullName(),
-			GitPath:       config.GitPath,
-			HashImportant: config.HashImportant,
-			UserEmails:    config.UserEmails,
-			Seed:          config.Seeds,
-			SkipLibraries: config.SkipLibraries,
+			RepoPath:                 path,
+			OutputPath:               outputPath,
+			GitPath:                  config.GitPath,
+			HashImportant:            config.HashImportant,
+			UserEmails:               config.UserEmails,
+			Seed:                     config.Seeds,
+			SkipLibraries:            config.SkipLibraries,
+			OutputFormat:             config.OutputFormat,
+			Since:                    config.Since,
+			Until:                    config.Until,
+			Aggregation:              config.Aggregation,
+			UseAuthorTimezone:        config.UseAuthorTimezone,
+			Workers:                  config.Workers,
+			ExcludePaths:             config.ExcludePaths,
+			RespectGitignore:         config.RespectGitignore,
+			GeneratedPaths:           config.GeneratedPaths,
+			CountGeneratedFiles:      config.CountGenerated,
+			Raw:                      config.Raw,
+			ToolVersion:              config.ToolVersion,
+			LegacyFormat:             config.LegacyFormat,
+			Pretty:                   config.Pretty,
+			HashAlgorithm:            config.HashAlgorithm,
+			HashSalt:                 config.HashSalt,
+			ObfuscateEmails:          config.ObfuscateEmails,
+			HashLibraries:            config.HashLibraries,
+			HashMapOut:               config.HashMapOut,
+			IncludeMessages:          config.IncludeMessages,
+			IncludeLibraryCounts:     config.IncludeLibraryCounts,
+			IncludeActivity:          config.IncludeActivity,
+			Refs:                     config.Refs,
+			RevRange:                 config.RevRange,
+			IncludeMerges:            config.IncludeMerges,
+			MaxCommits:               config.MaxCommits,
+			TimeLimit:                config.TimeLimit,
+			StateFile:                stateFile,
+			ForceRefresh:             config.ForceRefresh,
+			DryRun:                   config.DryRun,
+			Quiet:                    config.Quiet,
+			Compress:                 config.Compress,
+			UploadURL:                config.UploadURL,
+			UploadToken:              config.UploadToken,
+			UploadTimeout:            config.UploadTimeout,
+			UploadRetries:            config.UploadRetries,
+			Uploaders:                config.Uploaders,
+			EmailPattern:             config.EmailPattern,
+			Identities:               config.Identities,
+			NormalizeGitHubEmails:    config.NormalizeGitHubEmails,
+			MinLanguageConfidence:    config.MinLanguageConfidence,
+			MaxFileSize:              config.MaxFileSize,
+			GoOS:                     config.GoOS,
+			GoArch:                   config.GoArch,
+			GoBuildTags:              config.GoBuildTags,
+			ExcludeGoStandardLibrary: config.ExcludeGoStdlib,
+			ExcludeTypeScriptTypes:   config.ExcludeTSTypes,
+			PythonGranularity:        config.PythonGranularity,
+			ExcludePythonStdlib:      config.ExcludePythonStdlib,
+			PythonVersion:            config.PythonVersion,
+			ShellCommands:            config.ShellCommands,
+			PerlPragmas:              config.PerlPragmas,
+			SkipLanguages:            config.SkipLanguages,
+			OnlyLanguages:            config.OnlyLanguages,
+			Logger:                   config.Logger,
 		}
 
 		err = repoExtractor.Extract()
 		if err != nil {
-			fmt.Println("Error during execution.", err.Error())
+			config.Logger.Error("Error during execution", logger.Fields{"error": err.Error()})
+			failedRepos = append(failedRepos, repo.FullName)
+			if errors.As(err, &uploadErr) {
+				failedUploads++
+			} else {
+				extractErr = err
+			}
 			continue
 		}
 
 	}
 	source.CleanUp()
 
+	if len(repos) > 1 {
+		fields := logger.Fields{"extracted": len(repos) - len(failedRepos), "failed": len(failedRepos)}
+		if len(failedRepos) > 0 {
+			fields["failedRepos"] = strings.Join(failedRepos, ",")
+		}
+		config.Logger.Info("Finished extracting the repositories", fields)
+	}
+
+	if failedUploads > 0 && failedUploads == len(failedRepos) {
+		// The last upload error is wrapped, so the caller can tell the failed uploads from the failed extractions
+		return fmt.Errorf("%d export(s) couldn't be uploaded, they are kept in %s: %w", failedUploads, config.OutputPath, uploadErr)
+	}
+	if len(failedRepos) > 0 {
+		// The last error is wrapped too, so the caller can tell the invalid options and the missing git
+		return fmt.Errorf("%d repositories couldn't be extracted: %s: %w", len(failedRepos), strings.Join(failedRepos, ", "), extractErr)
+	}
 	return nil
 }
+
+// repoStateFile returns with the state file of the repository if several repositories are extracted
+// The name of the repository is added to the name of the state file, like "state_team_api.json".
+func repoStateFile(stateFile string, repo *entities.Repository) string {
+	if stateFile == "" {
+		return ""
+	}
+	extension := filepath.Ext(stateFile)
+	return strings.TrimSuffix(stateFile, extension) + "_" + repo.GetSafeFullName() + extension
+}
@@ -0,0 +1,391 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The uploads are signed with AWS Signature Version 4 using only the standard library,
+// so the tool doesn't depend on the AWS SDK.
+
+// Credentials are the AWS credentials signing the requests
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// CredentialsProvider returns with the credentials used for the next upload
+type CredentialsProvider func() (Credentials, error)
+
+// Uploader uploads the export files under the prefix of an S3 bucket
+type Uploader struct {
+	Bucket string
+	Prefix string
+	Region string
+	// Endpoint of an S3 compatible storage like "http://localhost:9000". The requests use path-style URLs if it is set.
+	Endpoint    string
+	Credentials CredentialsProvider
+	Client      *http.Client
+	now         func() time.Time
+}
+
+// NewUploader creates an uploader of a destination like "s3://bucket/prefix"
+// The region is read from AWS_REGION or AWS_DEFAULT_REGION, the endpoint from AWS_ENDPOINT_URL_S3,
+// and the credentials from the standard chain of the environment, the shared credentials file and the instance role.
+func NewUploader(destination string) (*Uploader, error) {
+	if !strings.HasPrefix(destination, "s3://") {
+		return nil, fmt.Errorf("the S3 destination must be like s3://bucket/prefix, got: %s", destination)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(destination, "s3://"), "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf("the S3 destination has no bucket: %s", destination)
+	}
+	prefix := ""
+	if len(parts) == 2 {
+		prefix = strings.Trim(parts[1], "/")
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &Uploader{
+		Bucket:      parts[0],
+		Prefix:      prefix,
+		Region:      region,
+		Endpoint:    os.Getenv("AWS_ENDPOINT_URL_S3"),
+		Credentials: DefaultCredentials,
+		Client:      &http.Client{Timeout: 5 * time.Minute},
+		now:         time.Now,
+	}, nil
+}
+
+// Key returns with the key of the export file like "prefix/owner/repo/20210318T100000Z/repo_techloop.json.gz"
+// Every upload of the same repo gets a new key, the previous exports are kept.
+func (u *Uploader) Key(exportPath, repoName string, uploadedAt time.Time) string {
+	return path.Join(u.Prefix, repoName, uploadedAt.UTC().Format("20060102T150405Z"), filepath.Base(exportPath))
+}
+
+// Upload puts the export file to the bucket
+func (u *Uploader) Upload(exportPath, repoName string) error {
+	content, err := ioutil.ReadFile(exportPath)
+	if err != nil {
+		return err
+	}
+	credentials, err := u.Credentials()
+	if err != nil {
+		return fmt.Errorf("cannot get the AWS credentials: %s", err.Error())
+	}
+
+	now := time.Now
+	if u.now != nil {
+		now = u.now
+	}
+	uploadedAt := now()
+
+	req, err := http.NewRequest(http.MethodPut, u.objectURL(u.Key(exportPath, repoName, uploadedAt)), bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if strings.HasSuffix(exportPath, ".gz") {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	payloadHash := sha256.Sum256(content)
+	SignRequest(req, credentials, u.Region, hex.EncodeToString(payloadHash[:]), uploadedAt)
+
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status: %s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// objectURL returns with the virtual-hosted-style URL of the object, or the path-style URL if an endpoint is set
+func (u *Uploader) objectURL(key string) string {
+	if u.Endpoint != "" {
+		return strings.TrimRight(u.Endpoint, "/") + "/" + u.Bucket + "/" + encodePath(key)
+	}
+	return "https://" + u.Bucket + ".s3." + u.Region + ".amazonaws.com/" + encodePath(key)
+}
+
+// SignRequest adds the headers of AWS Signature Version 4 to the S3 request
+// Every header set before signing is signed.
+func SignRequest(req *http.Request, credentials Credentials, region, payloadHash string, signedAt time.Time) {
+	amzDate := signedAt.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		encodePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+credentials.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// encodePath encodes every character of the path except the unreserved characters and the slashes
+func encodePath(p string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(p) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' || b == '/' {
+			encoded.WriteByte(b)
+			continue
+		}
+		encoded.WriteString(fmt.Sprintf("%%%02X", b))
+	}
+	return encoded.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, strings.Replace(url.QueryEscape(key), "+", "%20", -1)+"="+strings.Replace(url.QueryEscape(value), "+", "%20", -1))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// DefaultCredentials reads the credentials from the standard chain of AWS:
+// the environment variables, the shared credentials file, the role of the ECS task and the role of the EC2 instance.
+func DefaultCredentials() (Credentials, error) {
+	if credentials, ok := environmentCredentials(); ok {
+		return credentials, nil
+	}
+	credentials, ok, err := sharedFileCredentials()
+	if err != nil {
+		return Credentials{}, err
+	}
+	if ok {
+		return credentials, nil
+	}
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		return containerCredentials("http://169.254.170.2" + uri)
+	}
+	return instanceCredentials()
+}
+
+func environmentCredentials() (Credentials, bool) {
+	credentials := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	return credentials, credentials.AccessKeyID != "" && credentials.SecretAccessKey != ""
+}
+
+// sharedFileCredentials reads the profile of AWS_PROFILE (or the default profile)
+// from AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials
+func sharedFileCredentials() (Credentials, bool, error) {
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, false, nil
+		}
+		file = filepath.Join(home, ".aws", "credentials")
+	}
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return Credentials{}, false, nil
+	}
+	if err != nil {
+		return Credentials{}, false, err
+	}
+
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	return parseSharedCredentials(string(content), profile)
+}
+
+// parseSharedCredentials reads the profile from the INI formatted credentials file
+func parseSharedCredentials(content, profile string) (Credentials, bool, error) {
+	var credentials Credentials
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		keyValue := strings.SplitN(line, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(keyValue[1])
+		switch strings.TrimSpace(keyValue[0]) {
+		case "aws_access_key_id":
+			credentials.AccessKeyID = value
+		case "aws_secret_access_key":
+			credentials.SecretAccessKey = value
+		case "aws_session_token":
+			credentials.SessionToken = value
+		}
+	}
+	return credentials, credentials.AccessKeyID != "" && credentials.SecretAccessKey != "", nil
+}
+
+// roleCredentials is the response of the ECS and EC2 credential endpoints
+type roleCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+}
+
+var metadataClient = &http.Client{Timeout: 2 * time.Second}
+
+func containerCredentials(uri string) (Credentials, error) {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return fetchRoleCredentials(req)
+}
+
+// instanceCredentials gets the credentials of the role of the EC2 instance with IMDSv2
+func instanceCredentials() (Credentials, error) {
+	const metadataURL = "http://169.254.169.254/latest"
+	tokenReq, err := http.NewRequest(http.MethodPut, metadataURL+"/api/token", nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	tokenReq.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+	token, err := readMetadata(tokenReq)
+	if err != nil {
+		return Credentials{}, errors.New("no credentials found in the environment, the shared credentials file or the instance metadata")
+	}
+
+	roleReq, err := http.NewRequest(http.MethodGet, metadataURL+"/meta-data/iam/security-credentials/", nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	roleReq.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+	role, err := readMetadata(roleReq)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("the instance has no role: %s", err.Error())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadataURL+"/meta-data/iam/security-credentials/"+strings.TrimSpace(role), nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+	return fetchRoleCredentials(req)
+}
+
+func fetchRoleCredentials(req *http.Request) (Credentials, error) {
+	body, err := readMetadata(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var role roleCredentials
+	err = json.Unmarshal([]byte(body), &role)
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{AccessKeyID: role.AccessKeyID, SecretAccessKey: role.SecretAccessKey, SessionToken: role.Token}, nil
+}
+
+func readMetadata(req *http.Request) (string, error) {
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,56 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is saved after an extraction, so the next extraction only has to process the new commits
+type State struct {
+	// ProcessedRefs are the commit hashes of the analysed refs, e.g. the tips of the branches.
+	// Every commit reachable from them was processed.
+	ProcessedRefs []string `json:"processedRefs"`
+	// UpdatedAt is the time of the extraction
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Load reads the state file
+// It returns with an empty state if the file doesn't exist yet.
+func Load(path string) (*State, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &State{}
+	err = json.Unmarshal(content, s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the state file
+func (s *State) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// IsEmpty tells if nothing was processed yet
+func (s *State) IsEmpty() bool {
+	return len(s.ProcessedRefs) == 0
+}
@@ -1,9 +1,31 @@
 package ui
 
-import "github.com/cheggaaa/pb/v3"
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+// progressBarTemplate shows the elapsed time, the number of items per second and the estimated remaining time
+// besides the counters. The prefix is the label of the stage of the ProgressTracker.
+const progressBarTemplate pb.ProgressBarTemplate = `{{string . "prefix"}}{{counters . }} {{bar . }} {{percent . }} {{progress_stats . }}`
+
+// startKey is the key of the value of the bar when it was started, the rate is counted from it
+const startKey = "start"
+
+func init() {
+	pb.RegisterElement("progress_stats", pb.ElementFunc(func(state *pb.State, args ...string) string {
+		start, _ := state.Get(startKey).(int64)
+		return formatProgressStats(state.Value()-start, state.Total()-start, state.Time().Sub(state.StartTime()), state.IsFinished())
+	}), false)
+}
 
 type progressBar struct {
 	progressBar *pb.ProgressBar
+	// offset is added to the values set by SetCurrent, it is the number of items of the previous stages
+	offset int64
 }
 
 type nilProgressBar struct{}
@@ -14,12 +36,27 @@ type ProgressBar interface {
 	SetCurrent(value int)
 }
 
-// A simple progress bar CLI implementation
+// A simple progress bar CLI implementation, rendered to the standard error
 func NewProgressBar(count int) ProgressBar {
-	p := pb.StartNew(count)
+	p := progressBarTemplate.New(count).SetWriter(os.Stderr).Start()
+
+	return progressBar{
+		progressBar: p,
+	}
+}
+
+// newStageProgressBar returns with a progress bar which starts at the offset, after the items of the previous stages
+func newStageProgressBar(label string, total, offset int) ProgressBar {
+	p := progressBarTemplate.New(total).
+		Set("prefix", label+" ").
+		Set(startKey, int64(offset)).
+		SetCurrent(int64(offset)).
+		SetWriter(os.Stderr).
+		Start()
 
 	return progressBar{
 		progressBar: p,
+		offset:      int64(offset),
 	}
 }
 
@@ -32,7 +69,7 @@ func (p progressBar) Finish() {
 }
 
 func (p progressBar) SetCurrent(value int) {
-	p.progressBar.SetCurrent(int64(value))
+	p.progressBar.SetCurrent(p.offset + int64(value))
 }
 
 func NilProgressBar() ProgressBar {
@@ -44,3 +81,20 @@ func (n nilProgressBar) Inc() {}
 func (n nilProgressBar) Finish() {}
 
 func (n nilProgressBar) SetCurrent(value int) {}
+
+// formatProgressStats returns with the elapsed time, the rate and the estimated remaining time like "12s, 4.2/s, ETA 30s"
+// The rate is the average since the start, the remaining time is unknown until the first item is done.
+func formatProgressStats(current, total int64, elapsed time.Duration, finished bool) string {
+	stats := elapsed.Round(time.Second).String()
+	if current <= 0 || elapsed <= 0 {
+		return stats + ", ETA ?"
+	}
+
+	rate := float64(current) / elapsed.Seconds()
+	stats += fmt.Sprintf(", %.1f/s", rate)
+	if finished || current >= total {
+		return stats
+	}
+	remaining := time.Duration(float64(total-current) / rate * float64(time.Second))
+	return stats + ", ETA " + remaining.Round(time.Second).String()
+}
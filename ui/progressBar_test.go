@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProgressBar", func() {
+	Describe("formatProgressStats", func() {
+		It("should show the elapsed time, the rate and the remaining time", func() {
+			Expect(formatProgressStats(50, 200, 10*time.Second, false)).To(Equal("10s, 5.0/s, ETA 30s"))
+		})
+
+		It("should not estimate the remaining time before the first item", func() {
+			Expect(formatProgressStats(0, 200, 2*time.Second, false)).To(Equal("2s, ETA ?"))
+		})
+
+		It("should not show the remaining time when finished", func() {
+			Expect(formatProgressStats(200, 200, 100*time.Second, true)).To(Equal("1m40s, 2.0/s"))
+		})
+	})
+})
+
+var _ = Describe("ProgressTracker", func() {
+	It("should not show the bars of unexpected stages", func() {
+		tracker := NewProgressTracker(0, 10)
+		Expect(tracker.Stage("Analysing libraries")).To(Equal(NilProgressBar()))
+	})
+
+	It("should not show anything if it is nil", func() {
+		Expect(NilProgressTracker().Stage("Fetching commits")).To(Equal(NilProgressBar()))
+	})
+})
@@ -0,0 +1,45 @@
+package ui
+
+import "fmt"
+
+// ProgressTracker shows the overall progress of a task of several stages, like fetching the commits and then
+// analysing their libraries. Every stage has its own bar with its label, which continues where the previous stage
+// left off, so the percentage is the progress of the whole task.
+type ProgressTracker interface {
+	// Stage starts the next stage, its progress bar must be finished before the next stage is started
+	Stage(label string) ProgressBar
+}
+
+type progressTracker struct {
+	stages        int
+	itemsPerStage int
+	stage         int
+}
+
+type nilProgressTracker struct{}
+
+// NewProgressTracker returns with a tracker of the stages, each of them processing the same number of items
+func NewProgressTracker(stages, itemsPerStage int) ProgressTracker {
+	return &progressTracker{
+		stages:        stages,
+		itemsPerStage: itemsPerStage,
+	}
+}
+
+func (t *progressTracker) Stage(label string) ProgressBar {
+	// The bar of an unexpected stage is not shown, as the percentage would be over 100%
+	if t.stage >= t.stages {
+		return NilProgressBar()
+	}
+	t.stage++
+	return newStageProgressBar(fmt.Sprintf("[%d/%d] %s", t.stage, t.stages, label), t.stages*t.itemsPerStage, (t.stage-1)*t.itemsPerStage)
+}
+
+// NilProgressTracker returns with a tracker which doesn't show anything
+func NilProgressTracker() ProgressTracker {
+	return nilProgressTracker{}
+}
+
+func (n nilProgressTracker) Stage(label string) ProgressBar {
+	return NilProgressBar()
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -12,28 +13,61 @@ import (
 // SelectEmail shows a CLI select interface.
 // The user has a chance to select the given emails from
 // a predefined list (allEmails).
+// The emails are sorted by their number of commits (commits), the most active first,
+// and the number is shown next to the emails.
 // At least one option must be selected
 // The returning value is the selected emails.
-func SelectEmail(allEmails []string) []string {
+func SelectEmail(allEmails []string, commits map[string]int) []string {
+	options, emailsOfOptions := getEmailOptions(allEmails, commits)
 askForEmails:
-	// TODO sort by alphabetical order (or frequency?)
-	selectedEmailsWithNames := []string{}
+	selectedOptions := []string{}
 	prompt := &survey.MultiSelect{
 		Message: "Please choose your emails:",
-		Options: allEmails,
+		Options: options,
 		Filter: func(filterValue string, optValue string, optIndex int) bool {
 			return strings.Contains(optValue, filterValue)
 		},
 	}
-	err := survey.AskOne(prompt, &selectedEmailsWithNames, survey.WithKeepFilter(true))
+	err := survey.AskOne(prompt, &selectedOptions, survey.WithKeepFilter(true))
 	if err == terminal.InterruptErr {
 		os.Exit(0)
 	}
 
-	if len(selectedEmailsWithNames) == 0 {
+	if len(selectedOptions) == 0 {
 		fmt.Println("Please choose at least one email!")
 		goto askForEmails
 	}
 
+	selectedEmailsWithNames := make([]string, len(selectedOptions))
+	for i, option := range selectedOptions {
+		selectedEmailsWithNames[i] = emailsOfOptions[option]
+	}
 	return selectedEmailsWithNames
 }
+
+// getEmailOptions returns with the options of the emails in "Name -> email (N commits)" format, sorted by the number
+// of commits, and the emails in "Name -> email" format by their options
+func getEmailOptions(allEmails []string, commits map[string]int) ([]string, map[string]string) {
+	sortedEmails := make([]string, len(allEmails))
+	copy(sortedEmails, allEmails)
+	sort.SliceStable(sortedEmails, func(i, j int) bool {
+		return commits[getEmail(sortedEmails[i])] > commits[getEmail(sortedEmails[j])]
+	})
+
+	options := make([]string, len(sortedEmails))
+	emailsOfOptions := make(map[string]string, len(sortedEmails))
+	for i, email := range sortedEmails {
+		options[i] = email
+		if count, ok := commits[getEmail(email)]; ok {
+			options[i] = fmt.Sprintf("%s (%d commits)", email, count)
+		}
+		emailsOfOptions[options[i]] = email
+	}
+	return options, emailsOfOptions
+}
+
+// getEmail returns with the email of the "Name -> email" format
+func getEmail(emailWithName string) string {
+	fields := strings.Split(emailWithName, " -> ")
+	return fields[len(fields)-1]
+}
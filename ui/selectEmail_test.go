@@ -0,0 +1,26 @@
+package ui
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SelectEmail", func() {
+	It("should sort the emails by the number of commits and show the number", func() {
+		allEmails := []string{"Developer -> developer@example.com", "Colleague -> colleague@ourcompany.com", "Bot -> bot@example.com"}
+		commits := map[string]int{"developer@example.com": 2, "colleague@ourcompany.com": 12, "bot@example.com": 2}
+
+		options, emailsOfOptions := getEmailOptions(allEmails, commits)
+		Expect(options).To(Equal([]string{
+			"Colleague -> colleague@ourcompany.com (12 commits)",
+			"Developer -> developer@example.com (2 commits)",
+			"Bot -> bot@example.com (2 commits)",
+		}))
+		Expect(emailsOfOptions["Bot -> bot@example.com (2 commits)"]).To(Equal("Bot -> bot@example.com"))
+	})
+
+	It("should keep the emails without a number of commits", func() {
+		options, _ := getEmailOptions([]string{"Developer -> developer@example.com"}, nil)
+		Expect(options).To(Equal([]string{"Developer -> developer@example.com"}))
+	})
+})